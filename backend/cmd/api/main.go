@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -45,6 +46,15 @@ func main() {
 	logger.Info("Starting service",
 		slog.String("server_address", cfg.Server.Address()),
 	)
+	if cfg.Auth.AccessTokenSecret == "" {
+		logger.Warn("ACCESS_TOKEN_SECRET is not set, poll access tokens won't survive restart")
+	}
+	if cfg.Receipt.SigningKey == "" {
+		logger.Warn("RECEIPT_SIGNING_KEY is not set, vote receipts won't verify after restart")
+	}
+	if !cfg.Chat.Verified() {
+		logger.Warn("CHAT_SIGNING_SECRET and CHAT_VERIFICATION_TOKEN are not set, chat requests are not authenticated")
+	}
 
 	// Connect Redis
 	redisClient := redis.NewClient(&redis.Options{
@@ -68,12 +78,59 @@ func main() {
 	logger.Info("Connected to Redis", slog.String("redis_address", cfg.Redis.Address()))
 
 	// Initialize storage, logic and handler layers
-	stor := storage.NewRedisStorage(redisClient)
+	stor := storage.NewRedisStorage(redisClient, logger)
 	pollService := service.NewPollService(stor, cfg, logger)
 	pollHandler := handler.NewPollHandler(pollService, logger)
 
+	// Deliver webhooks, emails and chat updates in background, so voting doesn't wait for them
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	webhookWorker := service.NewWebhookWorker(stor, cfg.Webhook, logger)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		if err := webhookWorker.Run(workerCtx); err != nil {
+			logger.Error("webhook worker stopped", slog.String("error", err.Error()))
+		}
+	}()
+
+	if cfg.SMTP.Enabled() {
+		mailer, err := service.NewSMTPMailer(cfg.SMTP)
+		if err != nil {
+			logger.Error("failed to configure SMTP", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		emailWorker := service.NewEmailWorker(stor, mailer, cfg.SMTP, cfg.Server.BaseURL, logger)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			if err := emailWorker.Run(workerCtx); err != nil {
+				logger.Error("email worker stopped", slog.String("error", err.Error()))
+			}
+		}()
+		logger.Info("Email notifications enabled", slog.String("smtp_address", cfg.SMTP.Address()))
+	}
+
+	// Chat messages are updated only through response URLs of verified requests
+	if cfg.Chat.Verified() {
+		chatWorker := service.NewChatWorker(pollService, stor, cfg.Chat, logger)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			if err := chatWorker.Run(workerCtx); err != nil {
+				logger.Error("chat worker stopped", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
 	// Setup router and http server
-	router := handler.SetupRouter(pollHandler, logger, cfg.Env == "prod")
+	idempotency := handler.IdempotencyMiddleware(stor, cfg.Idempotency.Window, logger)
+	chatAuth := handler.ChatAuthMiddleware(cfg.Chat, logger)
+	router, err := handler.SetupRouter(pollHandler, idempotency, chatAuth, cfg.Server.TrustedProxies, logger, cfg.Env == "prod")
+	if err != nil {
+		logger.Error("failed to setup router", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 	server := &http.Server{
 		Addr:         cfg.Server.Address(),
 		Handler:      router,
@@ -103,6 +160,19 @@ func main() {
 		logger.Error("server forced to shutdown", slog.String("error", err.Error()))
 	}
 
+	// Let webhook requests and emails in flight finish
+	stopWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		logger.Error("background workers forced to stop")
+	}
+
 	// Close Redis connection
 	if err := stor.Close(); err != nil {
 		logger.Error("failed to close redis connection", slog.String("error", err.Error()))
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlexeyLars/surway-service/pkg/client"
+)
+
+// stringList is flag collecting repeated values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// intList is flag collecting repeated or comma separated integers
+type intList []int
+
+func (l *intList) String() string {
+	values := make([]string, len(*l))
+	for i, v := range *l {
+		values[i] = strconv.Itoa(v)
+	}
+	return strings.Join(values, ",")
+}
+
+func (l *intList) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return fmt.Errorf("invalid number %q", part)
+		}
+		*l = append(*l, v)
+	}
+	return nil
+}
+
+// newFlagSet returns flags of command printing usage to stderr
+func newFlagSet(a *app, name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: surway %s [flags] %s\n\nFlags:\n", name, args)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses flags placed before and after positional arguments
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parsePollID parses flags of command taking single poll ID
+func parsePollID(flags *flag.FlagSet, args []string) (string, error) {
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return "", err
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return "", errUsage
+	}
+	return positional[0], nil
+}
+
+// withTimeout bounds ctx by request timeout from config
+func (a *app) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.cfg.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, a.cfg.Timeout)
+}
+
+// adminToken returns token given by flag or stored on poll creation
+func (a *app) adminToken(pollID, flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	token, err := a.tokens.Get(pollID)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", fmt.Errorf("no admin token stored for poll %s, pass -admin-token", pollID)
+	}
+	return token, nil
+}
+
+// viewerOptions returns credentials of results request, stored admin token is used when present
+func (a *app) viewerOptions(pollID, adminToken, voterToken, password string) ([]client.RequestOption, error) {
+	var opts []client.RequestOption
+	if adminToken == "" {
+		token, err := a.tokens.Get(pollID)
+		if err != nil {
+			return nil, err
+		}
+		adminToken = token
+	}
+	if adminToken != "" {
+		opts = append(opts, client.WithAdminToken(adminToken))
+	}
+	if voterToken != "" {
+		opts = append(opts, client.WithVoterToken(voterToken))
+	}
+	if password != "" {
+		opts = append(opts, client.WithPassword(password))
+	}
+	return opts, nil
+}
+
+// readJSON decodes JSON file into v, "-" reads stdin
+func (a *app) readJSON(path string, v any) error {
+	var r io.Reader = a.stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// printJSON writes v as indented JSON
+func (a *app) printJSON(v any) error {
+	encoder := json.NewEncoder(a.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func runCreate(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "create", "")
+	title := flags.String("title", "", "poll title")
+	var options stringList
+	flags.Var(&options, "option", "answer option, repeat for every option")
+	file := flags.String("file", "", "JSON file with create poll request, - reads stdin; flags override its fields")
+	pollType := flags.String("type", "", "poll type, choice by default")
+	password := flags.String("password", "", "password required to vote and see results")
+	asJSON := flags.Bool("json", false, "print response as JSON")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	var req client.CreatePollRequest
+	if *file != "" {
+		if err := a.readJSON(*file, &req); err != nil {
+			return err
+		}
+	}
+	if *title != "" {
+		req.Title = *title
+	}
+	if len(options) > 0 {
+		req.Options = options
+	}
+	if *pollType != "" {
+		req.Type = client.PollType(*pollType)
+	}
+	if *password != "" {
+		req.Password = *password
+	}
+	if req.Title == "" {
+		fmt.Fprintln(a.stderr, "surway create: title is required, pass -title or -file")
+		return errUsage
+	}
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	created, err := a.client.CreatePoll(ctx, &req)
+	if err != nil {
+		return err
+	}
+	if err := a.tokens.Save(created.PollID, created.AdminToken); err != nil {
+		// Token is shown once, so it must not be lost
+		fmt.Fprintf(a.stderr, "Admin token of poll %s: %s\n", created.PollID, created.AdminToken)
+		return err
+	}
+
+	if *asJSON {
+		return a.printJSON(created)
+	}
+	fmt.Fprintf(a.stdout, "Poll created: %s\nVote:    %s\nResults: %s\nAdmin token saved to %s\n",
+		created.PollID, created.VoteURL, created.ResultsURL, a.tokens.path)
+	return nil
+}
+
+func runVote(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "vote", "<poll-id>")
+	var options, ranking intList
+	flags.Var(&options, "option", "index of chosen option, repeat or separate by commas for multiple choice")
+	flags.Var(&ranking, "rank", "option indices of ranked poll, most preferred first")
+	text := flags.String("text", "", "answer of text or word cloud poll, write-in of choice poll")
+	var number *float64
+	flags.Func("number", "answer of numeric poll", func(value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		number = &v
+		return err
+	})
+	var rating *int
+	flags.Func("rating", "answer of NPS poll, 0-10", func(value string) error {
+		v, err := strconv.Atoi(value)
+		rating = &v
+		return err
+	})
+	ballot := flags.String("ballot", "", "JSON file with vote request for other poll types, - reads stdin")
+	voterToken := flags.String("token", "", "voter token or invite of invite-only poll")
+	password := flags.String("password", "", "password of protected poll")
+	asJSON := flags.Bool("json", false, "print response as JSON")
+
+	pollID, err := parsePollID(flags, args)
+	if err != nil {
+		return err
+	}
+
+	var req client.VoteRequest
+	if *ballot != "" {
+		if err := a.readJSON(*ballot, &req); err != nil {
+			return err
+		}
+	}
+	if len(options) > 0 {
+		req.OptionIndices = options
+	}
+	if len(ranking) > 0 {
+		req.Ranking = ranking
+	}
+	if *text != "" {
+		req.Text = *text
+	}
+	if number != nil {
+		req.Number = number
+	}
+	if rating != nil {
+		req.Rating = rating
+	}
+
+	var opts []client.RequestOption
+	if *voterToken != "" {
+		opts = append(opts, client.WithVoterToken(*voterToken))
+	}
+	if *password != "" {
+		opts = append(opts, client.WithPassword(*password))
+	}
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	vote, err := a.client.Vote(ctx, pollID, &req, opts...)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return a.printJSON(vote)
+	}
+	fmt.Fprintln(a.stdout, "Vote counted")
+	if vote.Receipt != nil {
+		fmt.Fprintf(a.stdout, "Receipt: %s\n", vote.Receipt.ID)
+	}
+	if vote.VoterToken != "" {
+		fmt.Fprintf(a.stdout, "Voter token: %s\n", vote.VoterToken)
+	}
+	return nil
+}
+
+// resultsFlags are flags shared by results and watch
+type resultsFlags struct {
+	format     *string
+	adminToken *string
+	voterToken *string
+	password   *string
+}
+
+func newResultsFlags(flags *flag.FlagSet) resultsFlags {
+	return resultsFlags{
+		format:     flags.String("format", formatTable, "output format: table, json or text"),
+		adminToken: flags.String("admin-token", "", "admin token, stored token by default"),
+		voterToken: flags.String("token", "", "voter token returned on voting"),
+		password:   flags.String("password", "", "password of protected poll"),
+	}
+}
+
+// parse parses flags and returns poll ID with results request options
+func (f resultsFlags) parse(a *app, flags *flag.FlagSet, args []string) (string, []client.RequestOption, error) {
+	pollID, err := parsePollID(flags, args)
+	if err != nil {
+		return "", nil, err
+	}
+	if !validFormat(*f.format) {
+		fmt.Fprintf(a.stderr, "surway %s: unknown format %q\n", flags.Name(), *f.format)
+		return "", nil, errUsage
+	}
+	opts, err := a.viewerOptions(pollID, *f.adminToken, *f.voterToken, *f.password)
+	if err != nil {
+		return "", nil, err
+	}
+	return pollID, opts, nil
+}
+
+func runResults(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "results", "<poll-id>")
+	resultsFlags := newResultsFlags(flags)
+	pollID, opts, err := resultsFlags.parse(a, flags, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	results, err := a.client.GetResults(ctx, pollID, opts...)
+	if err != nil {
+		return err
+	}
+	return writeResults(a.stdout, results, *resultsFlags.format, false)
+}
+
+func runWatch(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "watch", "<poll-id>")
+	resultsFlags := newResultsFlags(flags)
+	pollID, opts, err := resultsFlags.parse(a, flags, args)
+	if err != nil {
+		return err
+	}
+
+	// Stream has no timeout, it runs until interrupted or poll is gone
+	first := true
+	err = a.client.WatchResults(ctx, pollID, func(results *client.PollResults) error {
+		// JSON updates are written one per line
+		if !first && *resultsFlags.format != formatJSON {
+			fmt.Fprintln(a.stdout)
+		}
+		first = false
+		return writeResults(a.stdout, results, *resultsFlags.format, true)
+	}, opts...)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func runClose(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "close", "<poll-id>")
+	adminToken := flags.String("admin-token", "", "admin token, stored token by default")
+	asJSON := flags.Bool("json", false, "print poll as JSON")
+
+	pollID, err := parsePollID(flags, args)
+	if err != nil {
+		return err
+	}
+	token, err := a.adminToken(pollID, *adminToken)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	details, err := a.client.ClosePoll(ctx, pollID, client.WithAdminToken(token))
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return a.printJSON(details)
+	}
+	fmt.Fprintf(a.stdout, "Voting closed in poll %s\n", pollID)
+	return nil
+}
+
+func runDelete(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet(a, "delete", "<poll-id>")
+	adminToken := flags.String("admin-token", "", "admin token, stored token by default")
+
+	pollID, err := parsePollID(flags, args)
+	if err != nil {
+		return err
+	}
+	token, err := a.adminToken(pollID, *adminToken)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	err = a.client.DeletePoll(ctx, pollID, client.WithAdminToken(token))
+	if err != nil && !errors.Is(err, client.ErrPollNotFound) {
+		return err
+	}
+	// Token of expired poll is useless as well
+	if forgetErr := a.tokens.Delete(pollID); forgetErr != nil {
+		return forgetErr
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "Poll %s deleted\n", pollID)
+	return nil
+}
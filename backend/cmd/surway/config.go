@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// config of CLI, environment overrides config file
+type config struct {
+	URL        string        `yaml:"url" env:"SURWAY_URL" env-default:"http://localhost:8080"`
+	Token      string        `yaml:"token" env:"SURWAY_TOKEN"`             // bearer token of gateway in front of API
+	TokensFile string        `yaml:"tokens_file" env:"SURWAY_TOKENS_FILE"` // admin tokens of created polls
+	Timeout    time.Duration `yaml:"timeout" env:"SURWAY_TIMEOUT" env-default:"30s"`
+}
+
+// configDir returns directory of CLI config and admin tokens
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "surway"), nil
+}
+
+// loadConfig reads config file at path, SURWAY_CONFIG or default location, then environment.
+// Missing file is an error only when path is set explicitly.
+func loadConfig(path string) (*config, error) {
+	if path == "" {
+		path = os.Getenv("SURWAY_CONFIG")
+	}
+	explicit := path != ""
+
+	dir, err := configDir()
+	if err != nil && !explicit {
+		return nil, err
+	}
+	if !explicit {
+		path = filepath.Join(dir, "config.yml")
+	}
+
+	var cfg config
+	switch _, statErr := os.Stat(path); {
+	case statErr == nil:
+		if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to load config %s: %w", path, err)
+		}
+	case errors.Is(statErr, fs.ErrNotExist) && !explicit:
+		if err := cleanenv.ReadEnv(&cfg); err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to load config %s: %w", path, statErr)
+	}
+
+	if cfg.TokensFile == "" {
+		if dir == "" {
+			return nil, errors.New("failed to load config: tokens_file is not set")
+		}
+		cfg.TokensFile = filepath.Join(dir, "tokens.json")
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlexeyLars/surway-service/pkg/client"
+)
+
+// Output formats of results
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatText  = "text"
+)
+
+// resultsRow is vote count of one option
+type resultsRow struct {
+	Option string
+	Votes  int
+}
+
+// resultsRows returns counts in poll option order, counts of other keys such as write-ins follow sorted
+func resultsRows(results *client.PollResults) []resultsRow {
+	rows := make([]resultsRow, 0, len(results.Votes))
+	seen := make(map[string]bool, len(results.Poll.Options))
+	for _, option := range results.Poll.Options {
+		seen[option] = true
+		rows = append(rows, resultsRow{Option: option, Votes: results.Votes[option]})
+	}
+
+	var extra []string
+	for option := range results.Votes {
+		if !seen[option] {
+			extra = append(extra, option)
+		}
+	}
+	slices.Sort(extra)
+	for _, option := range extra {
+		rows = append(rows, resultsRow{Option: option, Votes: results.Votes[option]})
+	}
+	return rows
+}
+
+// share returns percentage of votes out of total
+func share(votes, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(votes) * 100 / float64(total)
+}
+
+// validFormat reports whether results can be written in format
+func validFormat(format string) bool {
+	return format == formatTable || format == formatJSON || format == formatText
+}
+
+// writeResults writes results in format, JSON is indented unless compact
+func writeResults(w io.Writer, results *client.PollResults, format string, compact bool) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		if !compact {
+			encoder.SetIndent("", "  ")
+		}
+		return encoder.Encode(results)
+	case formatText:
+		return writeText(w, results)
+	default:
+		return writeTable(w, results)
+	}
+}
+
+func writeTable(w io.Writer, results *client.PollResults) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "OPTION\tVOTES\tSHARE\n")
+	for _, row := range resultsRows(results) {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\n", row.Option, row.Votes, share(row.Votes, results.Total))
+	}
+	fmt.Fprintf(tw, "Total\t%d\n", results.Total)
+	return tw.Flush()
+}
+
+func writeText(w io.Writer, results *client.PollResults) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", results.Poll.Title)
+	for _, row := range resultsRows(results) {
+		fmt.Fprintf(&b, "%s: %d (%.1f%%)\n", row.Option, row.Votes, share(row.Votes, results.Total))
+	}
+	fmt.Fprintf(&b, "Total: %d\n", results.Total)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,127 @@
+// Command surway creates polls, votes and follows results from terminal.
+//
+// Usage:
+//
+//	surway [-config file] <command> [flags] [poll-id]
+//
+// Server URL and optional bearer token are read from config file (~/.config/surway/config.yml
+// by default) and SURWAY_* environment variables. Admin tokens of created polls are kept in
+// tokens file, so close and delete need only poll ID.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/AlexeyLars/surway-service/pkg/client"
+)
+
+const usage = `Usage: surway [-config file] <command> [flags] [poll-id]
+
+Commands:
+  create   create poll from flags or JSON file
+  vote     vote in poll
+  results  show poll results
+  watch    follow live poll results
+  close    stop voting in poll
+  delete   delete poll with its votes
+
+Run "surway <command> -h" for command flags.
+
+Environment:
+  SURWAY_CONFIG       config file, ~/.config/surway/config.yml by default
+  SURWAY_URL          server URL, http://localhost:8080 by default
+  SURWAY_TOKEN        bearer token sent with every request
+  SURWAY_TOKENS_FILE  admin tokens of created polls
+  SURWAY_TIMEOUT      request timeout, 30s by default
+`
+
+// app holds dependencies of commands
+type app struct {
+	cfg    *config
+	client *client.Client
+	tokens tokenStore
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command runs subcommand with its arguments
+type command func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]command{
+	"create":  runCreate,
+	"vote":    runVote,
+	"results": runResults,
+	"watch":   runWatch,
+	"close":   runClose,
+	"delete":  runDelete,
+}
+
+// errUsage reports invalid command line, its usage is already printed
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "surway: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("surway", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	configPath := flags.String("config", "", "config file")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errUsage
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "surway: unknown command %q\n\n", flags.Arg(0))
+		flags.Usage()
+		return errUsage
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	var opts []client.Option
+	if cfg.Token != "" {
+		opts = append(opts, client.WithAuth(client.BearerToken(cfg.Token)))
+	}
+
+	a := &app{
+		cfg:    cfg,
+		client: client.New(cfg.URL, opts...),
+		tokens: tokenStore{path: cfg.TokensFile},
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+	return cmd(ctx, a, flags.Args()[1:])
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AlexeyLars/surway-service/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenStore(t *testing.T) {
+	store := tokenStore{path: filepath.Join(t.TempDir(), "surway", "tokens.json")}
+
+	token, err := store.Get("poll1")
+	require.NoError(t, err)
+	assert.Empty(t, token, "missing file holds no tokens")
+
+	require.NoError(t, store.Save("poll1", "secret1"))
+	require.NoError(t, store.Save("poll2", "secret2"))
+
+	info, err := os.Stat(store.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	token, err = store.Get("poll1")
+	require.NoError(t, err)
+	assert.Equal(t, "secret1", token)
+
+	require.NoError(t, store.Delete("poll1"))
+	token, err = store.Get("poll1")
+	require.NoError(t, err)
+	assert.Empty(t, token)
+
+	token, err = store.Get("poll2")
+	require.NoError(t, err)
+	assert.Equal(t, "secret2", token)
+}
+
+func TestWriteResults(t *testing.T) {
+	results := &client.PollResults{
+		Poll:  client.Poll{Title: "Lunch?", Options: []string{"Pizza", "Sushi"}},
+		Votes: map[string]int{"Pizza": 1, "Sushi": 3, "Other": 1},
+		Total: 5,
+	}
+
+	var text bytes.Buffer
+	require.NoError(t, writeResults(&text, results, formatText, false))
+	assert.Equal(t, "Lunch?\nPizza: 1 (20.0%)\nSushi: 3 (60.0%)\nOther: 1 (20.0%)\nTotal: 5\n", text.String())
+
+	var table bytes.Buffer
+	require.NoError(t, writeResults(&table, results, formatTable, false))
+	assert.Equal(t, "OPTION  VOTES  SHARE\nPizza   1      20.0%\nSushi   3      60.0%\nOther   1      20.0%\nTotal   5\n", table.String())
+
+	var compact bytes.Buffer
+	require.NoError(t, writeResults(&compact, results, formatJSON, true))
+	var decoded client.PollResults
+	require.NoError(t, json.Unmarshal(compact.Bytes(), &decoded))
+	assert.Equal(t, 5, decoded.Total)
+	assert.Equal(t, 1, bytes.Count(compact.Bytes(), []byte("\n")), "watch writes one update per line")
+}
+
+func TestRun_CreateAndDelete(t *testing.T) {
+	var deletedWith string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/polls", func(w http.ResponseWriter, r *http.Request) {
+		var req client.CreatePollRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "Lunch?", req.Title)
+		assert.Equal(t, []string{"Pizza", "Sushi"}, req.Options)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(client.CreatePollResponse{PollID: "poll1", AdminToken: "secret"})
+	})
+	mux.HandleFunc("DELETE /api/v1/polls/{id}", func(w http.ResponseWriter, r *http.Request) {
+		deletedWith = r.Header.Get("X-Admin-Token")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tokensFile := filepath.Join(t.TempDir(), "tokens.json")
+	t.Setenv("SURWAY_CONFIG", filepath.Join(t.TempDir(), "config.yml"))
+	require.NoError(t, os.WriteFile(os.Getenv("SURWAY_CONFIG"), []byte("url: "+server.URL+"\ntokens_file: "+tokensFile+"\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), []string{"create", "-title", "Lunch?", "-option", "Pizza", "-option", "Sushi"}, nil, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "Poll created: poll1")
+
+	err = run(context.Background(), []string{"delete", "poll1"}, nil, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", deletedWith, "stored admin token is used")
+
+	err = run(context.Background(), []string{"close", "poll1"}, nil, &stdout, &stderr)
+	assert.ErrorContains(t, err, "no admin token stored", "token is forgotten after delete")
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.ErrorIs(t, run(context.Background(), nil, nil, &stdout, &stderr), errUsage)
+	assert.ErrorIs(t, run(context.Background(), []string{"unknown"}, nil, &stdout, &stderr), errUsage)
+	assert.Contains(t, stderr.String(), "Commands:")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// tokenStore keeps admin tokens of created polls in JSON file readable by owner only
+type tokenStore struct {
+	path string
+}
+
+// load returns poll ID -> admin token, missing file holds no tokens
+func (s tokenStore) load() (map[string]string, error) {
+	tokens := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read admin tokens: %w", err)
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to read admin tokens %s: %w", s.path, err)
+	}
+	return tokens, nil
+}
+
+// write replaces file atomically, so interrupted write keeps previous tokens
+func (s tokenStore) write(tokens map[string]string) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal admin tokens: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to save admin tokens: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".tokens-*")
+	if err != nil {
+		return fmt.Errorf("failed to save admin tokens: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save admin tokens: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save admin tokens: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save admin tokens: %w", err)
+	}
+	return nil
+}
+
+// Save stores admin token of poll
+func (s tokenStore) Save(pollID, token string) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens[pollID] = token
+	return s.write(tokens)
+}
+
+// Get returns admin token of poll, empty if none is stored
+func (s tokenStore) Get(pollID string) (string, error) {
+	tokens, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return tokens[pollID], nil
+}
+
+// Delete forgets admin token of poll
+func (s tokenStore) Delete(pollID string) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[pollID]; !ok {
+		return nil
+	}
+	delete(tokens, pollID)
+	return s.write(tokens)
+}
@@ -1,16 +1,24 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"time"
 )
 
 type Config struct {
-	Server ServerConfig
-	Redis  RedisConfig
-	Poll   PollConfig
-	Env    string `env:"ENV" env-default:"dev"`
+	Server      ServerConfig
+	Redis       RedisConfig
+	Poll        PollConfig
+	Auth        AuthConfig
+	Receipt     ReceiptConfig
+	Idempotency IdempotencyConfig
+	Webhook     WebhookConfig
+	Chat        ChatConfig
+	SMTP        SMTPConfig
+	Env         string `env:"ENV" env-default:"dev"`
 }
 
 type ServerConfig struct {
@@ -20,6 +28,7 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration `env:"SERVER_WRITE_TIMEOUT" env-default:"10s"`
 	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" env-default:"5s"`
 	BaseURL         string        `env:"BASE_URL" env-default:"http://localhost:8080"`
+	TrustedProxies  []string      `env:"TRUSTED_PROXIES" env-separator:","` // IPs or CIDRs allowed to set X-Forwarded-For, none by default
 }
 
 type RedisConfig struct {
@@ -34,12 +43,71 @@ type PollConfig struct {
 	MaxTTL     time.Duration `env:"POLL_MAX_TTL" env-default:"720h"`     // 30 дней
 }
 
+type AuthConfig struct {
+	AccessTokenSecret string        `env:"ACCESS_TOKEN_SECRET" env-default:""` // random per process when empty
+	AccessTokenTTL    time.Duration `env:"ACCESS_TOKEN_TTL" env-default:"1h"`
+}
+
+type ReceiptConfig struct {
+	SigningKey string `env:"RECEIPT_SIGNING_KEY" env-default:""` // base64 Ed25519 seed, random per process when empty
+}
+
+type IdempotencyConfig struct {
+	Window time.Duration `env:"IDEMPOTENCY_WINDOW" env-default:"24h"` // how long responses are replayed on retries
+}
+
+type WebhookConfig struct {
+	Timeout        time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+	MaxAttempts    int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"` // failed delivery is moved to dead letters after them
+	RetryBaseDelay time.Duration `env:"WEBHOOK_RETRY_BASE_DELAY" env-default:"10s"`
+	RetryMaxDelay  time.Duration `env:"WEBHOOK_RETRY_MAX_DELAY" env-default:"1h"`
+	BatchInterval  time.Duration `env:"WEBHOOK_BATCH_INTERVAL" env-default:"10s"`  // votes are reported by one vote.cast per interval
+	AllowPrivate   bool          `env:"WEBHOOK_ALLOW_PRIVATE" env-default:"false"` // allows webhooks to private addresses for local testing
+}
+
+type ChatConfig struct {
+	SigningSecret     string        `env:"CHAT_SIGNING_SECRET" env-default:""`     // verifies X-Slack-Signature of requests when set
+	VerificationToken string        `env:"CHAT_VERIFICATION_TOKEN" env-default:""` // compared with token of requests when set
+	MaxRequestAge     time.Duration `env:"CHAT_MAX_REQUEST_AGE" env-default:"5m"`  // older signed requests are rejected as replays
+	AllowPrivate      bool          `env:"CHAT_ALLOW_PRIVATE" env-default:"false"` // allows response URLs on private addresses, e.g. chat in local network
+	UpdateInterval    time.Duration `env:"CHAT_UPDATE_INTERVAL" env-default:"5s"`  // poll messages get new counts at most once per interval
+	// Response URLs are posted only to these hosts
+	ResponseHosts []string `env:"CHAT_RESPONSE_HOSTS" env-separator:"," env-default:"hooks.slack.com"`
+}
+
+// Verified reports whether chat requests are authenticated
+func (c ChatConfig) Verified() bool {
+	return c.SigningSecret != "" || c.VerificationToken != ""
+}
+
+type SMTPConfig struct {
+	Host           string        `env:"SMTP_HOST" env-default:""` // email notifications are disabled when empty
+	Port           int           `env:"SMTP_PORT" env-default:"587"`
+	Username       string        `env:"SMTP_USERNAME" env-default:""` // no authentication when empty
+	Password       string        `env:"SMTP_PASSWORD" env-default:""`
+	From           string        `env:"SMTP_FROM" env-default:"Surway <noreply@localhost>"`
+	Timeout        time.Duration `env:"SMTP_TIMEOUT" env-default:"10s"`
+	MaxAttempts    int           `env:"SMTP_MAX_ATTEMPTS" env-default:"6"` // failed email is dropped after them
+	RetryBaseDelay time.Duration `env:"SMTP_RETRY_BASE_DELAY" env-default:"30s"`
+	RetryMaxDelay  time.Duration `env:"SMTP_RETRY_MAX_DELAY" env-default:"1h"`
+	RateLimit      int           `env:"SMTP_RATE_LIMIT" env-default:"20"` // emails per recipient within rate window, 0 disables limit
+	RateWindow     time.Duration `env:"SMTP_RATE_WINDOW" env-default:"24h"`
+}
+
+// Enabled reports whether email notifications can be sent
+func (s SMTPConfig) Enabled() bool {
+	return s.Host != ""
+}
+
 func Load() (*Config, error) {
 	var cfg Config
 
 	if err := cleanenv.ReadEnv(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	if _, err := cfg.Receipt.PrivateKey(); err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
 
 	return &cfg, nil
 }
@@ -53,3 +121,20 @@ func (s ServerConfig) Address() string {
 func (r RedisConfig) Address() string {
 	return fmt.Sprintf("%s:%d", r.Host, r.Port)
 }
+
+// Address returns SMTP server address as host:port
+func (s SMTPConfig) Address() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+// PrivateKey returns Ed25519 key signing vote receipts, it is nil when signing key is not set
+func (r ReceiptConfig) PrivateKey() (ed25519.PrivateKey, error) {
+	if r.SigningKey == "" {
+		return nil, nil
+	}
+	seed, err := base64.StdEncoding.DecodeString(r.SigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("RECEIPT_SIGNING_KEY must be base64 of %d bytes", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/service"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Headers of chat requests signed with signing secret
+const (
+	chatSignatureHeader = "X-Slack-Signature"
+	chatTimestampHeader = "X-Slack-Request-Timestamp"
+)
+
+// chatSignatureVersion prefixes signature and signed string
+const chatSignatureVersion = "v0"
+
+// ChatAuthMiddleware checks that chat requests come from workspace: signature when signing secret is configured
+// and verification token when it is configured. Without both requests are not authenticated.
+func ChatAuthMiddleware(cfg config.ChatConfig, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Verified() {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Failed to read request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if cfg.SigningSecret != "" {
+			err := verifyChatSignature(cfg.SigningSecret, c.GetHeader(chatTimestampHeader), c.GetHeader(chatSignatureHeader), body, time.Now(), cfg.MaxRequestAge)
+			if err != nil {
+				logger.WarnContext(c.Request.Context(), "chat request rejected",
+					slog.String("path", c.Request.URL.Path),
+					slog.String("error", err.Error()),
+				)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, model.ErrorResponse{
+					Error:   "invalid_signature",
+					Message: "Request signature is invalid or expired",
+				})
+				return
+			}
+		}
+		if cfg.VerificationToken != "" {
+			token := chatRequestToken(body)
+			if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.VerificationToken)) != 1 {
+				logger.WarnContext(c.Request.Context(), "chat request rejected",
+					slog.String("path", c.Request.URL.Path),
+					slog.String("error", "wrong verification token"),
+				)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, model.ErrorResponse{
+					Error:   "invalid_token",
+					Message: "Verification token is invalid",
+				})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// verifyChatSignature checks that signature is "v0=" and hex HMAC-SHA256 of "v0:<timestamp>:<body>"
+// keyed by signing secret, and that timestamp is within maxAge from now
+func verifyChatSignature(secret, timestamp, signature string, body []byte, now time.Time, maxAge time.Duration) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxAge || age < -maxAge {
+		return errors.New("timestamp is too old")
+	}
+	if !hmac.Equal([]byte(signature), []byte(chatSignature(secret, timestamp, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// chatSignature signs chat request body with timestamp
+func chatSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(chatSignatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	return chatSignatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// chatRequestToken returns verification token of command form or action payload
+func chatRequestToken(body []byte) string {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ""
+	}
+	if payload := form.Get("payload"); payload != "" {
+		var action model.ChatAction
+		if err := json.Unmarshal([]byte(payload), &action); err != nil {
+			return ""
+		}
+		return action.Token
+	}
+	return form.Get("token")
+}
+
+// ChatCommand godoc
+// @Summary      Chat slash command
+// @Description  Create poll from slash command like `/poll "Lunch?" Pizza Sushi Tacos` and reply with message whose buttons vote.
+// @Description  Admin token of the poll is posted to response_url for the sender only. Invalid command is answered with usage visible to its sender only.
+// @Tags         chat
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        text formData string false "Quoted question followed by options"
+// @Param        user_id formData string true "Chat user ID"
+// @Success      200 {object} model.ChatMessage
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /chat/commands [post]
+func (h *PollHandler) ChatCommand(c *gin.Context) {
+	var cmd model.ChatCommand
+	if err := c.ShouldBind(&cmd); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	message, err := h.service.ChatCommand(c.Request.Context(), &cmd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to create poll",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
+
+// ChatAction godoc
+// @Summary      Chat button click
+// @Description  Vote for option or refresh counts from poll message buttons. Updated message is posted to response_url of payload and returned.
+// @Tags         chat
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        payload formData string true "Interaction payload JSON"
+// @Success      200 {object} model.ChatMessage
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /chat/actions [post]
+func (h *PollHandler) ChatAction(c *gin.Context) {
+	var action model.ChatAction
+	if err := json.Unmarshal([]byte(c.PostForm("payload")), &action); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "payload must be interaction JSON",
+		})
+		return
+	}
+
+	message, err := h.service.ChatAction(c.Request.Context(), &action)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidChatAction) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Action doesn't refer to poll option",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to handle action",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, message)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newChatRouter returns router answering chat command guarded by ChatAuthMiddleware
+func newChatRouter(cfg config.ChatConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router.POST("/chat/commands", ChatAuthMiddleware(cfg, logger), func(c *gin.Context) {
+		c.String(http.StatusOK, c.PostForm("text"))
+	})
+	return router
+}
+
+func postChatCommand(router *gin.Engine, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/chat/commands", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// signedHeader returns headers signing body with secret at time
+func signedHeader(secret, body string, at time.Time) http.Header {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	header := http.Header{}
+	header.Set(chatTimestampHeader, timestamp)
+	header.Set(chatSignatureHeader, chatSignature(secret, timestamp, []byte(body)))
+	return header
+}
+
+func TestChatAuthMiddleware(t *testing.T) {
+	body := url.Values{"user_id": {"U1"}, "text": {`"Lunch?" Pizza Sushi`}, "token": {"verify-me"}}.Encode()
+
+	t.Run("requests are accepted without configuration", func(t *testing.T) {
+		w := postChatCommand(newChatRouter(config.ChatConfig{}), body, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("signed request passes with body intact", func(t *testing.T) {
+		router := newChatRouter(config.ChatConfig{SigningSecret: "secret", MaxRequestAge: 5 * time.Minute})
+		w := postChatCommand(router, body, signedHeader("secret", body, time.Now()))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"Lunch?" Pizza Sushi`, w.Body.String())
+	})
+
+	t.Run("wrong secret is rejected", func(t *testing.T) {
+		router := newChatRouter(config.ChatConfig{SigningSecret: "secret", MaxRequestAge: 5 * time.Minute})
+		w := postChatCommand(router, body, signedHeader("other", body, time.Now()))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_signature")
+	})
+
+	t.Run("old request is rejected", func(t *testing.T) {
+		router := newChatRouter(config.ChatConfig{SigningSecret: "secret", MaxRequestAge: 5 * time.Minute})
+		w := postChatCommand(router, body, signedHeader("secret", body, time.Now().Add(-10*time.Minute)))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("verification token of command", func(t *testing.T) {
+		router := newChatRouter(config.ChatConfig{VerificationToken: "verify-me"})
+		assert.Equal(t, http.StatusOK, postChatCommand(router, body, nil).Code)
+
+		router = newChatRouter(config.ChatConfig{VerificationToken: "other"})
+		w := postChatCommand(router, body, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_token")
+	})
+
+	t.Run("verification token of action payload", func(t *testing.T) {
+		payload, _ := json.Marshal(&model.ChatAction{Type: "block_actions", Token: "verify-me"})
+		assert.Equal(t, "verify-me", chatRequestToken([]byte(url.Values{"payload": {string(payload)}}.Encode())))
+	})
+}
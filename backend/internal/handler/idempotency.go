@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// idempotencyKeyHeader carries client-chosen key making retries of request safe
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotentReplayedHeader marks response replayed from the first request
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength bounds Idempotency-Key chosen by client
+const maxIdempotencyKeyLength = 255
+
+// idempotentCredentialHeaders are part of request fingerprint, retry with another credential is not replayed
+var idempotentCredentialHeaders = []string{pollPasswordHeader, accessTokenHeader, voterTokenHeader, adminTokenHeader}
+
+// responseRecorder copies response body written by handler
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware replays first response to request with Idempotency-Key for window.
+// Key is scoped by method and path, the same key with different body or credentials is rejected.
+// Only successes and client errors not depending on state are stored, other requests can be retried.
+// Stored body is encrypted with key derived from Idempotency-Key, storage keeps only its hash, so secrets
+// in responses like admin token of created poll can't be read from storage.
+func IdempotencyMiddleware(store storage.IdempotencyStorage, window time.Duration, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength),
+			})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Failed to read request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scope := scopedIdempotencyKey(c.Request.Method, c.Request.URL.Path, key)
+		bodyHash := hashRequest(c.Request.Header, body)
+		sealKey := idempotentSealKey(c.Request.Method, c.Request.URL.Path, key)
+
+		stored, err := store.BeginIdempotent(ctx, scope, bodyHash)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to check idempotency key",
+				slog.String("path", c.Request.URL.Path),
+				slog.String("error", err.Error()),
+			)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to check idempotency key",
+			})
+			return
+		}
+		if stored != nil {
+			replayIdempotent(c, stored, bodyHash, sealKey, logger)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Response is kept even if client has gone, that is when it retries
+		ctx = context.WithoutCancel(ctx)
+		status := recorder.Status()
+		if !storableIdempotent(status) {
+			if err := store.ReleaseIdempotent(ctx, scope); err != nil {
+				logger.ErrorContext(ctx, "failed to release idempotency key",
+					slog.String("path", c.Request.URL.Path),
+					slog.String("error", err.Error()),
+				)
+			}
+			return
+		}
+
+		sealed, err := sealIdempotent(sealKey, recorder.body.Bytes())
+		if err == nil {
+			err = store.SaveIdempotent(ctx, scope, &model.IdempotentResponse{
+				BodyHash:    bodyHash,
+				Status:      status,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Body:        sealed,
+			}, window)
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to save idempotent response",
+				slog.String("path", c.Request.URL.Path),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// storableIdempotent reports whether response with status is the same on retry.
+// Errors like not found, conflict or rate limit depend on state and may change.
+func storableIdempotent(status int) bool {
+	switch {
+	case status >= http.StatusOK && status < http.StatusMultipleChoices:
+		return true
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
+
+// replayIdempotent writes stored response if request is the same as the first request
+func replayIdempotent(c *gin.Context, stored *model.IdempotentResponse, bodyHash string, sealKey []byte, logger *slog.Logger) {
+	if stored.BodyHash != bodyHash {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+			Error:   "idempotency_key_reused",
+			Message: "Idempotency-Key was already used with different request body or credentials",
+		})
+		return
+	}
+	if !stored.Completed() {
+		c.AbortWithStatusJSON(http.StatusConflict, model.ErrorResponse{
+			Error:   "idempotency_in_progress",
+			Message: "Request with this Idempotency-Key is still in progress",
+		})
+		return
+	}
+
+	body, err := openIdempotent(sealKey, stored.Body)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "failed to open idempotent response",
+			slog.String("path", c.Request.URL.Path),
+			slog.String("error", err.Error()),
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to replay idempotent response",
+		})
+		return
+	}
+
+	c.Header(idempotentReplayedHeader, "true")
+	c.Data(stored.Status, stored.ContentType, body)
+	c.Abort()
+}
+
+// scopedIdempotencyKey returns storage key of Idempotency-Key on route
+func scopedIdempotencyKey(method, path, key string) string {
+	sum := sha256.Sum256([]byte(method + " " + path + "\n" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// idempotentSealKey returns AES-256 key of stored response of Idempotency-Key on route,
+// it is hashed apart from storage key, so one can't be computed from the other
+func idempotentSealKey(method, path, key string) []byte {
+	sum := sha256.Sum256([]byte("response\n" + method + " " + path + "\n" + key))
+	return sum[:]
+}
+
+// sealIdempotent encrypts response body with AES-GCM, random nonce is prepended to ciphertext
+func sealIdempotent(key, body []byte) ([]byte, error) {
+	aead, err := idempotentCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(body)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, body, nil), nil
+}
+
+// openIdempotent decrypts response body sealed by sealIdempotent
+func openIdempotent(key, sealed []byte) ([]byte, error) {
+	aead, err := idempotentCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed response is too short")
+	}
+	body, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt response: %w", err)
+	}
+	return body, nil
+}
+
+func idempotentCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// hashRequest returns hex SHA-256 of request body and credential headers
+func hashRequest(header http.Header, body []byte) string {
+	hash := sha256.New()
+	for _, name := range idempotentCredentialHeaders {
+		value := header.Get(name)
+		fmt.Fprintf(hash, "%s:%d:%s\n", name, len(value), value)
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
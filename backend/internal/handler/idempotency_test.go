@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotency keeps idempotent responses in memory
+type memoryIdempotency struct {
+	mu        sync.Mutex
+	responses map[string]model.IdempotentResponse
+}
+
+func newMemoryIdempotency() *memoryIdempotency {
+	return &memoryIdempotency{responses: make(map[string]model.IdempotentResponse)}
+}
+
+func (m *memoryIdempotency) BeginIdempotent(_ context.Context, key, bodyHash string) (*model.IdempotentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if response, ok := m.responses[key]; ok {
+		return &response, nil
+	}
+	m.responses[key] = model.IdempotentResponse{BodyHash: bodyHash}
+	return nil, nil
+}
+
+func (m *memoryIdempotency) SaveIdempotent(_ context.Context, key string, response *model.IdempotentResponse, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[key] = *response
+	return nil
+}
+
+func (m *memoryIdempotency) ReleaseIdempotent(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.responses, key)
+	return nil
+}
+
+// newIdempotentRouter returns router counting calls of handler answering with status
+func newIdempotentRouter(store *memoryIdempotency, status int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router.POST("/polls/:id/vote", IdempotencyMiddleware(store, time.Hour, logger), func(c *gin.Context) {
+		*calls++
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(status, gin.H{"call": *calls, "body": string(body)})
+	})
+	return router
+}
+
+func postVote(router *gin.Engine, path, key, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	t.Run("retry replays first response", func(t *testing.T) {
+		calls := 0
+		router := newIdempotentRouter(newMemoryIdempotency(), http.StatusOK, &calls)
+
+		first := postVote(router, "/polls/abc/vote", "key-1", `{"option_indices":[0]}`)
+		retry := postVote(router, "/polls/abc/vote", "key-1", `{"option_indices":[0]}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusOK, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String(), "handler reads body restored by middleware")
+		assert.Contains(t, first.Body.String(), "option_indices")
+		assert.Equal(t, "true", retry.Header().Get(idempotentReplayedHeader))
+		assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	})
+
+	t.Run("stored body is encrypted", func(t *testing.T) {
+		calls := 0
+		store := newMemoryIdempotency()
+		router := newIdempotentRouter(store, http.StatusOK, &calls)
+
+		postVote(router, "/polls/abc/vote", "key-1", `{"option_indices":[0]}`)
+
+		stored := store.responses[scopedIdempotencyKey(http.MethodPost, "/polls/abc/vote", "key-1")]
+		assert.NotContains(t, string(stored.Body), "option_indices", "storage can't read response without key")
+		_, err := openIdempotent(idempotentSealKey(http.MethodPost, "/polls/abc/vote", "key-2"), stored.Body)
+		assert.Error(t, err)
+	})
+
+	t.Run("different body", func(t *testing.T) {
+		calls := 0
+		router := newIdempotentRouter(newMemoryIdempotency(), http.StatusOK, &calls)
+
+		postVote(router, "/polls/abc/vote", "key-1", `{"option_indices":[0]}`)
+		retry := postVote(router, "/polls/abc/vote", "key-1", `{"option_indices":[1]}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusUnprocessableEntity, retry.Code)
+	})
+
+	t.Run("different credentials", func(t *testing.T) {
+		calls := 0
+		router := newIdempotentRouter(newMemoryIdempotency(), http.StatusOK, &calls)
+
+		postVote(router, "/polls/abc/vote", "key-1", "{}", voterTokenHeader, "token-1")
+		retry := postVote(router, "/polls/abc/vote", "key-1", "{}", voterTokenHeader, "token-2")
+		guessed := postVote(router, "/polls/abc/vote", "key-1", "{}", pollPasswordHeader, "guess")
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusUnprocessableEntity, retry.Code, "response isn't replayed to another voter")
+		assert.Equal(t, http.StatusUnprocessableEntity, guessed.Code)
+	})
+
+	t.Run("key is scoped by route", func(t *testing.T) {
+		calls := 0
+		router := newIdempotentRouter(newMemoryIdempotency(), http.StatusOK, &calls)
+
+		postVote(router, "/polls/abc/vote", "key-1", `{"option_indices":[0]}`)
+		postVote(router, "/polls/xyz/vote", "key-1", `{"option_indices":[0]}`)
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("request in progress", func(t *testing.T) {
+		calls := 0
+		store := newMemoryIdempotency()
+		router := newIdempotentRouter(store, http.StatusOK, &calls)
+		_, _ = store.BeginIdempotent(context.Background(), scopedIdempotencyKey(http.MethodPost, "/polls/abc/vote", "key-1"), hashRequest(http.Header{}, []byte("{}")))
+
+		w := postVote(router, "/polls/abc/vote", "key-1", "{}")
+
+		assert.Equal(t, 0, calls)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("server error is not stored", func(t *testing.T) {
+		calls := 0
+		router := newIdempotentRouter(newMemoryIdempotency(), http.StatusInternalServerError, &calls)
+
+		postVote(router, "/polls/abc/vote", "key-1", "{}")
+		postVote(router, "/polls/abc/vote", "key-1", "{}")
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("state dependent error is not stored", func(t *testing.T) {
+		for _, status := range []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusTooManyRequests} {
+			calls := 0
+			router := newIdempotentRouter(newMemoryIdempotency(), status, &calls)
+
+			postVote(router, "/polls/abc/vote", "key-1", "{}")
+			postVote(router, "/polls/abc/vote", "key-1", "{}")
+
+			assert.Equal(t, 2, calls, "status %d", status)
+		}
+	})
+
+	t.Run("client error is replayed", func(t *testing.T) {
+		calls := 0
+		router := newIdempotentRouter(newMemoryIdempotency(), http.StatusBadRequest, &calls)
+
+		postVote(router, "/polls/abc/vote", "key-1", "{}")
+		retry := postVote(router, "/polls/abc/vote", "key-1", "{}")
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusBadRequest, retry.Code)
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		calls := 0
+		router := newIdempotentRouter(newMemoryIdempotency(), http.StatusOK, &calls)
+
+		postVote(router, "/polls/abc/vote", "", "{}")
+		postVote(router, "/polls/abc/vote", "", "{}")
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("too long key", func(t *testing.T) {
+		calls := 0
+		router := newIdempotentRouter(newMemoryIdempotency(), http.StatusOK, &calls)
+
+		w := postVote(router, "/polls/abc/vote", strings.Repeat("k", maxIdempotencyKeyLength+1), "{}")
+
+		assert.Equal(t, 0, calls)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
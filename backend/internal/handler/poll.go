@@ -3,14 +3,38 @@ package handler
 import (
 	"errors"
 	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/lib/random"
 	"github.com/AlexeyLars/surway-service/internal/model"
 	"github.com/AlexeyLars/surway-service/internal/service"
 	"github.com/AlexeyLars/surway-service/internal/storage"
 	"github.com/gin-gonic/gin"
+	"io"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
+// adminTokenHeader carries admin token of poll or survey
+const adminTokenHeader = "X-Admin-Token"
+
+// voterTokenHeader carries voter token returned on voting, it may also be chosen by client
+const voterTokenHeader = "X-Voter-Token"
+
+// Headers carrying credentials of password-protected poll
+const (
+	pollPasswordHeader = "X-Poll-Password"
+	accessTokenHeader  = "X-Access-Token"
+)
+
+// Voter tokens chosen by client must fit these bounds
+const (
+	minVoterTokenLength = 16
+	maxVoterTokenLength = 128
+)
+
+// streamHeartbeatInterval is how often idle results stream is pinged
+const streamHeartbeatInterval = 15 * time.Second
+
 // PollHandler processes HTTP votes requests
 type PollHandler struct {
 	service *service.PollService
@@ -32,8 +56,11 @@ func NewPollHandler(service *service.PollService, logger *slog.Logger) *PollHand
 // @Accept       json
 // @Produce      json
 // @Param        request body model.CreatePollRequest true "Poll data"
+// @Param        Idempotency-Key header string false "Key making retries safe, first response is replayed"
 // @Success      201 {object} model.CreatePollResponse
 // @Failure      400 {object} model.ErrorResponse
+// @Failure      409 {object} model.ErrorResponse
+// @Failure      422 {object} model.ErrorResponse
 // @Failure      500 {object} model.ErrorResponse
 // @Router       /polls [post]
 func (h *PollHandler) CreatePoll(c *gin.Context) {
@@ -52,6 +79,14 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 
 	response, err := h.service.CreatePoll(c.Request.Context(), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidPollConfig) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error:   "internal_error",
 			Message: "Failed to create poll",
@@ -62,17 +97,396 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+// GetPoll godoc
+// @Summary      Get poll
+// @Description  Return poll settings with its status and countdown to opening or closing
+// @Tags         polls
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Success      200 {object} model.PollDetails
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id} [get]
+func (h *PollHandler) GetPoll(c *gin.Context) {
+	pollID := c.Param("id")
+
+	details, err := h.service.GetPoll(c.Request.Context(), pollID)
+	if err != nil {
+		if errors.Is(err, storage.ErrPollNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "poll_not_found",
+				Message: "Poll not found or expired",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get poll",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+// ExtendPoll godoc
+// @Summary      Extend poll
+// @Description  Move poll expiry further, votes and results are kept until the new expiry. Requires admin token.
+// @Description  Expiry can't be moved back or beyond maximum lifetime from poll creation.
+// @Tags         polls
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Param        request body model.ExtendPollRequest true "New expiry"
+// @Success      200 {object} model.PollDetails
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/extend [post]
+func (h *PollHandler) ExtendPoll(c *gin.Context) {
+	pollID := c.Param("id")
+
+	var req model.ExtendPollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	details, err := h.service.ExtendPoll(c.Request.Context(), pollID, token, &req)
+	if err != nil {
+		if errors.Is(err, storage.ErrPollNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "poll_not_found",
+				Message: "Poll not found or expired",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrAdminForbidden) {
+			c.JSON(http.StatusForbidden, model.ErrorResponse{
+				Error:   "forbidden",
+				Message: "Invalid admin token",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidPollConfig) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to extend poll",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+// ClosePoll godoc
+// @Summary      Close poll
+// @Description  Stop voting now, results are kept until expiry. Requires admin token.
+// @Tags         polls
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Success      200 {object} model.PollDetails
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/close [post]
+func (h *PollHandler) ClosePoll(c *gin.Context) {
+	pollID := c.Param("id")
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	details, err := h.service.ClosePoll(c.Request.Context(), pollID, token)
+	if err != nil {
+		if status, response, ok := adminErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to close poll",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+// DeletePoll godoc
+// @Summary      Delete poll
+// @Description  Remove poll with its votes and results. Requires admin token.
+// @Tags         polls
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Success      204
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id} [delete]
+func (h *PollHandler) DeletePoll(c *gin.Context) {
+	pollID := c.Param("id")
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePoll(c.Request.Context(), pollID, token); err != nil {
+		if status, response, ok := adminErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to delete poll",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// IssueAccessToken godoc
+// @Summary      Get access token
+// @Description  Exchange password of password-protected poll for short-lived access token, wrong passwords are rate limited
+// @Tags         polls
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        request body model.PollAccessRequest true "Poll password"
+// @Success      200 {object} model.PollAccessResponse
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      429 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/access [post]
+func (h *PollHandler) IssueAccessToken(c *gin.Context) {
+	pollID := c.Param("id")
+
+	var req model.PollAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	access := pollAccess(c)
+	access.Password = req.Password
+
+	response, err := h.service.IssueAccessToken(c.Request.Context(), pollID, access)
+	if err != nil {
+		if errors.Is(err, storage.ErrPollNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "poll_not_found",
+				Message: "Poll not found or expired",
+			})
+			return
+		}
+		if status, response, ok := pollAccessResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidPollConfig) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to issue access token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateInvites godoc
+// @Summary      Create invites
+// @Description  Generate single-use voter tokens of invite-only poll, either count of tokens or emails getting one token each. Requires admin token.
+// @Description  Tokens are shown once, emails are not stored.
+// @Tags         polls
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Param        request body model.CreateInvitesRequest true "Count or emails"
+// @Success      201 {object} model.CreateInvitesResponse
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/invites [post]
+func (h *PollHandler) CreateInvites(c *gin.Context) {
+	pollID := c.Param("id")
+
+	var req model.CreateInvitesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.CreateInvites(c.Request.Context(), pollID, token, &req)
+	if err != nil {
+		if status, response, ok := adminErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidPollConfig) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to create invites",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetInviteUsage godoc
+// @Summary      Get invite usage
+// @Description  Return numbers of issued, used and remaining invites of poll without telling who voted. Requires admin token.
+// @Tags         polls
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Success      200 {object} model.InviteUsage
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/invites [get]
+func (h *PollHandler) GetInviteUsage(c *gin.Context) {
+	pollID := c.Param("id")
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.service.GetInviteUsage(c.Request.Context(), pollID, token)
+	if err != nil {
+		if status, response, ok := adminErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get invite usage",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// AuditLedger godoc
+// @Summary      Audit vote ledger
+// @Description  Recompute hash chain and tally of vote ledger and compare them with vote counters, requires admin token
+// @Tags         polls
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Success      200 {object} model.LedgerAudit
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/audit [get]
+func (h *PollHandler) AuditLedger(c *gin.Context) {
+	pollID := c.Param("id")
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	audit, err := h.service.AuditLedger(c.Request.Context(), pollID, token)
+	if err != nil {
+		if status, response, ok := adminErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to audit ledger",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, audit)
+}
+
 // Vote godoc
 // @Summary      Vote
-// @Description  Register vote for given option
+// @Description  Register vote for given options, ranked polls expect ordered ranking, score polls expect scores, budget polls expect points, text polls expect text, numeric polls expect number, nps polls expect rating and matrix polls expect matrix
 // @Tags         polls
 // @Accept       json
 // @Produce      json
 // @Param        id path string true "Poll ID"
-// @Param        request body model.VoteRequest true "Option indices"
+// @Param        request body model.VoteRequest true "Option indices or ranking"
+// @Param        X-Voter-Token header string false "Voter token, generated when missing, invite token in invite-only polls"
+// @Param        X-Poll-Password header string false "Password of password-protected poll"
+// @Param        X-Access-Token header string false "Access token of password-protected poll"
+// @Param        Idempotency-Key header string false "Key making retries safe, first response is replayed"
 // @Success      200 {object} model.VoteResponse
 // @Failure      400 {object} model.ErrorResponse
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
 // @Failure      404 {object} model.ErrorResponse
+// @Failure      409 {object} model.ErrorResponse
+// @Failure      422 {object} model.ErrorResponse
+// @Failure      429 {object} model.ErrorResponse
 // @Failure      500 {object} model.ErrorResponse
 // @Router       /polls/{id}/vote [post]
 func (h *PollHandler) Vote(c *gin.Context) {
@@ -91,7 +505,12 @@ func (h *PollHandler) Vote(c *gin.Context) {
 		return
 	}
 
-	err := h.service.Vote(c.Request.Context(), pollID, &req)
+	voter, ok := voterToken(c)
+	if !ok {
+		return
+	}
+
+	receipt, err := h.service.Vote(c.Request.Context(), pollID, &req, voter, pollAccess(c))
 	if err != nil {
 		if errors.Is(err, storage.ErrPollNotFound) {
 			c.JSON(http.StatusNotFound, model.ErrorResponse{
@@ -100,18 +519,12 @@ func (h *PollHandler) Vote(c *gin.Context) {
 			})
 			return
 		}
-		if errors.Is(err, storage.ErrInvalidOption) {
-			c.JSON(http.StatusBadRequest, model.ErrorResponse{
-				Error:   "invalid_option",
-				Message: "Invalid option index",
-			})
+		if status, response, ok := pollAccessResponse(err); ok {
+			c.JSON(status, response)
 			return
 		}
-		if errors.Is(err, storage.ErrDuplicateOption) {
-			c.JSON(http.StatusBadRequest, model.ErrorResponse{
-				Error:   "duplicate_option",
-				Message: "Cannot vote for the same option multiple times",
-			})
+		if status, response, ok := ballotErrorResponse(err); ok {
+			c.JSON(status, response)
 			return
 		}
 
@@ -122,26 +535,383 @@ func (h *PollHandler) Vote(c *gin.Context) {
 		return
 	}
 
+	message := fmt.Sprintf("Votes registered successfully (%d options)", len(req.OptionIndices))
+	switch {
+	case len(req.Ranking) > 0:
+		message = fmt.Sprintf("Ballot registered successfully (%d options ranked)", len(req.Ranking))
+	case len(req.Scores) > 0:
+		message = fmt.Sprintf("Scores registered successfully (%d options rated)", len(req.Scores))
+	case len(req.Points) > 0:
+		message = fmt.Sprintf("Points registered successfully (%d options)", len(req.Points))
+	case len(req.Matrix) > 0:
+		message = fmt.Sprintf("Answers registered successfully (%d rows)", len(req.Matrix))
+	case req.Rating != nil:
+		message = "Rating registered successfully"
+	case req.Number != nil:
+		message = "Number registered successfully"
+	case req.Text != "" && len(req.OptionIndices) == 0:
+		message = "Answer registered successfully"
+	}
+
 	c.JSON(http.StatusOK, model.VoteResponse{
-		Success: true,
-		Message: fmt.Sprintf("Votes registered successfully (%d options)", len(req.OptionIndices)),
+		Success:    true,
+		Message:    message,
+		VoterToken: voter,
+		Receipt:    receipt,
 	})
 }
 
+// GetReceipt godoc
+// @Summary      Verify vote receipt
+// @Description  Check that ballot of vote receipt is in the tally and receipt signature is valid, ballot content is not revealed
+// @Tags         polls
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        receipt path string true "Receipt ID"
+// @Success      200 {object} model.ReceiptStatus
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/receipts/{receipt} [get]
+func (h *PollHandler) GetReceipt(c *gin.Context) {
+	pollID := c.Param("id")
+	receiptID := c.Param("receipt")
+
+	status, err := h.service.GetReceipt(c.Request.Context(), pollID, receiptID)
+	if err != nil {
+		if errors.Is(err, storage.ErrReceiptNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "receipt_not_found",
+				Message: "Receipt not found, poll expired or ballot is not counted",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get receipt",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetReceiptKey godoc
+// @Summary      Receipt public key
+// @Description  Return public key verifying vote receipt signatures offline
+// @Tags         polls
+// @Produce      json
+// @Success      200 {object} model.ReceiptKey
+// @Router       /receipts/key [get]
+func (h *PollHandler) GetReceiptKey(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.ReceiptKey())
+}
+
+// GetTextAnswers godoc
+// @Summary      List text answers
+// @Description  Return page of text answers and "Other" write-ins of poll, requires admin token
+// @Tags         polls
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Param        offset query int false "Number of answers to skip"
+// @Param        limit query int false "Page size, 20 by default, at most 100"
+// @Success      200 {object} model.TextAnswersPage
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/texts [get]
+func (h *PollHandler) GetTextAnswers(c *gin.Context) {
+	pollID := c.Param("id")
+
+	var query model.TextAnswersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.GetTextAnswers(c.Request.Context(), pollID, token, &query)
+	if err != nil {
+		if errors.Is(err, storage.ErrPollNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "poll_not_found",
+				Message: "Poll not found or expired",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrAdminForbidden) {
+			c.JSON(http.StatusForbidden, model.ErrorResponse{
+				Error:   "forbidden",
+				Message: "Invalid admin token",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get text answers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// adminErrorResponse maps missing poll and invalid admin token errors to HTTP response, ok is false for other errors
+func adminErrorResponse(err error) (int, model.ErrorResponse, bool) {
+	switch {
+	case errors.Is(err, storage.ErrPollNotFound):
+		return http.StatusNotFound, model.ErrorResponse{
+			Error:   "poll_not_found",
+			Message: "Poll not found or expired",
+		}, true
+	case errors.Is(err, service.ErrAdminForbidden):
+		return http.StatusForbidden, model.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Invalid admin token",
+		}, true
+	}
+	return 0, model.ErrorResponse{}, false
+}
+
+// adminToken reads admin token header, responds with 401 when it is missing
+func adminToken(c *gin.Context) (string, bool) {
+	token := c.GetHeader(adminTokenHeader)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "unauthorized",
+			Message: "Admin token required in " + adminTokenHeader + " header",
+		})
+		return "", false
+	}
+	return token, true
+}
+
+// voterToken returns voter token from header or generates new one, ok is false if response was written
+func voterToken(c *gin.Context) (string, bool) {
+	token := c.GetHeader(voterTokenHeader)
+	if token == "" {
+		return random.NewToken(), true
+	}
+	if len(token) < minVoterTokenLength || len(token) > maxVoterTokenLength {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: fmt.Sprintf("%s header must be %d-%d characters long", voterTokenHeader, minVoterTokenLength, maxVoterTokenLength),
+		})
+		return "", false
+	}
+	return token, true
+}
+
+// resultsViewer returns tokens identifying who requests results
+func resultsViewer(c *gin.Context) *model.ResultsViewer {
+	return &model.ResultsViewer{
+		AdminToken: c.GetHeader(adminTokenHeader),
+		VoterToken: c.GetHeader(voterTokenHeader),
+		PollAccess: *pollAccess(c),
+	}
+}
+
+// pollAccess returns credentials of password-protected poll from headers
+func pollAccess(c *gin.Context) *model.PollAccess {
+	return &model.PollAccess{
+		Password:    c.GetHeader(pollPasswordHeader),
+		AccessToken: c.GetHeader(accessTokenHeader),
+		Client:      c.ClientIP(),
+	}
+}
+
+// pollAccessResponse maps password protection error to HTTP response, ok is false for other errors
+func pollAccessResponse(err error) (int, model.ErrorResponse, bool) {
+	switch {
+	case errors.Is(err, service.ErrPasswordRequired):
+		return http.StatusUnauthorized, model.ErrorResponse{
+			Error:   "password_required",
+			Message: "Poll password or access token required in " + pollPasswordHeader + " or " + accessTokenHeader + " header",
+		}, true
+	case errors.Is(err, service.ErrWrongPassword):
+		return http.StatusForbidden, model.ErrorResponse{
+			Error:   "wrong_password",
+			Message: "Wrong poll password",
+		}, true
+	case errors.Is(err, service.ErrTooManyAttempts):
+		return http.StatusTooManyRequests, model.ErrorResponse{
+			Error:   "too_many_attempts",
+			Message: "Too many wrong passwords, try again later",
+		}, true
+	}
+	return 0, model.ErrorResponse{}, false
+}
+
+// resultsAccessResponse maps results visibility error to HTTP response, ok is false for other errors
+func resultsAccessResponse(err error) (int, model.ErrorResponse, bool) {
+	switch {
+	case errors.Is(err, service.ErrResultsHidden):
+		return http.StatusForbidden, model.ErrorResponse{
+			Error:   "results_hidden",
+			Message: "Results are hidden by results visibility policy",
+		}, true
+	case errors.Is(err, service.ErrAdminForbidden):
+		return http.StatusForbidden, model.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Invalid admin token",
+		}, true
+	}
+	return pollAccessResponse(err)
+}
+
+// ballotErrorResponse maps ballot validation error to HTTP response, ok is false for other errors
+func ballotErrorResponse(err error) (int, model.ErrorResponse, bool) {
+	if errors.Is(err, storage.ErrNotOpen) {
+		return http.StatusForbidden, model.ErrorResponse{
+			Error:   "not_open",
+			Message: "Voting is not open yet",
+		}, true
+	}
+	if errors.Is(err, storage.ErrClosed) {
+		return http.StatusForbidden, model.ErrorResponse{
+			Error:   "closed",
+			Message: "Voting is closed",
+		}, true
+	}
+	if errors.Is(err, storage.ErrInvalidInvite) {
+		return http.StatusForbidden, model.ErrorResponse{
+			Error:   "invalid_invite",
+			Message: voterTokenHeader + " must be an invite token issued for this poll",
+		}, true
+	}
+	if errors.Is(err, storage.ErrInviteUsed) {
+		return http.StatusForbidden, model.ErrorResponse{
+			Error:   "invite_used",
+			Message: "Invite token has already been used",
+		}, true
+	}
+	if errors.Is(err, storage.ErrAlreadyVoted) {
+		return http.StatusConflict, model.ErrorResponse{
+			Error:   "already_voted",
+			Message: "Voter token has already voted in this poll",
+		}, true
+	}
+	if errors.Is(err, storage.ErrInvalidOption) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_option",
+			Message: "Invalid option index",
+		}, true
+	}
+	if errors.Is(err, storage.ErrDuplicateOption) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "duplicate_option",
+			Message: "Cannot vote for the same option multiple times",
+		}, true
+	}
+	if errors.Is(err, storage.ErrEmptyVote) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "empty_vote",
+			Message: "Vote must contain at least one option",
+		}, true
+	}
+	if errors.Is(err, storage.ErrInvalidScore) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_score",
+			Message: "Score is out of poll scale",
+		}, true
+	}
+	if errors.Is(err, storage.ErrInvalidPoints) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_points",
+			Message: "Points must not be negative",
+		}, true
+	}
+	if errors.Is(err, storage.ErrBudgetExceeded) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "budget_exceeded",
+			Message: "Points exceed poll budget",
+		}, true
+	}
+	if errors.Is(err, storage.ErrVoteTypeMismatch) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "vote_type_mismatch",
+			Message: "Vote does not match poll type",
+		}, true
+	}
+	if errors.Is(err, storage.ErrInvalidQuestion) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_question",
+			Message: "Invalid question index",
+		}, true
+	}
+	if errors.Is(err, storage.ErrDuplicateAnswer) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "duplicate_answer",
+			Message: "Cannot answer the same question multiple times",
+		}, true
+	}
+	if errors.Is(err, storage.ErrInvalidColumn) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_column",
+			Message: "Invalid column index",
+		}, true
+	}
+	if errors.Is(err, storage.ErrInvalidNumber) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_number",
+			Message: "Number is out of range or doesn't match the step",
+		}, true
+	}
+	if errors.Is(err, storage.ErrTextTooLong) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "text_too_long",
+			Message: "Text answer exceeds maximum length",
+		}, true
+	}
+	if errors.Is(err, storage.ErrUnreachableAnswer) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "unreachable_question",
+			Message: "Question is hidden by answers to earlier questions",
+		}, true
+	}
+	if errors.Is(err, storage.ErrMissingAnswer) {
+		return http.StatusBadRequest, model.ErrorResponse{
+			Error:   "missing_answer",
+			Message: "Required question is not answered",
+		}, true
+	}
+
+	return 0, model.ErrorResponse{}, false
+}
+
 // GetResults godoc
 // @Summary      Get results
-// @Description  Return poll results with option's vote counts
+// @Description  Return poll results with option's vote counts, ranked polls include tabulation by poll method.
+// @Description  Results may be hidden by poll results visibility.
 // @Tags         polls
 // @Produce      json
 // @Param        id path string true "Poll ID"
+// @Param        X-Voter-Token header string false "Voter token returned on voting"
+// @Param        X-Admin-Token header string false "Admin token, shows results regardless of visibility and password"
+// @Param        X-Poll-Password header string false "Password of password-protected poll"
+// @Param        X-Access-Token header string false "Access token of password-protected poll"
 // @Success      200 {object} model.PollResults
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
 // @Failure      404 {object} model.ErrorResponse
+// @Failure      429 {object} model.ErrorResponse
 // @Failure      500 {object} model.ErrorResponse
 // @Router       /polls/{id}/results [get]
 func (h *PollHandler) GetResults(c *gin.Context) {
 	pollID := c.Param("id")
 
-	results, err := h.service.GetResults(c.Request.Context(), pollID)
+	results, err := h.service.ViewResults(c.Request.Context(), pollID, resultsViewer(c))
 	if err != nil {
 		if errors.Is(err, storage.ErrPollNotFound) {
 			c.JSON(http.StatusNotFound, model.ErrorResponse{
@@ -150,6 +920,10 @@ func (h *PollHandler) GetResults(c *gin.Context) {
 			})
 			return
 		}
+		if status, response, ok := resultsAccessResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
 
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error:   "internal_error",
@@ -161,6 +935,79 @@ func (h *PollHandler) GetResults(c *gin.Context) {
 	c.JSON(http.StatusOK, results)
 }
 
+// StreamResults godoc
+// @Summary      Stream results
+// @Description  Stream poll results as Server-Sent Events, "results" event is sent on connect and after new votes
+// @Tags         polls
+// @Produce      text/event-stream
+// @Param        id path string true "Poll ID"
+// @Param        X-Voter-Token header string false "Voter token returned on voting"
+// @Param        X-Admin-Token header string false "Admin token, shows results regardless of visibility and password"
+// @Param        X-Poll-Password header string false "Password of password-protected poll"
+// @Param        X-Access-Token header string false "Access token of password-protected poll"
+// @Success      200 {object} model.PollResults
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      429 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/results/stream [get]
+func (h *PollHandler) StreamResults(c *gin.Context) {
+	pollID := c.Param("id")
+
+	stream, err := h.service.WatchResults(c.Request.Context(), pollID, resultsViewer(c))
+	if err != nil {
+		if errors.Is(err, storage.ErrPollNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "poll_not_found",
+				Message: "Poll not found or expired",
+			})
+			return
+		}
+		if status, response, ok := resultsAccessResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to stream results",
+		})
+		return
+	}
+
+	// Stream outlives server write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "failed to clear write deadline of results stream",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case results, ok := <-stream:
+			if !ok {
+				return false
+			}
+			c.SSEvent("results", results)
+		case <-heartbeat.C:
+			// Comment line keeps idle connection open through proxies
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		return true
+	})
+}
+
 // HealthCheck godoc
 // @Summary      Health check
 // @Description  Check service heath state
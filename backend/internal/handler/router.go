@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"log/slog"
@@ -9,13 +10,21 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRouter setups router with middleware and routes
-func SetupRouter(handler *PollHandler, logger *slog.Logger, releaseMode bool) *gin.Engine {
+// SetupRouter setups router with middleware and routes, idempotency guards poll creation and voting,
+// chatAuth guards chat integration endpoints. Client IP is taken from X-Forwarded-For only behind trusted proxies,
+// none are trusted when the list is empty.
+func SetupRouter(handler *PollHandler, idempotency, chatAuth gin.HandlerFunc, trustedProxies []string, logger *slog.Logger, releaseMode bool) (*gin.Engine, error) {
 	if releaseMode {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
+	if len(trustedProxies) == 0 {
+		trustedProxies = nil
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 
 	// Middleware
 	router.Use(gin.Recovery())
@@ -31,13 +40,45 @@ func SetupRouter(handler *PollHandler, logger *slog.Logger, releaseMode bool) *g
 	{
 		polls := v1.Group("/polls")
 		{
-			polls.POST("", handler.CreatePoll)
-			polls.POST("/:id/vote", handler.Vote)
+			polls.POST("", idempotency, handler.CreatePoll)
+			polls.GET("/:id", handler.GetPoll)
+			polls.DELETE("/:id", handler.DeletePoll)
+			polls.POST("/:id/extend", handler.ExtendPoll)
+			polls.POST("/:id/close", handler.ClosePoll)
+			polls.POST("/:id/access", handler.IssueAccessToken)
+			polls.POST("/:id/invites", handler.CreateInvites)
+			polls.GET("/:id/invites", handler.GetInviteUsage)
+			polls.GET("/:id/audit", handler.AuditLedger)
+			polls.POST("/:id/webhooks", handler.AddWebhook)
+			polls.GET("/:id/webhooks", handler.ListWebhooks)
+			polls.GET("/:id/webhooks/deliveries", handler.GetWebhookDeliveries)
+			polls.DELETE("/:id/webhooks/:webhook", handler.DeleteWebhook)
+			polls.POST("/:id/vote", idempotency, handler.Vote)
+			polls.GET("/:id/receipts/:receipt", handler.GetReceipt)
 			polls.GET("/:id/results", handler.GetResults)
+			polls.GET("/:id/results/stream", handler.StreamResults)
+			polls.GET("/:id/texts", handler.GetTextAnswers)
+		}
+
+		v1.GET("/receipts/key", handler.GetReceiptKey)
+
+		surveys := v1.Group("/surveys")
+		{
+			surveys.POST("", handler.CreateSurvey)
+			surveys.GET("/:id", handler.GetSurvey)
+			surveys.POST("/:id/responses", handler.SubmitSurvey)
+			surveys.GET("/:id/results", handler.GetSurveyResults)
+			surveys.GET("/:id/questions/:question/texts", handler.GetSurveyTextAnswers)
+		}
+
+		chat := v1.Group("/chat", chatAuth)
+		{
+			chat.POST("/commands", handler.ChatCommand)
+			chat.POST("/actions", handler.ChatAction)
 		}
 	}
 
-	return router
+	return router, nil
 }
 
 // LoggerMiddleware logs HTTP requests
@@ -68,7 +109,7 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Admin-Token, X-Voter-Token, X-Poll-Password, X-Access-Token, Idempotency-Key")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupRouter_TrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	noop := func(c *gin.Context) {}
+
+	clientIP := func(trustedProxies []string) string {
+		router, err := SetupRouter(NewPollHandler(nil, logger), noop, noop, trustedProxies, logger, false)
+		require.NoError(t, err)
+		router.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "10.0.0.5:4321"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "10.0.0.5", clientIP(nil), "forwarded address is ignored by default")
+	assert.Equal(t, "203.0.113.7", clientIP([]string{"10.0.0.0/8"}))
+
+	_, err := SetupRouter(NewPollHandler(nil, logger), noop, noop, []string{"not an ip"}, logger, false)
+	assert.Error(t, err)
+}
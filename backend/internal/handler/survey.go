@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/service"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSurvey godoc
+// @Summary      Create survey
+// @Description  Create new survey with ordered list of questions, each question has its own type and options
+// @Tags         surveys
+// @Accept       json
+// @Produce      json
+// @Param        request body model.CreateSurveyRequest true "Survey data"
+// @Success      201 {object} model.CreateSurveyResponse
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /surveys [post]
+func (h *PollHandler) CreateSurvey(c *gin.Context) {
+	var req model.CreateSurveyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "invalid survey request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response, err := h.service.CreateSurvey(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPollConfig) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to create survey",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetSurvey godoc
+// @Summary      Get survey
+// @Description  Return survey definition with branching graph, poll ID returns the poll as one-question survey
+// @Tags         surveys
+// @Produce      json
+// @Param        id path string true "Survey ID"
+// @Success      200 {object} model.SurveyDefinition
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /surveys/{id} [get]
+func (h *PollHandler) GetSurvey(c *gin.Context) {
+	surveyID := c.Param("id")
+
+	survey, err := h.service.GetSurvey(c.Request.Context(), surveyID)
+	if err != nil {
+		if errors.Is(err, storage.ErrSurveyNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "survey_not_found",
+				Message: "Survey not found or expired",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get survey",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, survey)
+}
+
+// SubmitSurvey godoc
+// @Summary      Submit survey
+// @Description  Register answers to survey questions atomically, quiz submissions are scored
+// @Tags         surveys
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Survey ID"
+// @Param        request body model.SubmitSurveyRequest true "Answers"
+// @Param        X-Voter-Token header string false "Voter token, generated when missing"
+// @Param        X-Poll-Password header string false "Password of password-protected poll answered as survey"
+// @Param        X-Access-Token header string false "Access token of password-protected poll answered as survey"
+// @Success      200 {object} model.SubmitSurveyResponse
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      409 {object} model.ErrorResponse
+// @Failure      429 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /surveys/{id}/responses [post]
+func (h *PollHandler) SubmitSurvey(c *gin.Context) {
+	surveyID := c.Param("id")
+
+	var req model.SubmitSurveyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "invalid survey submission",
+			slog.String("survey_id", surveyID),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	voter, ok := voterToken(c)
+	if !ok {
+		return
+	}
+
+	score, err := h.service.SubmitSurvey(c.Request.Context(), surveyID, &req, voter, pollAccess(c))
+	if err != nil {
+		if errors.Is(err, storage.ErrSurveyNotFound) || errors.Is(err, storage.ErrPollNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "survey_not_found",
+				Message: "Survey not found or expired",
+			})
+			return
+		}
+		if status, response, ok := pollAccessResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+		if status, response, ok := ballotErrorResponse(err); ok {
+			var answerErr *storage.AnswerError
+			if errors.As(err, &answerErr) {
+				response.Message = fmt.Sprintf("Question %d: %s", answerErr.Question, response.Message)
+			}
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to submit survey",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SubmitSurveyResponse{
+		Success:    true,
+		Message:    fmt.Sprintf("Survey submitted successfully (%d answers)", len(req.Answers)),
+		VoterToken: voter,
+		Score:      score,
+	})
+}
+
+// GetSurveyResults godoc
+// @Summary      Get survey results
+// @Description  Return per-question results of survey, results may be hidden by survey results visibility
+// @Tags         surveys
+// @Produce      json
+// @Param        id path string true "Survey ID"
+// @Param        X-Voter-Token header string false "Voter token returned on submission"
+// @Param        X-Admin-Token header string false "Admin token, shows results regardless of visibility"
+// @Param        X-Poll-Password header string false "Password of password-protected poll requested as survey"
+// @Param        X-Access-Token header string false "Access token of password-protected poll requested as survey"
+// @Success      200 {object} model.SurveyResults
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /surveys/{id}/results [get]
+func (h *PollHandler) GetSurveyResults(c *gin.Context) {
+	surveyID := c.Param("id")
+
+	results, err := h.service.ViewSurveyResults(c.Request.Context(), surveyID, resultsViewer(c))
+	if err != nil {
+		if errors.Is(err, storage.ErrSurveyNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "survey_not_found",
+				Message: "Survey not found or expired",
+			})
+			return
+		}
+		if status, response, ok := resultsAccessResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get survey results",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetSurveyTextAnswers godoc
+// @Summary      List survey text answers
+// @Description  Return page of text answers and "Other" write-ins of survey question, requires admin token
+// @Tags         surveys
+// @Produce      json
+// @Param        id path string true "Survey ID"
+// @Param        question path int true "Question index"
+// @Param        X-Admin-Token header string true "Admin token returned on survey creation"
+// @Param        offset query int false "Number of answers to skip"
+// @Param        limit query int false "Page size, 20 by default, at most 100"
+// @Success      200 {object} model.TextAnswersPage
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /surveys/{id}/questions/{question}/texts [get]
+func (h *PollHandler) GetSurveyTextAnswers(c *gin.Context) {
+	surveyID := c.Param("id")
+
+	question, err := strconv.Atoi(c.Param("question"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_question",
+			Message: "Question must be an index",
+		})
+		return
+	}
+
+	var query model.TextAnswersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.GetSurveyTextAnswers(c.Request.Context(), surveyID, question, token, &query)
+	if err != nil {
+		if errors.Is(err, storage.ErrSurveyNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "survey_not_found",
+				Message: "Survey not found or expired",
+			})
+			return
+		}
+		if errors.Is(err, service.ErrAdminForbidden) {
+			c.JSON(http.StatusForbidden, model.ErrorResponse{
+				Error:   "forbidden",
+				Message: "Invalid admin token",
+			})
+			return
+		}
+		if status, response, ok := ballotErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get text answers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
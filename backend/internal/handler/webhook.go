@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/service"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// AddWebhook godoc
+// @Summary      Add webhook
+// @Description  Register URL receiving poll.created, vote.cast, poll.closed and poll.expired events of poll, all events when none are given. Requires admin token.
+// @Description  Secret signing requests is shown once.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Param        request body model.CreateWebhookRequest true "Webhook URL and events"
+// @Success      201 {object} model.Webhook
+// @Failure      400 {object} model.ErrorResponse
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      409 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/webhooks [post]
+func (h *PollHandler) AddWebhook(c *gin.Context) {
+	pollID := c.Param("id")
+
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.AddWebhook(c.Request.Context(), pollID, token, &req)
+	if err != nil {
+		if status, response, ok := adminErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidPollConfig) {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, storage.ErrTooManyWebhooks) {
+			c.JSON(http.StatusConflict, model.ErrorResponse{
+				Error:   "too_many_webhooks",
+				Message: fmt.Sprintf("Poll can have at most %d webhooks", model.MaxWebhooks),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to add webhook",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// ListWebhooks godoc
+// @Summary      List webhooks
+// @Description  Get webhooks of poll without secrets. Requires admin token.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Success      200 {array} model.Webhook
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/webhooks [get]
+func (h *PollHandler) ListWebhooks(c *gin.Context) {
+	pollID := c.Param("id")
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.service.ListWebhooks(c.Request.Context(), pollID, token)
+	if err != nil {
+		if status, response, ok := adminErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to list webhooks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete webhook
+// @Description  Remove webhook of poll, its pending deliveries are dropped. Requires admin token.
+// @Tags         webhooks
+// @Param        id path string true "Poll ID"
+// @Param        webhook path string true "Webhook ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Success      204
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/webhooks/{webhook} [delete]
+func (h *PollHandler) DeleteWebhook(c *gin.Context) {
+	pollID := c.Param("id")
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhook(c.Request.Context(), pollID, token, c.Param("webhook")); err != nil {
+		if status, response, ok := adminErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			c.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error:   "webhook_not_found",
+				Message: "Webhook not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to delete webhook",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary      Webhook delivery log
+// @Description  Get recent delivery attempts and dead letters of poll webhooks, newest first. Requires admin token.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id path string true "Poll ID"
+// @Param        X-Admin-Token header string true "Admin token returned on poll creation"
+// @Success      200 {object} model.WebhookDeliveryLog
+// @Failure      401 {object} model.ErrorResponse
+// @Failure      403 {object} model.ErrorResponse
+// @Failure      404 {object} model.ErrorResponse
+// @Failure      500 {object} model.ErrorResponse
+// @Router       /polls/{id}/webhooks/deliveries [get]
+func (h *PollHandler) GetWebhookDeliveries(c *gin.Context) {
+	pollID := c.Param("id")
+
+	token, ok := adminToken(c)
+	if !ok {
+		return
+	}
+
+	log, err := h.service.GetWebhookDeliveries(c.Request.Context(), pollID, token)
+	if err != nil {
+		if status, response, ok := adminErrorResponse(err); ok {
+			c.JSON(status, response)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get webhook delivery log",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, log)
+}
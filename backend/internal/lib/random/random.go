@@ -1,6 +1,9 @@
 package random
 
 import (
+	crand "crypto/rand"
+	"encoding/base64"
+	"fmt"
 	"math/rand"
 	"time"
 )
@@ -20,3 +23,13 @@ func NewRandomString(size int) string {
 
 	return string(b)
 }
+
+// NewToken generates URL-safe secret token from crypto random source
+func NewToken() string {
+	b := make([]byte, 24)
+	if _, err := crand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,120 @@
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for requests to addresses which are not public unicast ones
+var ErrPrivateAddress = errors.New("private address is not allowed")
+
+// reservedPrefixes are special purpose ranges which may lead into local or provider network, see IANA registries
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network", Linux routes it to local host
+	netip.MustParsePrefix("10.0.0.0/8"),     // private
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT, cloud metadata services live there too
+	netip.MustParsePrefix("127.0.0.0/8"),    // loopback
+	netip.MustParsePrefix("169.254.0.0/16"), // link-local, cloud metadata services
+	netip.MustParsePrefix("172.16.0.0/12"),  // private
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("192.168.0.0/16"), // private
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("224.0.0.0/4"),    // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved and broadcast
+	netip.MustParsePrefix("::/96"),          // unspecified, loopback and IPv4-compatible
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("100::/64"),       // discard-only
+	netip.MustParsePrefix("2001::/32"),      // Teredo, embedded IPv4 is obfuscated
+	netip.MustParsePrefix("fc00::/7"),       // unique local
+	netip.MustParsePrefix("fe80::/10"),      // link-local
+	netip.MustParsePrefix("ff00::/8"),       // multicast
+}
+
+// nat64Prefix is well-known NAT64 prefix, IPv4 address is in last 4 bytes
+var nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// sixToFourPrefix is 6to4 prefix, IPv4 address is in bytes 2-5
+var sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+
+// Private reports whether ip is not public unicast address and must not be requested on behalf of users.
+// IPv6 addresses embedding IPv4 one are checked by it.
+func Private(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return true
+	}
+	return reserved(addr.Unmap())
+}
+
+// reserved reports whether addr is in reserved range or embeds reserved IPv4 address
+func reserved(addr netip.Addr) bool {
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	b := addr.As16()
+	switch {
+	case nat64Prefix.Contains(addr):
+		return reserved(netip.AddrFrom4([4]byte(b[12:16])))
+	case sixToFourPrefix.Contains(addr):
+		return reserved(netip.AddrFrom4([4]byte(b[2:6])))
+	}
+	return false
+}
+
+// CheckHost rejects host of URL given by user which is private address or localhost.
+// Other names are checked when they are resolved, see NewClient.
+func CheckHost(target *url.URL) error {
+	host := strings.TrimSuffix(strings.ToLower(target.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && Private(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// NewClient returns client for URLs given by users. Resolved address is checked when connection is dialed,
+// so DNS can't point client into private network, redirects are not followed and proxies from environment are ignored.
+// allowPrivate turns the check off for local testing.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = controlPublic
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// controlPublic refuses connection to private address right before it is made
+func controlPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || Private(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
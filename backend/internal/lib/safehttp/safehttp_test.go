@@ -0,0 +1,62 @@
+package safehttp
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivate(t *testing.T) {
+	for _, ip := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1",
+		"100.100.100.200", "100.64.0.1", "0.1.2.3", "198.18.0.1", "198.19.255.255", "240.0.0.1", "255.255.255.255", "224.0.0.1",
+		"64:ff9b::a00:1", "64:ff9b::6464:64c8", "2002:7f00:1::", "2001:0:4136:e378::", "ff02::1",
+	} {
+		assert.True(t, Private(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"203.0.113.7", "8.8.8.8", "100.128.0.1", "2001:4860:4860::8888", "64:ff9b::808:808", "2002:808:808::"} {
+		assert.False(t, Private(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	for _, target := range []string{"http://127.0.0.1:8080/hook", "http://[::1]/hook", "http://169.254.169.254/latest", "http://localhost/hook", "http://api.localhost./hook", "http://100.100.100.200/latest/meta-data", "http://[64:ff9b::a9fe:a9fe]/latest"} {
+		parsed, err := url.Parse(target)
+		require.NoError(t, err)
+		assert.ErrorIs(t, CheckHost(parsed), ErrPrivateAddress, target)
+	}
+
+	parsed, err := url.Parse("https://example.com/hook")
+	require.NoError(t, err)
+	assert.NoError(t, CheckHost(parsed))
+}
+
+func TestNewClient(t *testing.T) {
+	redirected := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/target" {
+			redirected = true
+		}
+		http.Redirect(w, r, "/target", http.StatusFound)
+	}))
+	defer server.Close()
+
+	t.Run("private address is refused when dialed", func(t *testing.T) {
+		_, err := NewClient(time.Second, false).Get(server.URL)
+		assert.ErrorIs(t, err, ErrPrivateAddress)
+	})
+
+	t.Run("redirect is not followed", func(t *testing.T) {
+		resp, err := NewClient(time.Second, true).Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+		assert.False(t, redirected)
+	})
+}
@@ -0,0 +1,64 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Text normalizes free-text answer before storage: invalid UTF-8, control and invisible
+// formatting characters (zero-width, bidi overrides) are removed, text is NFC normalized,
+// spaces are collapsed and at most one blank line is kept between paragraphs.
+// Markup is kept as literal text, clients escape it when rendering.
+func Text(s string) string {
+	s = norm.NFC.String(strings.ToValidUTF8(s, ""))
+	s = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	blankLines := 0
+	for _, line := range strings.Split(s, "\n") {
+		line = cleanLine(line)
+		if line == "" {
+			blankLines++
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+			if blankLines > 0 {
+				b.WriteByte('\n')
+			}
+		}
+		b.WriteString(line)
+		blankLines = 0
+	}
+
+	return b.String()
+}
+
+// cleanLine drops control and formatting characters and collapses whitespace runs into single space
+func cleanLine(line string) string {
+	var b strings.Builder
+	b.Grow(len(line))
+
+	space := false
+	for _, r := range line {
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			continue
+		default:
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "Great talk", want: "Great talk"},
+		{name: "trims and collapses spaces", in: "  Great \t  talk  ", want: "Great talk"},
+		{name: "keeps one blank line", in: "First\r\n\r\n\r\n\r\nSecond\rThird", want: "First\n\nSecond\nThird"},
+		{name: "drops control characters", in: "bell\a and\x00 nul", want: "bell and nul"},
+		{name: "drops invisible formatting", in: "pay\u200bpal \u202egnp.exe\u202c", want: "paypal gnp.exe"},
+		{name: "drops invalid UTF-8", in: "ok\xff\xfe!", want: "ok!"},
+		{name: "composes to NFC", in: "e\u0301te\u0301", want: "\u00e9t\u00e9"},
+		{name: "keeps markup as text", in: "<b>bold</b> & co", want: "<b>bold</b> & co"},
+		{name: "only whitespace", in: " \n\u200b\t ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Text(tt.in))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package terms
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/AlexeyLars/surway-service/internal/lib/sanitize"
+)
+
+// Normalize turns short phrase into word cloud term: text is sanitized and lower-cased,
+// punctuation around words is dropped, words are separated by single spaces and English
+// plurals are stemmed, so "Meetings!" and "meeting" are the same term.
+func Normalize(phrase string) string {
+	words := strings.Fields(strings.ToLower(sanitize.Text(phrase)))
+
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		if word == "" {
+			continue
+		}
+		normalized = append(normalized, stem(word))
+	}
+
+	return strings.Join(normalized, " ")
+}
+
+// stem strips English plural endings so terms stay readable, short words and non-ASCII words are kept as is
+func stem(word string) string {
+	for _, r := range word {
+		if r > unicode.MaxASCII {
+			return word
+		}
+	}
+
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 4 && (strings.HasSuffix(word, "ches") || strings.HasSuffix(word, "shes") ||
+		strings.HasSuffix(word, "xes") || strings.HasSuffix(word, "sses")):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") &&
+		!strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return word[:len(word)-1]
+	}
+
+	return word
+}
@@ -0,0 +1,36 @@
+package terms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Chaotic", want: "chaotic"},
+		{in: "  Too   MANY meetings!! ", want: "too many meeting"},
+		{in: "Meeting", want: "meeting"},
+		{in: "meetings", want: "meeting"},
+		{in: "blocked", want: "blocked"},
+		{in: "stories", want: "story"},
+		{in: "Fixes", want: "fix"},
+		{in: "branches", want: "branch"},
+		{in: "processes", want: "process"},
+		{in: "success", want: "success"},
+		{in: "focus", want: "focus"},
+		{in: "bus", want: "bus"},
+		{in: "\"fun\"", want: "fun"},
+		{in: "Продуктивный", want: "продуктивный"},
+		{in: "!!!", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, Normalize(tt.in))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// PollAccess carries credentials of password-protected poll, either password or access token is enough
+type PollAccess struct {
+	Password    string
+	AccessToken string
+	Client      string // client address, wrong passwords are rate limited per client
+}
+
+// PollAccessRequest exchanges poll password for access token
+type PollAccessRequest struct {
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+// PollAccessResponse is short-lived access token of password-protected poll
+type PollAccessResponse struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,90 @@
+package model
+
+// Chat payloads follow Slack slash command and Block Kit formats, Mattermost accepts them as well
+
+// ChatResponseType defines who sees reply to chat command or action
+type ChatResponseType string
+
+const (
+	// ChatInChannel shows reply to everyone in channel
+	ChatInChannel ChatResponseType = "in_channel"
+
+	// ChatEphemeral shows reply to the user who sent command or clicked button only
+	ChatEphemeral ChatResponseType = "ephemeral"
+)
+
+// Action IDs of poll message buttons
+const (
+	ChatActionVote    = "vote"    // value is "<poll_id>:<option index>"
+	ChatActionRefresh = "refresh" // value is poll ID
+)
+
+// ChatCommand is slash command sent by chat as form
+type ChatCommand struct {
+	Token       string `form:"token"` // verification token of command, checked by middleware
+	TeamID      string `form:"team_id"`
+	ChannelID   string `form:"channel_id"`
+	UserID      string `form:"user_id" binding:"required"`
+	UserName    string `form:"user_name"`
+	Command     string `form:"command"`
+	Text        string `form:"text"`
+	ResponseURL string `form:"response_url"`
+}
+
+// ChatAction is button click sent by chat in "payload" form field as JSON
+type ChatAction struct {
+	Type        string           `json:"type"`
+	Token       string           `json:"token,omitempty"`
+	Team        ChatTeam         `json:"team"`
+	User        ChatUser         `json:"user"`
+	ResponseURL string           `json:"response_url,omitempty"`
+	Actions     []ChatActionItem `json:"actions"`
+}
+
+type ChatTeam struct {
+	ID string `json:"id"`
+}
+
+type ChatUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username,omitempty"`
+}
+
+// ChatActionItem is clicked button
+type ChatActionItem struct {
+	ActionID string `json:"action_id"`
+	BlockID  string `json:"block_id,omitempty"`
+	Value    string `json:"value"`
+}
+
+// ChatMessage is reply to command or action, it is also posted to response URL of action to update poll message
+type ChatMessage struct {
+	ResponseType    ChatResponseType `json:"response_type,omitempty"`
+	ReplaceOriginal bool             `json:"replace_original,omitempty"`
+	Text            string           `json:"text"` // fallback for notifications and clients without blocks
+	Blocks          []ChatBlock      `json:"blocks,omitempty"`
+}
+
+// ChatBlock is Block Kit layout block: section or actions
+type ChatBlock struct {
+	Type      string        `json:"type"`
+	BlockID   string        `json:"block_id,omitempty"`
+	Text      *ChatText     `json:"text,omitempty"`
+	Accessory *ChatElement  `json:"accessory,omitempty"`
+	Elements  []ChatElement `json:"elements,omitempty"`
+}
+
+// ChatText is plain_text or mrkdwn text object
+type ChatText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ChatElement is button of section or actions block
+type ChatElement struct {
+	Type     string    `json:"type"`
+	ActionID string    `json:"action_id,omitempty"`
+	Text     *ChatText `json:"text,omitempty"`
+	Value    string    `json:"value,omitempty"`
+	Style    string    `json:"style,omitempty"` // primary or danger
+}
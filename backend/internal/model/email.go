@@ -0,0 +1,36 @@
+package model
+
+// EmailReason tells why poll creator is notified
+type EmailReason string
+
+const (
+	// EmailPollClosed is sent when voting closes, polls without closing time are reported shortly before expiry
+	EmailPollClosed EmailReason = "poll.closed"
+
+	// EmailVoteThreshold is sent once when poll reaches number of votes chosen by creator
+	EmailVoteThreshold EmailReason = "vote.threshold"
+)
+
+// EmailNotification is opt-in of poll creator to email notifications, it is kept apart from poll
+type EmailNotification struct {
+	Email         string `json:"email"`
+	Threshold     int64  `json:"threshold,omitempty"`      // votes triggering EmailVoteThreshold, none when zero
+	ThresholdSent bool   `json:"threshold_sent,omitempty"` // EmailVoteThreshold was queued
+}
+
+// Email is rendered message
+type Email struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// EmailDelivery is email queued for sending, it is retried until max attempts
+type EmailDelivery struct {
+	ID       string      `json:"id"`
+	PollID   string      `json:"poll_id"`
+	Reason   EmailReason `json:"reason"`
+	Email    Email       `json:"email"`
+	Attempts int         `json:"attempts"` // failed attempts so far
+}
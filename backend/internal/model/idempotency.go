@@ -0,0 +1,14 @@
+package model
+
+// IdempotentResponse is first response to request with Idempotency-Key, replayed on retries
+type IdempotentResponse struct {
+	BodyHash    string `json:"body_hash"` // hex SHA-256 of request body and credential headers
+	Status      int    `json:"status"`    // zero while first request is in progress
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"` // AES-GCM sealed with key derived from Idempotency-Key
+}
+
+// Completed reports whether first request has finished and its response can be replayed
+func (r *IdempotentResponse) Completed() bool {
+	return r.Status != 0
+}
@@ -0,0 +1,26 @@
+package model
+
+// CreateInvitesRequest generates single-use voter tokens of invite-only poll, either count or emails is given
+type CreateInvitesRequest struct {
+	Count  int      `json:"count,omitempty" binding:"omitempty,min=1,max=1000"`
+	Emails []string `json:"emails,omitempty" binding:"omitempty,max=1000,dive,required,email"` // one token per email
+}
+
+// Invite is single-use voter token, email is not stored
+type Invite struct {
+	Email string `json:"email,omitempty"`
+	Token string `json:"token"`
+}
+
+// CreateInvitesResponse holds generated invites, tokens are shown once
+type CreateInvitesResponse struct {
+	Invites []Invite `json:"invites"`
+	InviteUsage
+}
+
+// InviteUsage counts invites of poll, it doesn't tell which invite voted for what
+type InviteUsage struct {
+	Issued    int `json:"issued"`
+	Used      int `json:"used"`
+	Remaining int `json:"remaining"`
+}
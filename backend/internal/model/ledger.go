@@ -0,0 +1,20 @@
+package model
+
+// LedgerAudit compares hash-chained vote ledger of poll with its vote counters
+type LedgerAudit struct {
+	PollID     string `json:"poll_id"`
+	Entries    int    `json:"entries"`
+	HeadHash   string `json:"head_hash,omitempty"` // hash of the last ledger entry
+	ChainValid bool   `json:"chain_valid"`
+	BrokenAt   *int64 `json:"broken_at,omitempty"` // sequence number of the first entry not matching the chain
+	Consistent bool   `json:"consistent"`          // chain is valid and counters match ledger tally
+
+	Divergences []CounterDivergence `json:"divergences,omitempty"`
+}
+
+// CounterDivergence is vote counter not matching tally recomputed from ledger
+type CounterDivergence struct {
+	Field   string `json:"field"` // counter field, option index for most poll types
+	Ledger  int    `json:"ledger"`
+	Counter int    `json:"counter"`
+}
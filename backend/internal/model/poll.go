@@ -1,41 +1,360 @@
 package model
 
 import (
+	"math"
 	"time"
 )
 
+// PollType defines how voters express their choice and how votes are counted
+type PollType string
+
+const (
+	// PollTypeChoice lets voter pick one or more options (approval counting)
+	PollTypeChoice PollType = "choice"
+
+	// PollTypeRanked lets voter order options by preference
+	PollTypeRanked PollType = "ranked"
+
+	// PollTypeScore lets voter rate options within a scale
+	PollTypeScore PollType = "score"
+
+	// PollTypeBudget lets voter spread a budget of points across options (dot voting)
+	PollTypeBudget PollType = "budget"
+
+	// PollTypeText collects free-text answers, it has no options
+	PollTypeText PollType = "text"
+
+	// PollTypeNumeric collects numbers within a range (slider), it has no options
+	PollTypeNumeric PollType = "numeric"
+
+	// PollTypeNPS collects Net Promoter Score ratings on fixed 0-10 scale, options are the ratings
+	PollTypeNPS PollType = "nps"
+
+	// PollTypeMatrix lets voter pick a column for every row (Likert grid), options are the rows
+	PollTypeMatrix PollType = "matrix"
+
+	// PollTypeWordCloud collects short phrases counted as normalized terms, it has no options
+	PollTypeWordCloud PollType = "wordcloud"
+)
+
+// ScoreScale is inclusive range of scores in score polls
+type ScoreScale struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// PointBudget limits points each voter may spend in budget polls
+type PointBudget struct {
+	Points    int  `json:"points"`
+	Quadratic bool `json:"quadratic,omitempty"` // k votes for an option cost k*k points
+}
+
+// NumericRange is inclusive range of numbers in numeric polls, answers must be Min plus multiple of Step
+type NumericRange struct {
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Step    float64 `json:"step"`
+	Buckets int     `json:"buckets,omitempty"` // histogram buckets in results
+}
+
+// numericTolerance absorbs floating point error when matching number to a step
+const numericTolerance = 1e-9
+
+// Steps returns number of distinct values in range
+func (r *NumericRange) Steps() int {
+	return int(math.Floor((r.Max-r.Min)/r.Step+numericTolerance)) + 1
+}
+
+// Value returns number at step index
+func (r *NumericRange) Value(step int) float64 {
+	return r.Min + float64(step)*r.Step
+}
+
+// StepOf returns step index of number, ok is false when number is out of range or between steps
+func (r *NumericRange) StepOf(number float64) (int, bool) {
+	step := int(math.Round((number - r.Min) / r.Step))
+	if step < 0 || step >= r.Steps() {
+		return 0, false
+	}
+	if math.Abs(r.Value(step)-number) > numericTolerance*math.Max(1, math.Abs(number)) {
+		return 0, false
+	}
+	return step, true
+}
+
+// Tabulation defines how ranked ballots are counted
+type Tabulation string
+
+const (
+	// TabulationIRV counts ranked ballots by instant-runoff
+	TabulationIRV Tabulation = "irv"
+
+	// TabulationSchulze finds Condorcet winner using the Schulze method
+	TabulationSchulze Tabulation = "schulze"
+)
+
+// ResultsVisibility defines who can see results of poll or survey before its admin
+type ResultsVisibility string
+
+const (
+	// ResultsAlways shows results to everyone at any time
+	ResultsAlways ResultsVisibility = "always"
+
+	// ResultsAfterVote shows results to voters who already voted, and to everyone once closed
+	ResultsAfterVote ResultsVisibility = "after_vote"
+
+	// ResultsAfterClose shows results to everyone once closed
+	ResultsAfterClose ResultsVisibility = "after_close"
+
+	// ResultsCreator shows results to the admin token holder only
+	ResultsCreator ResultsVisibility = "creator"
+)
+
+// ResultsViewer identifies who requests results, all credentials are optional
+type ResultsViewer struct {
+	AdminToken string
+	VoterToken string
+	PollAccess
+}
+
 type Poll struct {
-	ID        string    `json:"id"`
-	Title     string    `json:"title"`
-	Options   []string  `json:"options"`
+	ID         string        `json:"id"`
+	Title      string        `json:"title"`
+	Type       PollType      `json:"type,omitempty"`
+	Tabulation Tabulation    `json:"tabulation,omitempty"`  // ranked polls only
+	Scale      *ScoreScale   `json:"scale,omitempty"`       // score polls only
+	Budget     *PointBudget  `json:"budget,omitempty"`      // budget polls only
+	AllowOther bool          `json:"allow_other,omitempty"` // choice polls accept "Other" write-in
+	MaxLength  int           `json:"max_length,omitempty"`  // text and word cloud polls and write-ins, in characters
+	TopTerms   int           `json:"top_terms,omitempty"`   // word cloud polls, terms shown in results
+	Range      *NumericRange `json:"range,omitempty"`       // numeric polls only
+	Columns    []string      `json:"columns,omitempty"`     // matrix polls only, options are the rows
+	Options    []string      `json:"options"`
+
+	ResultsVisibility ResultsVisibility `json:"results_visibility,omitempty"`
+	Schedule
+	Protected  bool `json:"protected,omitempty"`   // voting and results require password or access token
+	InviteOnly bool `json:"invite_only,omitempty"` // voter token must be unused invite
+
 	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
+
+	AdminTokenHash string `json:"-"` // stored apart from poll info, never exposed
+	PasswordHash   string `json:"-"` // salted, stored apart from poll info, never exposed
+}
+
+// VotingType returns poll type, polls created before types were introduced are choice polls
+func (p *Poll) VotingType() PollType {
+	if p.Type == "" {
+		return PollTypeChoice
+	}
+	return p.Type
+}
+
+// Visibility returns results visibility of poll, results are always visible by default
+func (p *Poll) Visibility() ResultsVisibility {
+	if p.ResultsVisibility == "" {
+		return ResultsAlways
+	}
+	return p.ResultsVisibility
+}
+
+// RankedTabulation returns tabulation method of ranked poll, instant-runoff by default
+func (p *Poll) RankedTabulation() Tabulation {
+	if p.Tabulation == "" {
+		return TabulationIRV
+	}
+	return p.Tabulation
 }
 
 type CreatePollRequest struct {
-	Title   string   `json:"title" binding:"required,min=3,max=200"`
-	Options []string `json:"options" binding:"required,min=2,max=10,dive,required,min=1,max=100"`
+	Title      string        `json:"title" binding:"required,min=3,max=200"`
+	Type       PollType      `json:"type,omitempty" binding:"omitempty,oneof=choice ranked score budget text numeric nps matrix wordcloud"`
+	Tabulation Tabulation    `json:"tabulation,omitempty" binding:"omitempty,oneof=irv schulze"`
+	Scale      *ScoreScale   `json:"scale,omitempty"`                                                          // score polls, 1-5 by default
+	Budget     *PointBudget  `json:"budget,omitempty"`                                                         // budget polls, 10 points by default
+	AllowOther bool          `json:"allow_other,omitempty"`                                                    // choice polls
+	MaxLength  int           `json:"max_length,omitempty" binding:"omitempty,min=1"`                           // text polls and write-ins, 500 by default, word cloud polls, 50 by default
+	TopTerms   int           `json:"top_terms,omitempty" binding:"omitempty,min=1"`                            // word cloud polls, 20 by default
+	Range      *NumericRange `json:"range,omitempty"`                                                          // numeric polls, 0-100 with step 1 by default
+	Columns    []string      `json:"columns,omitempty" binding:"omitempty,max=10,dive,required,min=1,max=100"` // matrix polls, five point Likert scale by default
+	Options    []string      `json:"options" binding:"omitempty,max=10,dive,required,min=1,max=100"`
+
+	ResultsVisibility ResultsVisibility `json:"results_visibility,omitempty" binding:"omitempty,oneof=always after_vote after_close creator"`
+	Schedule
+	Password   string `json:"password,omitempty" binding:"omitempty,min=4,max=72"` // voting and results require it
+	InviteOnly bool   `json:"invite_only,omitempty"`                               // voting requires invite tokens generated by admin
+
+	Webhooks []CreateWebhookRequest `json:"webhooks,omitempty" binding:"omitempty,max=5,dive"` // receive poll.created and later events
+
+	NotifyEmail     string `json:"notify_email,omitempty" binding:"omitempty,email,max=254"` // emailed results summary when poll closes
+	NotifyThreshold int64  `json:"notify_threshold,omitempty" binding:"omitempty,min=1"`     // also emailed once poll reaches this many votes
 }
 
 type CreatePollResponse struct {
 	PollID     string `json:"poll_id"`
 	VoteURL    string `json:"vote_url"`
 	ResultsURL string `json:"results_url"`
+	AdminToken string `json:"admin_token"` // shown once, required by admin endpoints
+
+	Webhooks []Webhook `json:"webhooks,omitempty"` // registered webhooks with secrets, shown once
 }
 
+// VoteRequest carries a ballot, the filled field depends on poll type
 type VoteRequest struct {
-	OptionIndices []int `json:"option_indices" binding:"required,min=1,dive,min=0"`
+	OptionIndices []int       `json:"option_indices,omitempty" binding:"omitempty,min=1,dive,min=0"` // choice polls
+	Ranking       []int       `json:"ranking,omitempty" binding:"omitempty,min=1,dive,min=0"`        // ranked polls, most preferred first
+	Scores        map[int]int `json:"scores,omitempty"`                                              // score polls, option index -> score
+	Points        map[int]int `json:"points,omitempty"`                                              // budget polls, option index -> points
+	Text          string      `json:"text,omitempty" binding:"max=20000"`                            // text and word cloud polls, or "Other" write-in of choice polls
+	Number        *float64    `json:"number,omitempty"`                                              // numeric polls
+	Rating        *int        `json:"rating,omitempty"`                                              // nps polls, 0-10
+	Matrix        map[int]int `json:"matrix,omitempty"`                                              // matrix polls, row index -> column index
 }
 
 type VoteResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message,omitempty"`
+	Success    bool         `json:"success"`
+	Message    string       `json:"message,omitempty"`
+	VoterToken string       `json:"voter_token,omitempty"` // identifies voter when results are visible after voting
+	Receipt    *VoteReceipt `json:"receipt,omitempty"`     // proof that ballot was counted
 }
 
 type PollResults struct {
 	Poll  Poll           `json:"poll"`
-	Votes map[string]int `json:"votes"` // option ->  count
+	Votes map[string]int `json:"votes"` // option ->  count (first preferences for ranked polls, points for budget polls)
 	Total int            `json:"total"`
+
+	TextAnswers int `json:"text_answers,omitempty"` // text answers and "Other" write-ins
+	Breakdown
+}
+
+// Breakdown holds type specific results of poll or survey question
+type Breakdown struct {
+	Runoff  *RunoffResults        `json:"runoff,omitempty"`
+	Schulze *SchulzeResults       `json:"schulze,omitempty"`
+	Scores  map[string]ScoreStats `json:"scores,omitempty"` // option -> score statistics
+	Numeric *NumericStats         `json:"numeric,omitempty"`
+	NPS     *NPSResults           `json:"nps,omitempty"`
+	Matrix  map[string]MatrixRow  `json:"matrix,omitempty"` // row -> column statistics
+	Words   *WordCloudResults     `json:"words,omitempty"`
+}
+
+// ScoreStats describes scores given to one option of score poll
+type ScoreStats struct {
+	Count        int         `json:"count"`
+	Sum          int         `json:"sum"`
+	Mean         float64     `json:"mean"`
+	Median       float64     `json:"median"`
+	Distribution map[int]int `json:"distribution"` // score -> count
+}
+
+// NumericStats describes numbers given in numeric poll
+type NumericStats struct {
+	Count       int                `json:"count"`
+	Mean        float64            `json:"mean"`
+	Median      float64            `json:"median"`
+	Percentiles map[string]float64 `json:"percentiles"` // "p10", "p25", "p75", "p90" -> number
+	Histogram   []HistogramBucket  `json:"histogram"`
+}
+
+// MatrixRow describes columns picked for one row of matrix poll
+type MatrixRow struct {
+	Count        int            `json:"count"`
+	Mean         float64        `json:"mean"`         // columns scored 1 to number of columns
+	Distribution map[string]int `json:"distribution"` // column -> count
+}
+
+// Answers returns number of answers for question types without per-option counters
+func (b *Breakdown) Answers() (int, bool) {
+	switch {
+	case b.Numeric != nil:
+		return b.Numeric.Count, true
+	case b.Words != nil:
+		return b.Words.Answers, true
+	}
+	return 0, false
+}
+
+// WordCloudResults are most frequent terms of word cloud poll
+type WordCloudResults struct {
+	Answers  int         `json:"answers"`
+	Distinct int         `json:"distinct"` // number of different terms
+	Terms    []TermCount `json:"terms"`    // most frequent first
+}
+
+// TermCount is number of answers normalized to the term
+type TermCount struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// NPSResults is Net Promoter Score of nps poll, promoters rate 9-10, passives 7-8 and detractors 0-6
+type NPSResults struct {
+	Responses  int                 `json:"responses"`
+	Promoters  int                 `json:"promoters"`
+	Passives   int                 `json:"passives"`
+	Detractors int                 `json:"detractors"`
+	Score      float64             `json:"score"` // percentage of promoters minus percentage of detractors, -100 to 100
+	Confidence *ConfidenceInterval `json:"confidence,omitempty"`
+}
+
+// ConfidenceInterval is range that contains true value with given confidence level
+type ConfidenceInterval struct {
+	Level float64 `json:"level"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// HistogramBucket counts numbers within inclusive range of steps
+type HistogramBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// RunoffResults is instant-runoff tabulation of ranked ballots
+type RunoffResults struct {
+	Ballots int           `json:"ballots"`
+	Rounds  []RunoffRound `json:"rounds"`
+	Winner  string        `json:"winner,omitempty"`
+}
+
+// RunoffRound is one counting round of instant-runoff
+type RunoffRound struct {
+	Round      int            `json:"round"`
+	Tallies    map[string]int `json:"tallies"`   // continuing option -> count
+	Exhausted  int            `json:"exhausted"` // ballots without continuing options
+	Eliminated string         `json:"eliminated,omitempty"`
+}
+
+// SchulzeResults is Schulze method tabulation of ranked ballots.
+// Matrices are indexed by option position in the poll.
+type SchulzeResults struct {
+	Ballots        int        `json:"ballots"`
+	Pairwise       [][]int    `json:"pairwise"`        // [i][j] ballots preferring i over j
+	StrongestPaths [][]int    `json:"strongest_paths"` // [i][j] strength of the strongest path from i to j
+	Ranking        [][]string `json:"ranking"`         // final ordering, options in one group are tied
+	Winner         string     `json:"winner,omitempty"`
+}
+
+// TextAnswer is stored free-text answer or "Other" write-in
+type TextAnswer struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TextAnswersQuery is pagination of text answers listing
+type TextAnswersQuery struct {
+	Offset int `form:"offset" binding:"min=0"`
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// TextAnswersPage is a page of text answers in the order they were submitted
+type TextAnswersPage struct {
+	Items  []TextAnswer `json:"items"`
+	Total  int          `json:"total"`
+	Offset int          `json:"offset"`
+	Limit  int          `json:"limit"`
 }
 
 type ErrorResponse struct {
@@ -0,0 +1,95 @@
+package model
+
+// QuizScore is score of one survey submission
+type QuizScore struct {
+	Score    int   `json:"score"`
+	MaxScore int   `json:"max_score"`
+	Correct  []int `json:"correct"` // indices of correctly answered questions
+}
+
+// QuizResults are scores of all survey submissions
+type QuizResults struct {
+	MaxScore     int         `json:"max_score"`
+	Mean         float64     `json:"mean"`
+	Distribution map[int]int `json:"distribution"` // score -> submissions
+}
+
+// QuestionQuiz tells how often graded question was answered correctly
+type QuestionQuiz struct {
+	Answered int     `json:"answered"`
+	Correct  int     `json:"correct"`
+	Rate     float64 `json:"rate"` // correct share of answers, from 0 to 1
+}
+
+// Graded reports whether question has correct options
+func (q *Question) Graded() bool {
+	return len(q.Correct) > 0
+}
+
+// IsCorrect reports whether answer chose exactly the correct options of graded question
+func (q *Question) IsCorrect(a *Answer) bool {
+	if !q.Graded() || a == nil {
+		return false
+	}
+
+	chosen := make(map[int]bool, len(a.OptionIndices))
+	for _, option := range a.OptionIndices {
+		chosen[option] = true
+	}
+	if len(chosen) != len(q.Correct) {
+		return false
+	}
+	for _, option := range q.Correct {
+		if !chosen[option] {
+			return false
+		}
+	}
+	return true
+}
+
+// Graded reports whether survey is a quiz, that is any of its questions has correct options
+func (s *Survey) Graded() bool {
+	for n := range s.Questions {
+		if s.Questions[n].Graded() {
+			return true
+		}
+	}
+	return false
+}
+
+// Grade scores submission of quiz, it returns nil for surveys without graded questions
+func (s *Survey) Grade(answers []Answer) *QuizScore {
+	if !s.Graded() {
+		return nil
+	}
+
+	byQuestion := make(map[int]*Answer, len(answers))
+	for i := range answers {
+		byQuestion[answers[i].Question] = &answers[i]
+	}
+
+	score := &QuizScore{Correct: []int{}}
+	for n := range s.Questions {
+		question := &s.Questions[n]
+		if !question.Graded() {
+			continue
+		}
+
+		score.MaxScore += question.Points
+		if question.IsCorrect(byQuestion[n]) {
+			score.Score += question.Points
+			score.Correct = append(score.Correct, n)
+		}
+	}
+	return score
+}
+
+// HideCorrectAnswers removes correct options from questions, points stay visible
+func (s *Survey) HideCorrectAnswers() {
+	questions := make([]Question, len(s.Questions))
+	copy(questions, s.Questions)
+	for n := range questions {
+		questions[n].Correct = nil
+	}
+	s.Questions = questions
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// VoteReceipt proves that ballot was counted without revealing it.
+// Signature covers poll ID, ballot hash and timestamp.
+type VoteReceipt struct {
+	ID         string    `json:"id"`
+	PollID     string    `json:"poll_id"`
+	BallotHash string    `json:"ballot_hash"` // hex SHA-256 of nonce and ballot
+	Timestamp  time.Time `json:"timestamp"`
+	Signature  string    `json:"signature"`       // base64 Ed25519 signature
+	Nonce      string    `json:"nonce,omitempty"` // returned to voter once, never stored
+}
+
+// ReceiptStatus is stored receipt with result of its signature check
+type ReceiptStatus struct {
+	VoteReceipt
+	Counted bool `json:"counted"` // ballot is in the tally
+	Valid   bool `json:"valid"`   // signature matches server key
+}
+
+// ReceiptKey is public key verifying vote receipts
+type ReceiptKey struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"` // base64
+}
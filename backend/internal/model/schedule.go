@@ -0,0 +1,93 @@
+package model
+
+import "time"
+
+// PollStatus tells whether poll or survey accepts answers
+type PollStatus string
+
+const (
+	// PollStatusScheduled is status before opening time
+	PollStatusScheduled PollStatus = "scheduled"
+
+	// PollStatusOpen is status while answers are accepted
+	PollStatusOpen PollStatus = "open"
+
+	// PollStatusClosed is status after closing time, results are kept until expiry
+	PollStatusClosed PollStatus = "closed"
+)
+
+// Schedule is optional voting window of poll or survey, separate from storage expiry
+type Schedule struct {
+	OpensAt  *time.Time `json:"opens_at,omitempty"`
+	ClosesAt *time.Time `json:"closes_at,omitempty"`
+}
+
+// status returns status at the moment, expired polls are closed regardless of schedule
+func (s *Schedule) status(now, expiresAt time.Time) PollStatus {
+	if !now.Before(expiresAt) || (s.ClosesAt != nil && !now.Before(*s.ClosesAt)) {
+		return PollStatusClosed
+	}
+	if s.OpensAt != nil && now.Before(*s.OpensAt) {
+		return PollStatusScheduled
+	}
+	return PollStatusOpen
+}
+
+// Status returns poll status at the moment
+func (p *Poll) Status(now time.Time) PollStatus {
+	return p.Schedule.status(now, p.ExpiresAt)
+}
+
+// Status returns survey status at the moment
+func (s *Survey) Status(now time.Time) PollStatus {
+	return s.Schedule.status(now, s.ExpiresAt)
+}
+
+// Closed reports whether poll no longer accepts votes
+func (p *Poll) Closed(now time.Time) bool {
+	return p.Status(now) == PollStatusClosed
+}
+
+// Closed reports whether survey no longer accepts answers
+func (s *Survey) Closed(now time.Time) bool {
+	return s.Status(now) == PollStatusClosed
+}
+
+// ExtendPollRequest moves poll expiry further, results and votes are kept until the new expiry
+type ExtendPollRequest struct {
+	ExpiresAt time.Time `json:"expires_at" binding:"required"`
+}
+
+// PollDetails is poll with its status and countdown to the next status change
+type PollDetails struct {
+	Poll
+	Status   PollStatus `json:"status"`
+	OpensIn  *int64     `json:"opens_in,omitempty"`  // seconds until opening, scheduled polls only
+	ClosesIn *int64     `json:"closes_in,omitempty"` // seconds until closing or expiry, open polls only
+}
+
+// NewPollDetails returns details of poll at the moment
+func NewPollDetails(poll *Poll, now time.Time) *PollDetails {
+	details := &PollDetails{
+		Poll:   *poll,
+		Status: poll.Status(now),
+	}
+
+	switch details.Status {
+	case PollStatusScheduled:
+		details.OpensIn = secondsUntil(now, *poll.OpensAt)
+	case PollStatusOpen:
+		closesAt := poll.ExpiresAt
+		if poll.ClosesAt != nil && poll.ClosesAt.Before(closesAt) {
+			closesAt = *poll.ClosesAt
+		}
+		details.ClosesIn = secondsUntil(now, closesAt)
+	}
+	return details
+}
+
+// secondsUntil returns whole seconds from now to t, rounded up
+func secondsUntil(now, t time.Time) *int64 {
+	seconds := int64((t.Sub(now) + time.Second - 1) / time.Second)
+	return &seconds
+}
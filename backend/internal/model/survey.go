@@ -0,0 +1,242 @@
+package model
+
+import (
+	"time"
+)
+
+// Question is a single question of survey, it is answered the same way as poll of its type
+type Question struct {
+	Title      string        `json:"title" binding:"required,min=1,max=200"`
+	Type       PollType      `json:"type,omitempty" binding:"omitempty,oneof=choice ranked score budget text numeric nps matrix wordcloud"`
+	Tabulation Tabulation    `json:"tabulation,omitempty" binding:"omitempty,oneof=irv schulze"`
+	Scale      *ScoreScale   `json:"scale,omitempty"`
+	Budget     *PointBudget  `json:"budget,omitempty"`
+	AllowOther bool          `json:"allow_other,omitempty"`
+	MaxLength  int           `json:"max_length,omitempty" binding:"omitempty,min=1"`
+	TopTerms   int           `json:"top_terms,omitempty" binding:"omitempty,min=1"`
+	Range      *NumericRange `json:"range,omitempty"`
+	Columns    []string      `json:"columns,omitempty" binding:"omitempty,max=10,dive,required,min=1,max=100"`
+	Options    []string      `json:"options" binding:"omitempty,max=10,dive,required,min=1,max=100"`
+	Required   bool          `json:"required,omitempty"`
+	Correct    []int         `json:"correct,omitempty" binding:"omitempty,max=10,dive,min=0"` // choice questions of quiz, hidden until survey closes
+	Points     int           `json:"points,omitempty" binding:"omitempty,min=1"`              // points for correct answer, 1 by default
+	ShowIf     []Condition   `json:"show_if,omitempty" binding:"omitempty,max=10,dive"`
+}
+
+// Condition shows question only if earlier choice question was answered with any of the options
+type Condition struct {
+	Question int   `json:"question" binding:"min=0"`
+	Options  []int `json:"options" binding:"required,min=1,dive,min=0"`
+}
+
+// BranchEdge links question to the question it shows, when answered with any of the options
+type BranchEdge struct {
+	From    int   `json:"from"`
+	To      int   `json:"to"`
+	Options []int `json:"options"`
+}
+
+// VotingType returns question type, choice by default
+func (q *Question) VotingType() PollType {
+	if q.Type == "" {
+		return PollTypeChoice
+	}
+	return q.Type
+}
+
+// Survey is ordered list of questions answered in one submission
+type Survey struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Questions []Question `json:"questions"`
+
+	ResultsVisibility ResultsVisibility `json:"results_visibility,omitempty"`
+	Schedule
+
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	AdminTokenHash string `json:"-"` // stored apart from survey info, never exposed
+}
+
+// SurveyDefinition is survey with its branching graph for rendering
+type SurveyDefinition struct {
+	Survey
+	Branching []BranchEdge `json:"branching,omitempty"`
+}
+
+// Visibility returns results visibility of survey, results are always visible by default
+func (s *Survey) Visibility() ResultsVisibility {
+	if s.ResultsVisibility == "" {
+		return ResultsAlways
+	}
+	return s.ResultsVisibility
+}
+
+// Branching returns edges between questions declared by show_if conditions
+func (s *Survey) Branching() []BranchEdge {
+	var edges []BranchEdge
+	for n, question := range s.Questions {
+		for _, condition := range question.ShowIf {
+			edges = append(edges, BranchEdge{
+				From:    condition.Question,
+				To:      n,
+				Options: condition.Options,
+			})
+		}
+	}
+	return edges
+}
+
+// Reachable reports which questions are shown for the given answers by question index.
+// Question is shown when every its condition refers to a shown question answered with one of condition options.
+func (s *Survey) Reachable(answers map[int]*Answer) []bool {
+	reachable := make([]bool, len(s.Questions))
+	for n, question := range s.Questions {
+		reachable[n] = true
+		for _, condition := range question.ShowIf {
+			if condition.Question >= n || !reachable[condition.Question] || !answers[condition.Question].selects(condition.Options) {
+				reachable[n] = false
+				break
+			}
+		}
+	}
+	return reachable
+}
+
+// Question returns poll as a required question of one-question survey
+func (p *Poll) Question() Question {
+	return Question{
+		Title:      p.Title,
+		Type:       p.VotingType(),
+		Tabulation: p.Tabulation,
+		Scale:      p.Scale,
+		Budget:     p.Budget,
+		AllowOther: p.AllowOther,
+		MaxLength:  p.MaxLength,
+		TopTerms:   p.TopTerms,
+		Range:      p.Range,
+		Columns:    p.Columns,
+		Options:    p.Options,
+		Required:   true,
+	}
+}
+
+// Survey returns poll as one-question survey
+func (p *Poll) Survey() Survey {
+	return Survey{
+		ID:        p.ID,
+		Title:     p.Title,
+		Questions: []Question{p.Question()},
+
+		ResultsVisibility: p.ResultsVisibility,
+		Schedule:          p.Schedule,
+
+		CreatedAt: p.CreatedAt,
+		ExpiresAt: p.ExpiresAt,
+
+		AdminTokenHash: p.AdminTokenHash,
+	}
+}
+
+type CreateSurveyRequest struct {
+	Title     string     `json:"title" binding:"required,min=3,max=200"`
+	Questions []Question `json:"questions" binding:"required,min=1,max=50,dive"`
+
+	ResultsVisibility ResultsVisibility `json:"results_visibility,omitempty" binding:"omitempty,oneof=always after_vote after_close creator"`
+	Schedule
+}
+
+type CreateSurveyResponse struct {
+	SurveyID     string `json:"survey_id"`
+	SurveyURL    string `json:"survey_url"`
+	ResponsesURL string `json:"responses_url"`
+	ResultsURL   string `json:"results_url"`
+	AdminToken   string `json:"admin_token"` // shown once, required by admin endpoints
+}
+
+// Answer is an answer to one survey question, the filled field depends on question type
+type Answer struct {
+	Question      int         `json:"question" binding:"min=0"`
+	OptionIndices []int       `json:"option_indices,omitempty"`
+	Ranking       []int       `json:"ranking,omitempty"`
+	Scores        map[int]int `json:"scores,omitempty"`
+	Points        map[int]int `json:"points,omitempty"`
+	Text          string      `json:"text,omitempty" binding:"max=20000"`
+	Number        *float64    `json:"number,omitempty"`
+	Rating        *int        `json:"rating,omitempty"`
+	Matrix        map[int]int `json:"matrix,omitempty"`
+}
+
+// Answer returns vote as an answer to the only question of poll
+func (r *VoteRequest) Answer() Answer {
+	return Answer{
+		OptionIndices: r.OptionIndices,
+		Ranking:       r.Ranking,
+		Scores:        r.Scores,
+		Points:        r.Points,
+		Text:          r.Text,
+		Number:        r.Number,
+		Rating:        r.Rating,
+		Matrix:        r.Matrix,
+	}
+}
+
+// selects reports whether answer chose any of the options, nil answer selects nothing
+func (a *Answer) selects(options []int) bool {
+	if a == nil {
+		return false
+	}
+	for _, chosen := range a.OptionIndices {
+		for _, option := range options {
+			if chosen == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VoteRequest returns answer as a vote for poll
+func (a *Answer) VoteRequest() *VoteRequest {
+	return &VoteRequest{
+		OptionIndices: a.OptionIndices,
+		Ranking:       a.Ranking,
+		Scores:        a.Scores,
+		Points:        a.Points,
+		Text:          a.Text,
+		Number:        a.Number,
+		Rating:        a.Rating,
+		Matrix:        a.Matrix,
+	}
+}
+
+// SubmitSurveyRequest carries answers to all answered questions of survey
+type SubmitSurveyRequest struct {
+	Answers []Answer `json:"answers" binding:"required,min=1,dive"`
+}
+
+type SubmitSurveyResponse struct {
+	Success    bool       `json:"success"`
+	Message    string     `json:"message,omitempty"`
+	VoterToken string     `json:"voter_token,omitempty"`
+	Score      *QuizScore `json:"score,omitempty"` // quizzes only
+}
+
+type SurveyResults struct {
+	Survey    Survey            `json:"survey"`
+	Responses int               `json:"responses,omitempty"` // number of submissions, unknown for polls
+	Quiz      *QuizResults      `json:"quiz,omitempty"`      // quizzes only
+	Questions []QuestionResults `json:"questions"`
+}
+
+// QuestionResults are results of one survey question
+type QuestionResults struct {
+	Question int            `json:"question"`
+	Votes    map[string]int `json:"votes"`
+	Total    int            `json:"total"`
+
+	TextAnswers int           `json:"text_answers,omitempty"`
+	Quiz        *QuestionQuiz `json:"quiz,omitempty"` // graded questions only
+	Breakdown
+}
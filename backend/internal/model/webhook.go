@@ -0,0 +1,113 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// WebhookEventType names poll lifecycle event delivered to webhooks
+type WebhookEventType string
+
+const (
+	WebhookPollCreated WebhookEventType = "poll.created"
+	WebhookVoteCast    WebhookEventType = "vote.cast" // votes are batched
+	WebhookPollClosed  WebhookEventType = "poll.closed"
+	WebhookPollExpired WebhookEventType = "poll.expired"
+)
+
+// WebhookEventTypes lists every event type, webhook registered without events receives all of them
+var WebhookEventTypes = []WebhookEventType{WebhookPollCreated, WebhookVoteCast, WebhookPollClosed, WebhookPollExpired}
+
+// MaxWebhooks bounds webhooks registered for one poll
+const MaxWebhooks = 5
+
+// CreateWebhookRequest registers URL receiving poll events
+type CreateWebhookRequest struct {
+	URL    string             `json:"url" binding:"required,url,max=2048"`
+	Events []WebhookEventType `json:"events,omitempty" binding:"omitempty,max=4,dive,oneof=poll.created vote.cast poll.closed poll.expired"` // all events when empty
+}
+
+// Webhook receives poll events signed with its secret
+type Webhook struct {
+	ID        string             `json:"id"`
+	URL       string             `json:"url"`
+	Events    []WebhookEventType `json:"events"`
+	Secret    string             `json:"secret,omitempty"` // HMAC-SHA256 key, shown once on registration
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// Subscribed reports whether webhook receives events of type
+func (w *Webhook) Subscribed(event WebhookEventType) bool {
+	return slices.Contains(w.Events, event)
+}
+
+// WebhookEvent is body of webhook request
+type WebhookEvent struct {
+	ID        string           `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	PollID    string           `json:"poll_id"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      json.RawMessage  `json:"data,omitempty"`
+}
+
+// VoteCastData is data of vote.cast event
+type VoteCastData struct {
+	Votes   int64        `json:"votes"` // ballots cast since previous vote.cast event
+	Results *PollResults `json:"results"`
+}
+
+// PollExpiredData is data of poll.expired event
+type PollExpiredData struct {
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+// WebhookDelivery is queued request of event to webhook
+type WebhookDelivery struct {
+	ID        string       `json:"id"`
+	WebhookID string       `json:"webhook_id"`
+	Event     WebhookEvent `json:"event"`
+	Attempts  int          `json:"attempts"` // failed attempts so far
+}
+
+// WebhookDeliveryStatus is outcome of delivery attempt
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDelivered WebhookDeliveryStatus = "delivered"
+	WebhookRetrying  WebhookDeliveryStatus = "retrying"
+	WebhookDead      WebhookDeliveryStatus = "dead" // attempts are exhausted, delivery is moved to dead letters
+)
+
+// WebhookAttempt is entry of delivery log
+type WebhookAttempt struct {
+	DeliveryID string                `json:"delivery_id"`
+	WebhookID  string                `json:"webhook_id"`
+	EventID    string                `json:"event_id"`
+	EventType  WebhookEventType      `json:"event_type"`
+	Attempt    int                   `json:"attempt"`
+	Status     WebhookDeliveryStatus `json:"status"`
+	StatusCode int                   `json:"status_code,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	Timestamp  time.Time             `json:"timestamp"`
+}
+
+// WebhookDeadLetter is delivery given up after all attempts
+type WebhookDeadLetter struct {
+	WebhookDelivery
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// WebhookDeliveryLog shows recent delivery attempts and dead letters of poll webhooks, newest first
+type WebhookDeliveryLog struct {
+	Attempts    []WebhookAttempt    `json:"attempts"`
+	DeadLetters []WebhookDeadLetter `json:"dead_letters"`
+}
+
+// WebhookTrigger is scheduled poll event, it becomes deliveries to subscribed webhooks when due
+type WebhookTrigger struct {
+	PollID string
+	Type   WebhookEventType
+	DueAt  time.Time
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrAdminForbidden returns when admin token is missing or doesn't match poll or survey
+var ErrAdminForbidden = errors.New("invalid admin token")
+
+// hashToken returns hex SHA-256 of admin or voter token, only the hash is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkAdminToken compares token with stored hash in constant time.
+// Polls and surveys created before admin tokens have no hash and can't be administered.
+func checkAdminToken(storedHash, token string) error {
+	if storedHash == "" || token == "" {
+		return ErrAdminForbidden
+	}
+	if subtle.ConstantTimeCompare([]byte(storedHash), []byte(hashToken(token))) != 1 {
+		return ErrAdminForbidden
+	}
+	return nil
+}
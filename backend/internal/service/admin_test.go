@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAdminToken(t *testing.T) {
+	hash := hashToken("secret")
+
+	assert.NoError(t, checkAdminToken(hash, "secret"))
+	assert.ErrorIs(t, checkAdminToken(hash, "guess"), ErrAdminForbidden)
+	assert.ErrorIs(t, checkAdminToken(hash, ""), ErrAdminForbidden)
+	assert.ErrorIs(t, checkAdminToken("", ""), ErrAdminForbidden, "polls without token can't be administered")
+}
+
+func TestPollService_CreatePoll_AdminToken(t *testing.T) {
+	mockStorage := new(MockStorage)
+	var stored *model.Poll
+	mockStorage.On("CreatePoll", mock.Anything, mock.AnythingOfType("*model.Poll"), mock.AnythingOfType("time.Duration")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.Poll) }).
+		Return(nil)
+
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+	res, err := service.CreatePoll(context.Background(), &model.CreatePollRequest{
+		Title:   "Lunch?",
+		Options: []string{"Pizza", "Sushi"},
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, res.AdminToken)
+	assert.NotEqual(t, res.AdminToken, stored.AdminTokenHash, "only hash of token is stored")
+	assert.NoError(t, checkAdminToken(stored.AdminTokenHash, res.AdminToken))
+}
+
+func TestPollService_GetTextAnswers(t *testing.T) {
+	poll := &model.Poll{
+		ID:             "text1",
+		Title:          "Any feedback?",
+		Type:           model.PollTypeText,
+		MaxLength:      500,
+		AdminTokenHash: hashToken("secret"),
+	}
+	answers := []model.TextAnswer{{Text: "More demos"}, {Text: "Shorter breaks"}}
+
+	tests := []struct {
+		name          string
+		token         string
+		query         model.TextAnswersQuery
+		setupMock     func(*MockStorage)
+		expectedError error
+		expectedLimit int
+	}{
+		{
+			name:  "default page",
+			token: "secret",
+			setupMock: func(m *MockStorage) {
+				m.On("GetPoll", mock.Anything, "text1").Return(poll, nil)
+				m.On("GetTextAnswers", mock.Anything, "text1", 0, 20).Return(answers, 2, nil)
+			},
+			expectedLimit: 20,
+		},
+		{
+			name:  "explicit page",
+			token: "secret",
+			query: model.TextAnswersQuery{Offset: 40, Limit: 10},
+			setupMock: func(m *MockStorage) {
+				m.On("GetPoll", mock.Anything, "text1").Return(poll, nil)
+				m.On("GetTextAnswers", mock.Anything, "text1", 40, 10).Return([]model.TextAnswer{}, 2, nil)
+			},
+			expectedLimit: 10,
+		},
+		{
+			name:  "wrong token",
+			token: "guess",
+			setupMock: func(m *MockStorage) {
+				m.On("GetPoll", mock.Anything, "text1").Return(poll, nil)
+			},
+			expectedError: ErrAdminForbidden,
+		},
+		{
+			name:  "poll not found",
+			token: "secret",
+			setupMock: func(m *MockStorage) {
+				m.On("GetPoll", mock.Anything, "text1").Return(nil, storage.ErrPollNotFound)
+			},
+			expectedError: storage.ErrPollNotFound,
+		},
+		{
+			name:  "storage error",
+			token: "secret",
+			setupMock: func(m *MockStorage) {
+				m.On("GetPoll", mock.Anything, "text1").Return(poll, nil)
+				m.On("GetTextAnswers", mock.Anything, "text1", 0, 20).Return(nil, 0, errors.New("storage error"))
+			},
+			expectedError: errors.New("storage error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			tt.setupMock(mockStorage)
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			page, err := service.GetTextAnswers(context.Background(), "text1", tt.token, &tt.query)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				if errors.Is(tt.expectedError, ErrAdminForbidden) || errors.Is(tt.expectedError, storage.ErrPollNotFound) {
+					assert.ErrorIs(t, err, tt.expectedError)
+				}
+				assert.Nil(t, page)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 2, page.Total)
+				assert.Equal(t, tt.query.Offset, page.Offset)
+				assert.Equal(t, tt.expectedLimit, page.Limit)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_GetSurveyTextAnswers(t *testing.T) {
+	survey := newTestSurvey()
+	survey.AdminTokenHash = hashToken("secret")
+
+	t.Run("survey question", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetSurvey", mock.Anything, "survey1").Return(survey, nil)
+		mockStorage.On("GetSurveyTextAnswers", mock.Anything, "survey1", 2, 0, 20).
+			Return([]model.TextAnswer{{Text: "Yoga"}}, 1, nil)
+
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+		page, err := service.GetSurveyTextAnswers(context.Background(), "survey1", 2, "secret", &model.TextAnswersQuery{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Yoga", page.Items[0].Text)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("question out of range", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetSurvey", mock.Anything, "survey1").Return(survey, nil)
+
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+		_, err := service.GetSurveyTextAnswers(context.Background(), "survey1", 3, "secret", &model.TextAnswersQuery{})
+
+		assert.ErrorIs(t, err, storage.ErrInvalidQuestion)
+	})
+
+	t.Run("poll as one-question survey", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetSurvey", mock.Anything, "poll1").Return(nil, storage.ErrSurveyNotFound)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&model.Poll{
+			ID:             "poll1",
+			Title:          "Favorite editor?",
+			AllowOther:     true,
+			MaxLength:      40,
+			Options:        []string{"Vim", "Emacs"},
+			AdminTokenHash: hashToken("secret"),
+		}, nil)
+		mockStorage.On("GetTextAnswers", mock.Anything, "poll1", 0, 20).
+			Return([]model.TextAnswer{{Text: "Helix"}}, 1, nil)
+
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+		page, err := service.GetSurveyTextAnswers(context.Background(), "poll1", 0, "secret", &model.TextAnswersQuery{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, page.Total)
+		mockStorage.AssertExpectations(t)
+	})
+}
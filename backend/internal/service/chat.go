@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// chatUsage explains poll slash command
+const chatUsage = "Usage: `/poll \"Question?\" Option1 Option2 ...` - quote question and options containing spaces, 2-10 options"
+
+// Chat polls limits, same as poll creation request validation
+const (
+	minChatTitleLength  = 3
+	maxChatTitleLength  = 200
+	maxChatOptions      = 10
+	maxChatOptionLength = 100
+)
+
+// chatBarWidth is length of results bar in poll message
+const chatBarWidth = 10
+
+// ErrInvalidChatAction returns when button click doesn't refer to poll or its option
+var ErrInvalidChatAction = errors.New("invalid chat action")
+
+// errChatHelp returns when command asks for usage
+var errChatHelp = errors.New("help requested")
+
+// chatQuotes maps opening quotes to closing ones, chat clients may replace straight quotes with typographic
+var chatQuotes = map[rune]rune{
+	'"':  '"',
+	'\'': '\'',
+	'“':  '”',
+	'„':  '“',
+	'‘':  '’',
+	'«':  '»',
+}
+
+// ChatCommand creates poll from slash command text and returns message with vote buttons for channel.
+// Admin token is posted to response URL of command for its sender only. Invalid command is answered with usage.
+func (s *PollService) ChatCommand(ctx context.Context, cmd *model.ChatCommand) (*model.ChatMessage, error) {
+	title, options, err := parseChatCommand(cmd.Text)
+	if err != nil {
+		if err == errChatHelp {
+			return chatEphemeral(chatUsage), nil
+		}
+		return chatEphemeral("Can't create poll: " + err.Error() + "\n" + chatUsage), nil
+	}
+
+	created, err := s.CreatePoll(ctx, &model.CreatePollRequest{Title: title, Options: options})
+	if err != nil {
+		if errors.Is(err, ErrInvalidPollConfig) {
+			return chatEphemeral("Can't create poll: " + err.Error() + "\n" + chatUsage), nil
+		}
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "chat poll created",
+		slog.String("poll_id", created.PollID),
+		slog.String("team_id", cmd.TeamID),
+		slog.String("channel_id", cmd.ChannelID),
+	)
+
+	if cmd.ResponseURL != "" {
+		if err := s.chat.post(ctx, cmd.ResponseURL, chatAdminMessage(created)); err != nil {
+			s.logger.WarnContext(ctx, "failed to send admin token to chat user",
+				slog.String("poll_id", created.PollID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	message, err := s.chatPollMessage(ctx, created.PollID)
+	if err != nil {
+		return nil, err
+	}
+	message.ResponseType = model.ChatInChannel
+	return message, nil
+}
+
+// ChatAction handles button click on poll message: votes once per chat user or refreshes counts.
+// Returned message replaces poll message, unless vote is rejected and the reason is shown to voter only.
+// It is posted to response URL of action too, as chat ignores response to button click.
+func (s *PollService) ChatAction(ctx context.Context, action *model.ChatAction) (*model.ChatMessage, error) {
+	if len(action.Actions) == 0 || action.User.ID == "" {
+		return nil, ErrInvalidChatAction
+	}
+	item := action.Actions[0]
+
+	var pollID string
+	switch item.ActionID {
+	case model.ChatActionVote:
+		id, option, err := parseChatVote(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		rejected, err := s.chatVote(ctx, id, option, chatVoter(s.config.Chat, action.Team.ID, action.User.ID))
+		if err != nil {
+			return nil, err
+		}
+		if rejected != "" {
+			message := chatEphemeral(rejected)
+			s.respondChat(ctx, action.ResponseURL, "", message)
+			return message, nil
+		}
+		pollID = id
+	case model.ChatActionRefresh:
+		pollID = item.Value
+	default:
+		return nil, ErrInvalidChatAction
+	}
+
+	message, err := s.chatPollMessage(ctx, pollID)
+	if err != nil {
+		if text, ok := chatErrorText(err); ok {
+			message := chatEphemeral(text)
+			s.respondChat(ctx, action.ResponseURL, "", message)
+			return message, nil
+		}
+		return nil, err
+	}
+	message.ReplaceOriginal = true
+	s.respondChat(ctx, action.ResponseURL, pollID, message)
+	return message, nil
+}
+
+// respondChat posts message to response URL of action. Response URL of updated poll message is kept,
+// so live counts of poll are posted to it, pollID is empty for messages shown to the user only.
+func (s *PollService) respondChat(ctx context.Context, responseURL, pollID string, message *model.ChatMessage) {
+	if responseURL == "" {
+		return
+	}
+	if err := s.chat.post(ctx, responseURL, message); err != nil {
+		s.logger.WarnContext(ctx, "failed to update chat message",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	if pollID == "" {
+		return
+	}
+	if err := s.storage.SetChatResponseURL(ctx, pollID, responseURL, chatResponseURLTTL); err != nil {
+		s.logger.ErrorContext(ctx, "failed to save chat response url",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// pushChatUpdate posts live counts of poll to its chat message. Every worker collects the same votes and posts
+// at about the same time, claim for half of update interval lets one of them post without delaying the next update.
+func (s *PollService) pushChatUpdate(ctx context.Context, pollID string) error {
+	responseURL, err := s.storage.ClaimChatUpdate(ctx, pollID, s.config.Chat.UpdateInterval/2)
+	if err != nil || responseURL == "" {
+		return err
+	}
+
+	message, err := s.chatPollMessage(ctx, pollID)
+	if err != nil {
+		return err
+	}
+	message.ReplaceOriginal = true
+	return s.chat.post(ctx, responseURL, message)
+}
+
+// chatVote votes for poll option, it returns reason shown to voter when vote is rejected.
+// Chat user votes once, storage rejects repeated vote of the same voter atomically.
+func (s *PollService) chatVote(ctx context.Context, pollID string, option int, voter string) (string, error) {
+	if _, err := s.Vote(ctx, pollID, &model.VoteRequest{OptionIndices: []int{option}}, voter, &model.PollAccess{}); err != nil {
+		if text, ok := chatErrorText(err); ok {
+			return text, nil
+		}
+		return "", err
+	}
+	return "", nil
+}
+
+// chatPollMessage renders poll message, counts are shown only when everyone can see results as the message is shared
+func (s *PollService) chatPollMessage(ctx context.Context, pollID string) (*model.ChatMessage, error) {
+	results, err := s.ViewResults(ctx, pollID, &model.ResultsViewer{})
+	if err == nil {
+		return renderChatPoll(&results.Poll, results, time.Now()), nil
+	}
+	if err != ErrResultsHidden {
+		return nil, err
+	}
+
+	poll, err := s.storage.GetPoll(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	return renderChatPoll(poll, nil, time.Now()), nil
+}
+
+// renderChatPoll returns message with option buttons while poll is open, results are nil when hidden
+func renderChatPoll(poll *model.Poll, results *model.PollResults, now time.Time) *model.ChatMessage {
+	status := poll.Status(now)
+	blocks := []model.ChatBlock{chatSection("*" + chatEscape(poll.Title) + "*")}
+
+	for i, option := range poll.Options {
+		text := "*" + chatEscape(option) + "*"
+		if results != nil {
+			votes := results.Votes[option]
+			text += fmt.Sprintf("\n`%s` %d", chatBar(votes, results.Total), votes)
+		}
+		block := chatSection(text)
+		if status == model.PollStatusOpen {
+			block.Accessory = &model.ChatElement{
+				Type:     "button",
+				ActionID: model.ChatActionVote,
+				Text:     &model.ChatText{Type: "plain_text", Text: "Vote"},
+				Value:    fmt.Sprintf("%s:%d", poll.ID, i),
+			}
+		}
+		blocks = append(blocks, block)
+	}
+
+	summary := "Results are hidden"
+	if results != nil {
+		summary = fmt.Sprintf("%d votes", results.Total)
+	}
+	switch status {
+	case model.PollStatusScheduled:
+		summary += " · voting is not open yet"
+	case model.PollStatusClosed:
+		summary += " · voting is closed"
+	}
+	blocks = append(blocks,
+		chatSection(fmt.Sprintf("%s · poll `%s`", summary, poll.ID)),
+		model.ChatBlock{
+			Type: "actions",
+			Elements: []model.ChatElement{{
+				Type:     "button",
+				ActionID: model.ChatActionRefresh,
+				Text:     &model.ChatText{Type: "plain_text", Text: "Refresh"},
+				Value:    poll.ID,
+			}},
+		},
+	)
+
+	return &model.ChatMessage{
+		Text:   fmt.Sprintf("%s (%s)", poll.Title, summary),
+		Blocks: blocks,
+	}
+}
+
+// chatAdminMessage returns admin token of poll created from chat, it is shown to the command sender only
+func chatAdminMessage(created *model.CreatePollResponse) *model.ChatMessage {
+	return chatEphemeral(fmt.Sprintf("Poll `%s` is created. Its admin token is shown only to you: `%s`\n"+
+		"Send it in X-Admin-Token header to close, extend or delete the poll. Results: %s",
+		created.PollID, created.AdminToken, created.ResultsURL))
+}
+
+// chatSection returns section block with mrkdwn text
+func chatSection(text string) model.ChatBlock {
+	return model.ChatBlock{Type: "section", Text: &model.ChatText{Type: "mrkdwn", Text: text}}
+}
+
+// chatEphemeral returns message shown to the user who sent command or clicked button only
+func chatEphemeral(text string) *model.ChatMessage {
+	return &model.ChatMessage{ResponseType: model.ChatEphemeral, Text: text}
+}
+
+// chatEscape escapes characters having special meaning in mrkdwn
+func chatEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// chatBar returns bar filled in proportion of votes to total
+func chatBar(votes, total int) string {
+	filled := 0
+	if total > 0 {
+		filled = int(math.Round(float64(votes) * chatBarWidth / float64(total)))
+	}
+	filled = min(max(filled, 0), chatBarWidth)
+	return strings.Repeat("█", filled) + strings.Repeat("░", chatBarWidth-filled)
+}
+
+// chatVoter returns voter token of chat user, user IDs are unique within team. Token is keyed by chat secrets,
+// so API clients can't derive it from IDs to vote or see results as chat user.
+func chatVoter(cfg config.ChatConfig, teamID, userID string) string {
+	mac := hmac.New(sha256.New, []byte(cfg.SigningSecret+"\x00"+cfg.VerificationToken))
+	mac.Write([]byte(teamID + ":" + userID))
+	return "chat:" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// chatErrorText returns reason of rejected chat action shown to the user, ok is false for unexpected errors
+func chatErrorText(err error) (string, bool) {
+	switch {
+	case errors.Is(err, storage.ErrPollNotFound):
+		return "This poll has expired", true
+	case errors.Is(err, storage.ErrAlreadyVoted):
+		return "You have already voted in this poll", true
+	case errors.Is(err, storage.ErrClosed):
+		return "Voting is closed", true
+	case errors.Is(err, storage.ErrNotOpen):
+		return "Voting is not open yet", true
+	case errors.Is(err, storage.ErrInvalidOption), errors.Is(err, storage.ErrVoteTypeMismatch):
+		return "This poll can't be answered with buttons", true
+	case errors.Is(err, ErrPasswordRequired), errors.Is(err, ErrWrongPassword), errors.Is(err, ErrTooManyAttempts),
+		errors.Is(err, storage.ErrInvalidInvite), errors.Is(err, storage.ErrInviteUsed):
+		return "This poll can't be voted from chat", true
+	}
+	return "", false
+}
+
+// parseChatVote parses "<poll_id>:<option index>" value of vote button
+func parseChatVote(value string) (string, int, error) {
+	pollID, index, ok := strings.Cut(value, ":")
+	if !ok || pollID == "" {
+		return "", 0, ErrInvalidChatAction
+	}
+	option, err := strconv.Atoi(index)
+	if err != nil || option < 0 {
+		return "", 0, ErrInvalidChatAction
+	}
+	return pollID, option, nil
+}
+
+// parseChatCommand splits command text into poll title and options, quoted words are kept together
+func parseChatCommand(text string) (string, []string, error) {
+	words, err := splitChatWords(text)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(words) == 0 || (len(words) == 1 && strings.EqualFold(words[0], "help")) {
+		return "", nil, errChatHelp
+	}
+
+	title, options := words[0], words[1:]
+	if n := utf8.RuneCountInString(title); n < minChatTitleLength || n > maxChatTitleLength {
+		return "", nil, fmt.Errorf("question must be %d-%d characters long", minChatTitleLength, maxChatTitleLength)
+	}
+	if len(options) < minOptions || len(options) > maxChatOptions {
+		return "", nil, fmt.Errorf("poll needs %d-%d options, got %d", minOptions, maxChatOptions, len(options))
+	}
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		if n := utf8.RuneCountInString(option); n < 1 || n > maxChatOptionLength {
+			return "", nil, fmt.Errorf("options must be 1-%d characters long", maxChatOptionLength)
+		}
+		if seen[option] {
+			return "", nil, fmt.Errorf("option %q is given twice", option)
+		}
+		seen[option] = true
+	}
+	return title, options, nil
+}
+
+// splitChatWords splits text by spaces, quote at the start of word groups words until closing quote
+func splitChatWords(text string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var closing rune
+
+	for _, r := range text {
+		switch {
+		case closing != 0:
+			if r == closing {
+				words = append(words, strings.TrimSpace(word.String()))
+				word.Reset()
+				closing = 0
+				continue
+			}
+			word.WriteRune(r)
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case !inWord && chatQuotes[r] != 0:
+			closing = chatQuotes[r]
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if closing != 0 {
+		return nil, errors.New("closing quote is missing")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
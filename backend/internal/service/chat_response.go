@@ -0,0 +1,95 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/lib/safehttp"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// chatResponseTimeout bounds posting message to response URL of chat request
+const chatResponseTimeout = 5 * time.Second
+
+// chatResponseURLTTL is how long chat accepts messages posted to response URL of action
+const chatResponseURLTTL = 30 * time.Minute
+
+// errChatUnverified returns when response URL comes from chat requests which are not authenticated
+var errChatUnverified = errors.New("chat requests are not verified")
+
+// errChatResponseHost returns when response URL is not on configured chat host
+var errChatResponseHost = errors.New("response url host is not allowed")
+
+// chatResponder posts messages to response URLs of chat requests. Requests which are not verified may be forged,
+// so nothing is posted for them, and verified ones are posted only to configured chat hosts.
+type chatResponder struct {
+	config config.ChatConfig
+	client *http.Client
+}
+
+func newChatResponder(cfg config.ChatConfig) *chatResponder {
+	return &chatResponder{
+		config: cfg,
+		client: safehttp.NewClient(chatResponseTimeout, cfg.AllowPrivate),
+	}
+}
+
+// allowed checks that messages may be posted to response URL
+func (r *chatResponder) allowed(responseURL string) error {
+	if !r.config.Verified() {
+		return errChatUnverified
+	}
+	target, err := url.Parse(responseURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return errChatResponseHost
+	}
+	host := strings.ToLower(target.Hostname())
+	if !slices.ContainsFunc(r.config.ResponseHosts, func(allowed string) bool {
+		return strings.EqualFold(strings.TrimSpace(allowed), host)
+	}) {
+		return fmt.Errorf("%w: %s", errChatResponseHost, host)
+	}
+	if !r.config.AllowPrivate {
+		return safehttp.CheckHost(target)
+	}
+	return nil
+}
+
+// post sends message to response URL of chat request
+func (r *chatResponder) post(ctx context.Context, responseURL string, message *model.ChatMessage) error {
+	if err := r.allowed(responseURL); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
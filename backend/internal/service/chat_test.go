@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// chatStub is chat server receiving messages posted to response URLs
+type chatStub struct {
+	*httptest.Server
+	messages chan model.ChatMessage
+}
+
+func newChatStub(t *testing.T) *chatStub {
+	stub := &chatStub{messages: make(chan model.ChatMessage, 1)}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var message model.ChatMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		stub.messages <- message
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+// config returns service config of verified chat posting to the stub
+func (s *chatStub) config() *config.Config {
+	cfg := newTestConfig()
+	cfg.Chat = config.ChatConfig{
+		SigningSecret:  "secret",
+		ResponseHosts:  []string{"127.0.0.1"},
+		AllowPrivate:   true,
+		UpdateInterval: 10 * time.Second,
+	}
+	return cfg
+}
+
+func (s *chatStub) receive(t *testing.T) model.ChatMessage {
+	select {
+	case message := <-s.messages:
+		return message
+	default:
+		t.Fatal("message wasn't posted")
+		return model.ChatMessage{}
+	}
+}
+
+func TestChatResponder(t *testing.T) {
+	stub := newChatStub(t)
+	message := &model.ChatMessage{ReplaceOriginal: true, Text: "Lunch? (3 votes)"}
+
+	t.Run("posted to allowed host", func(t *testing.T) {
+		responder := newChatResponder(stub.config().Chat)
+		require.NoError(t, responder.post(context.Background(), stub.URL, message))
+		assert.Equal(t, *message, stub.receive(t))
+
+		assert.EqualError(t, responder.post(context.Background(), stub.URL+"/missing", message), "unexpected status 404")
+	})
+
+	t.Run("nothing is posted for unverified chat", func(t *testing.T) {
+		cfg := stub.config().Chat
+		cfg.SigningSecret = ""
+		assert.ErrorIs(t, newChatResponder(cfg).post(context.Background(), stub.URL, message), errChatUnverified)
+	})
+
+	t.Run("other hosts and private addresses are refused", func(t *testing.T) {
+		cfg := stub.config().Chat
+		responder := newChatResponder(cfg)
+		assert.ErrorIs(t, responder.allowed("https://evil.example.com/hook"), errChatResponseHost)
+		assert.ErrorIs(t, responder.allowed("file:///etc/passwd"), errChatResponseHost)
+
+		cfg.AllowPrivate = false
+		assert.Error(t, newChatResponder(cfg).post(context.Background(), stub.URL, message))
+
+		cfg.ResponseHosts = []string{"hooks.slack.com"}
+		assert.NoError(t, newChatResponder(cfg).allowed("https://hooks.slack.com/actions/T1/1/abc"))
+	})
+}
+
+func TestPollService_PushChatUpdate(t *testing.T) {
+	stub := newChatStub(t)
+	poll := &model.Poll{ID: "poll123", Title: "Lunch?", Options: []string{"Pizza", "Sushi"}, ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("counts are posted to chat message", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("ClaimChatUpdate", mock.Anything, "poll123", 5*time.Second).Return(stub.URL, nil)
+		mockStorage.On("GetPoll", mock.Anything, "poll123").Return(poll, nil)
+		mockStorage.On("GetResults", mock.Anything, "poll123").
+			Return(&model.PollResults{Poll: *poll, Votes: map[string]int{"Pizza": 2}, Total: 2}, nil)
+		service := NewPollService(mockStorage, stub.config(), newTestLogger())
+
+		require.NoError(t, service.pushChatUpdate(context.Background(), "poll123"))
+
+		message := stub.receive(t)
+		assert.True(t, message.ReplaceOriginal)
+		assert.Contains(t, message.Text, "2 votes")
+	})
+
+	t.Run("nothing is posted without claimed message", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("ClaimChatUpdate", mock.Anything, "poll123", 5*time.Second).Return("", nil)
+		service := NewPollService(mockStorage, stub.config(), newTestLogger())
+
+		require.NoError(t, service.pushChatUpdate(context.Background(), "poll123"))
+		mockStorage.AssertNotCalled(t, "GetResults", mock.Anything, mock.Anything)
+	})
+}
+
+func TestParseChatCommand(t *testing.T) {
+	tests := []struct {
+		name            string
+		text            string
+		expectedTitle   string
+		expectedOptions []string
+		expectedError   string
+	}{
+		{
+			name:            "quoted title",
+			text:            `"Lunch today?" Pizza Sushi Tacos`,
+			expectedTitle:   "Lunch today?",
+			expectedOptions: []string{"Pizza", "Sushi", "Tacos"},
+		},
+		{
+			name:            "typographic quotes and quoted options",
+			text:            `“Where to go?”  ‘Old Town’ «Main street»`,
+			expectedTitle:   "Where to go?",
+			expectedOptions: []string{"Old Town", "Main street"},
+		},
+		{
+			name:            "apostrophe inside word",
+			text:            `Lunch? Don't Pizza`,
+			expectedTitle:   "Lunch?",
+			expectedOptions: []string{"Don't", "Pizza"},
+		},
+		{
+			name:          "help",
+			text:          " help ",
+			expectedError: errChatHelp.Error(),
+		},
+		{
+			name:          "empty",
+			text:          "",
+			expectedError: errChatHelp.Error(),
+		},
+		{
+			name:          "missing closing quote",
+			text:          `"Lunch? Pizza Sushi`,
+			expectedError: "closing quote is missing",
+		},
+		{
+			name:          "one option",
+			text:          `"Lunch?" Pizza`,
+			expectedError: "poll needs 2-10 options, got 1",
+		},
+		{
+			name:          "short title",
+			text:          `Hi A B`,
+			expectedError: "question must be 3-200 characters long",
+		},
+		{
+			name:          "duplicate option",
+			text:          `"Lunch?" Pizza Pizza`,
+			expectedError: `option "Pizza" is given twice`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, options, err := parseChatCommand(tt.text)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTitle, title)
+			assert.Equal(t, tt.expectedOptions, options)
+		})
+	}
+}
+
+func TestChatBar(t *testing.T) {
+	assert.Equal(t, "░░░░░░░░░░", chatBar(0, 0))
+	assert.Equal(t, "███░░░░░░░", chatBar(1, 3))
+	assert.Equal(t, "██████████", chatBar(4, 4))
+}
+
+func TestChatVoter(t *testing.T) {
+	cfg := config.ChatConfig{SigningSecret: "secret"}
+	voter := chatVoter(cfg, "T1", "U1")
+
+	assert.Equal(t, voter, chatVoter(cfg, "T1", "U1"))
+	assert.NotContains(t, voter, "U1", "user ID can't be turned into voter token without secret")
+	assert.NotEqual(t, voter, chatVoter(cfg, "T1", "U2"))
+	assert.NotEqual(t, voter, chatVoter(config.ChatConfig{SigningSecret: "other"}, "T1", "U1"))
+}
+
+func TestPollService_ChatCommand(t *testing.T) {
+	t.Run("creates poll with vote buttons", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		// Poll saved by CreatePoll is read back to render message
+		created := &model.Poll{}
+		results := &model.PollResults{Votes: map[string]int{}}
+		mockStorage.On("CreatePoll", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				*created = *args.Get(1).(*model.Poll)
+				results.Poll = *created
+			}).
+			Return(nil)
+		mockStorage.On("GetPoll", mock.Anything, mock.Anything).Return(created, nil)
+		mockStorage.On("GetResults", mock.Anything, mock.Anything).Return(results, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		message, err := service.ChatCommand(context.Background(), &model.ChatCommand{UserID: "U1", Text: `"Lunch?" Pizza Sushi`})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Lunch?", created.Title)
+		assert.Equal(t, []string{"Pizza", "Sushi"}, created.Options)
+		assert.Equal(t, model.ChatInChannel, message.ResponseType)
+		require.Len(t, message.Blocks, 5)
+		assert.Equal(t, "*Lunch?*", message.Blocks[0].Text.Text)
+		require.NotNil(t, message.Blocks[2].Accessory)
+		assert.Equal(t, model.ChatActionVote, message.Blocks[2].Accessory.ActionID)
+		assert.Equal(t, created.ID+":1", message.Blocks[2].Accessory.Value)
+		assert.Equal(t, model.ChatActionRefresh, message.Blocks[4].Elements[0].ActionID)
+	})
+
+	t.Run("admin token is sent to the sender only", func(t *testing.T) {
+		stub := newChatStub(t)
+		mockStorage := new(MockStorage)
+		created := &model.Poll{}
+		mockStorage.On("CreatePoll", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { *created = *args.Get(1).(*model.Poll) }).
+			Return(nil)
+		mockStorage.On("GetPoll", mock.Anything, mock.Anything).Return(created, nil)
+		mockStorage.On("GetResults", mock.Anything, mock.Anything).Return(&model.PollResults{Votes: map[string]int{}}, nil)
+		service := NewPollService(mockStorage, stub.config(), newTestLogger())
+
+		message, err := service.ChatCommand(context.Background(), &model.ChatCommand{
+			UserID:      "U1",
+			Text:        `"Lunch?" Pizza Sushi`,
+			ResponseURL: stub.URL + "/commands/1",
+		})
+
+		require.NoError(t, err)
+		private := stub.receive(t)
+		assert.Equal(t, model.ChatEphemeral, private.ResponseType)
+		assert.Contains(t, private.Text, created.ID)
+		token, _, ok := strings.Cut(strings.SplitN(private.Text, "`", 4)[3], "`")
+		require.True(t, ok)
+		assert.Equal(t, created.AdminTokenHash, hashToken(token), "admin token of created poll")
+		assert.NotContains(t, message.Text, token, "channel doesn't see admin token")
+	})
+
+	t.Run("invalid command is answered with usage", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		message, err := service.ChatCommand(context.Background(), &model.ChatCommand{UserID: "U1", Text: `"Lunch?" Pizza`})
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ChatEphemeral, message.ResponseType)
+		assert.Contains(t, message.Text, "poll needs 2-10 options")
+		assert.Contains(t, message.Text, chatUsage)
+		mockStorage.AssertNotCalled(t, "CreatePoll", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPollService_ChatAction(t *testing.T) {
+	poll := &model.Poll{ID: "poll123", Title: "Lunch?", Options: []string{"Pizza", "Sushi"}, ExpiresAt: time.Now().Add(time.Hour)}
+	voter := voterHash(chatVoter(newTestConfig().Chat, "T1", "U1"))
+	vote := func(value string) *model.ChatAction {
+		return &model.ChatAction{
+			Type:    "block_actions",
+			Team:    model.ChatTeam{ID: "T1"},
+			User:    model.ChatUser{ID: "U1"},
+			Actions: []model.ChatActionItem{{ActionID: model.ChatActionVote, Value: value}},
+		}
+	}
+
+	t.Run("vote updates message with counts", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll123").Return(poll, nil)
+		mockStorage.On("Vote", mock.Anything, "poll123", &model.VoteRequest{OptionIndices: []int{1}}, voter, mock.Anything).Return(nil)
+		mockStorage.On("GetResults", mock.Anything, "poll123").
+			Return(&model.PollResults{Poll: *poll, Votes: map[string]int{"Pizza": 1, "Sushi": 3}, Total: 4}, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		message, err := service.ChatAction(context.Background(), vote("poll123:1"))
+
+		require.NoError(t, err)
+		assert.True(t, message.ReplaceOriginal)
+		assert.Equal(t, "*Sushi*\n`████████░░` 3", message.Blocks[2].Text.Text)
+		require.NotNil(t, message.Blocks[2].Accessory)
+		assert.Contains(t, message.Blocks[3].Text.Text, "4 votes")
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("second vote of user is rejected", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll123").Return(poll, nil)
+		mockStorage.On("Vote", mock.Anything, "poll123", mock.Anything, voter, mock.Anything).Return(storage.ErrAlreadyVoted)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		message, err := service.ChatAction(context.Background(), vote("poll123:0"))
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ChatEphemeral, message.ResponseType)
+		assert.Equal(t, "You have already voted in this poll", message.Text)
+	})
+
+	t.Run("updated message is posted to response url and kept for live counts", func(t *testing.T) {
+		stub := newChatStub(t)
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll123").Return(poll, nil)
+		mockStorage.On("Vote", mock.Anything, "poll123", mock.Anything, voterHash(chatVoter(stub.config().Chat, "T1", "U1")), mock.Anything).Return(nil)
+		mockStorage.On("GetResults", mock.Anything, "poll123").
+			Return(&model.PollResults{Poll: *poll, Votes: map[string]int{"Pizza": 1}, Total: 1}, nil)
+		mockStorage.On("SetChatResponseURL", mock.Anything, "poll123", stub.URL+"/actions/1", chatResponseURLTTL).Return(nil)
+		service := NewPollService(mockStorage, stub.config(), newTestLogger())
+
+		action := vote("poll123:0")
+		action.ResponseURL = stub.URL + "/actions/1"
+		message, err := service.ChatAction(context.Background(), action)
+
+		require.NoError(t, err)
+		assert.Equal(t, *message, stub.receive(t))
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("vote in closed poll is rejected", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll123").Return(poll, nil)
+		mockStorage.On("Vote", mock.Anything, "poll123", mock.Anything, voter, mock.Anything).Return(storage.ErrClosed)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		message, err := service.ChatAction(context.Background(), vote("poll123:0"))
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ChatEphemeral, message.ResponseType)
+		assert.Equal(t, "Voting is closed", message.Text)
+	})
+
+	t.Run("refresh hides counts and buttons of closed poll with hidden results", func(t *testing.T) {
+		closed := *poll
+		closesAt := time.Now().Add(-time.Minute)
+		closed.ClosesAt = &closesAt
+		closed.ResultsVisibility = model.ResultsCreator
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll123").Return(&closed, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		message, err := service.ChatAction(context.Background(), &model.ChatAction{
+			User:    model.ChatUser{ID: "U1"},
+			Actions: []model.ChatActionItem{{ActionID: model.ChatActionRefresh, Value: "poll123"}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "*Pizza*", message.Blocks[1].Text.Text)
+		assert.Nil(t, message.Blocks[1].Accessory)
+		assert.Contains(t, message.Blocks[3].Text.Text, "Results are hidden · voting is closed")
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		service := NewPollService(new(MockStorage), newTestConfig(), newTestLogger())
+
+		_, err := service.ChatAction(context.Background(), vote("poll123"))
+
+		assert.ErrorIs(t, err, ErrInvalidChatAction)
+	})
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+)
+
+// chatPollInterval is how often worker looks for due updates of chat messages
+const chatPollInterval = time.Second
+
+// ChatWorker posts live counts to chat messages of polls, so votes cast through API or other clients show up in chat.
+// Votes are collected for update interval, every worker gets them, storage lets one of them post each update.
+type ChatWorker struct {
+	service *PollService
+	storage storage.Storage
+	config  config.ChatConfig
+	logger  *slog.Logger
+
+	// pending holds when update of poll message is due
+	pending map[string]time.Time
+}
+
+func NewChatWorker(service *PollService, storage storage.Storage, cfg config.ChatConfig, logger *slog.Logger) *ChatWorker {
+	return &ChatWorker{
+		service: service,
+		storage: storage,
+		config:  cfg,
+		logger:  logger,
+		pending: make(map[string]time.Time),
+	}
+}
+
+// Run posts updates of chat messages until ctx is done
+func (w *ChatWorker) Run(ctx context.Context) error {
+	votes, err := w.storage.WatchVotes(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(chatPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case pollID, ok := <-votes:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return errors.New("poll updates subscription closed")
+			}
+			if _, ok := w.pending[pollID]; !ok {
+				w.pending[pollID] = time.Now().Add(w.config.UpdateInterval)
+			}
+		case <-ticker.C:
+			w.processUpdates(ctx, time.Now())
+		}
+	}
+}
+
+// processUpdates posts due updates of chat messages
+func (w *ChatWorker) processUpdates(ctx context.Context, now time.Time) {
+	for pollID, due := range w.pending {
+		if due.After(now) {
+			continue
+		}
+		delete(w.pending, pollID)
+
+		if err := w.service.pushChatUpdate(ctx, pollID); err != nil && !errors.Is(err, storage.ErrPollNotFound) {
+			w.logger.WarnContext(ctx, "failed to update chat message",
+				slog.String("poll_id", pollID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// retryPolicy is exponential backoff of failed items of due queue, they are given up after max attempts
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// backoff returns delay before next attempt after failed attempts
+func (p retryPolicy) backoff(attempts int) time.Duration {
+	return retryBackoff(p.baseDelay, p.maxDelay, attempts)
+}
+
+// next returns when item with failed attempts is due again, ok is false once it is given up
+func (p retryPolicy) next(attempts int, now time.Time) (time.Time, bool) {
+	if attempts >= p.maxAttempts {
+		return time.Time{}, false
+	}
+	return now.Add(p.backoff(attempts)), true
+}
+
+// retryBackoff returns delay doubling from base with every failed attempt, capped at max
+func retryBackoff(base, max time.Duration, attempts int) time.Duration {
+	if attempts < 1 {
+		return base
+	}
+	delay := base << (attempts - 1)
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// dueRunner handles items claimed from due queue in background, at most limit of them at once,
+// so slow receivers don't hold the worker loop and can't take more than limit slots
+type dueRunner struct {
+	slots    chan struct{}
+	inflight sync.WaitGroup
+}
+
+func newDueRunner(limit int) *dueRunner {
+	return &dueRunner{slots: make(chan struct{}, limit)}
+}
+
+// free returns how many items can be started right away, worker claims no more than that
+func (r *dueRunner) free() int {
+	return cap(r.slots) - len(r.slots)
+}
+
+// wait returns once all started items are handled
+func (r *dueRunner) wait() {
+	r.inflight.Wait()
+}
+
+// runDue starts handling of items claimed from due queue and returns without waiting for them.
+// Claimed items are finished even when ctx is canceled, otherwise they would be lost.
+func runDue[T any](ctx context.Context, r *dueRunner, items []T, handle func(context.Context, T)) {
+	ctx = context.WithoutCancel(ctx)
+	for _, item := range items {
+		r.slots <- struct{}{}
+		r.inflight.Add(1)
+		go func() {
+			defer r.inflight.Done()
+			defer func() { <-r.slots }()
+			handle(ctx, item)
+		}()
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, retryBackoff(30*time.Second, time.Hour, 1))
+	assert.Equal(t, 2*time.Minute, retryBackoff(30*time.Second, time.Hour, 3))
+	assert.Equal(t, time.Hour, retryBackoff(30*time.Second, time.Hour, 100))
+}
+
+func TestRunDue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	runner := newDueRunner(2)
+	release := make(chan struct{})
+	var handled atomic.Int32
+	runDue(ctx, runner, []int{1, 2}, func(ctx context.Context, item int) {
+		assert.NoError(t, ctx.Err(), "claimed items are finished on shutdown")
+		<-release
+		handled.Add(int32(item))
+	})
+
+	assert.Equal(t, 0, runner.free(), "returns while items are handled")
+	close(release)
+	runner.wait()
+
+	assert.Equal(t, int32(3), handled.Load())
+	assert.Equal(t, 2, runner.free())
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// emailSummary is data of notification templates
+type emailSummary struct {
+	Reason     model.EmailReason
+	Title      string
+	Expiring   bool  // poll without closing time is reported before expiry
+	Ballots    int64 // ballots cast, known for threshold notification only
+	Total      int
+	Options    []emailOptionResult
+	ResultsURL string
+	SentAt     time.Time
+}
+
+// emailOptionResult is option count in results summary
+type emailOptionResult struct {
+	Option  string
+	Count   int
+	Percent int
+}
+
+var emailSubjectTemplate = texttemplate.Must(texttemplate.New("subject").Parse(
+	`{{if eq .Reason "vote.threshold"}}Poll "{{.Title}}" reached {{.Ballots}} votes` +
+		`{{else if .Expiring}}Final results of poll "{{.Title}}"` +
+		`{{else}}Poll "{{.Title}}" is closed{{end}}`))
+
+var emailTextTemplate = texttemplate.Must(texttemplate.New("text").Parse(`
+{{- if eq .Reason "vote.threshold"}}Your poll "{{.Title}}" has reached {{.Ballots}} votes.
+{{- else if .Expiring}}Your poll "{{.Title}}" expires in a minute, these are its final results.
+{{- else}}Voting in your poll "{{.Title}}" is closed.{{end}}
+
+Results, {{.Total}} votes:
+{{range .Options}}
+  {{.Option}}: {{.Count}} ({{.Percent}}%)
+{{- end}}
+
+Full results: {{.ResultsURL}}
+
+You get this email because it was given as notify_email when the poll was created.
+`))
+
+var emailHTMLTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #1f2937; max-width: 560px;">
+  <h2 style="margin-bottom: 4px;">{{.Title}}</h2>
+  <p style="margin-top: 0; color: #4b5563;">
+    {{- if eq .Reason "vote.threshold"}}Your poll has reached {{.Ballots}} votes.
+    {{- else if .Expiring}}Your poll expires in a minute, these are its final results.
+    {{- else}}Voting is closed.{{end -}}
+  </p>
+  {{- if .Options}}
+  <table style="width: 100%; border-collapse: collapse;">
+    {{- range .Options}}
+    <tr>
+      <td style="padding: 6px 8px 6px 0;">{{.Option}}</td>
+      <td style="padding: 6px 0; width: 50%;">
+        <div style="background: #e5e7eb; border-radius: 4px;">
+          <div style="background: #6366f1; height: 10px; border-radius: 4px; width: {{.Percent}}%;"></div>
+        </div>
+      </td>
+      <td style="padding: 6px 0 6px 8px; text-align: right; white-space: nowrap;">{{.Count}} ({{.Percent}}%)</td>
+    </tr>
+    {{- end}}
+  </table>
+  {{- end}}
+  <p><strong>{{.Total}}</strong> votes in total. <a href="{{.ResultsURL}}">Full results</a></p>
+  <p style="font-size: 12px; color: #9ca3af;">You get this email because it was given as notify_email when the poll was created.</p>
+</body>
+</html>
+`))
+
+// validateEmailNotification checks email notification settings of poll creation request
+func validateEmailNotification(req *model.CreatePollRequest, cfg config.SMTPConfig) error {
+	if req.NotifyEmail == "" {
+		if req.NotifyThreshold > 0 {
+			return fmt.Errorf("%w: notify_threshold requires notify_email", ErrInvalidPollConfig)
+		}
+		return nil
+	}
+	if !cfg.Enabled() {
+		return fmt.Errorf("%w: email notifications are not configured", ErrInvalidPollConfig)
+	}
+	// Summary lists option counts, other poll types have no such results
+	if req.Type != "" && req.Type != model.PollTypeChoice {
+		return fmt.Errorf("%w: notify_email is supported for choice polls only", ErrInvalidPollConfig)
+	}
+	return nil
+}
+
+// newEmailSummary returns template data of poll results
+func newEmailSummary(reason model.EmailReason, results *model.PollResults, ballots int64, baseURL string, now time.Time) *emailSummary {
+	summary := &emailSummary{
+		Reason:     reason,
+		Title:      results.Poll.Title,
+		Expiring:   results.Poll.ClosesAt == nil,
+		Ballots:    ballots,
+		Total:      results.Total,
+		ResultsURL: fmt.Sprintf("%s/api/v1/polls/%s/results", baseURL, results.Poll.ID),
+		SentAt:     now,
+	}
+	for _, option := range results.Poll.Options {
+		count := results.Votes[option]
+		percent := 0
+		if results.Total > 0 {
+			percent = count * 100 / results.Total
+		}
+		summary.Options = append(summary.Options, emailOptionResult{Option: option, Count: count, Percent: percent})
+	}
+	return summary
+}
+
+// renderEmail renders notification of poll creator with text and HTML bodies
+func renderEmail(to string, summary *emailSummary) (*model.Email, error) {
+	var subject, text, html bytes.Buffer
+	if err := emailSubjectTemplate.Execute(&subject, summary); err != nil {
+		return nil, fmt.Errorf("failed to render email subject: %w", err)
+	}
+	if err := emailTextTemplate.Execute(&text, summary); err != nil {
+		return nil, fmt.Errorf("failed to render email text: %w", err)
+	}
+	if err := emailHTMLTemplate.Execute(&html, summary); err != nil {
+		return nil, fmt.Errorf("failed to render email HTML: %w", err)
+	}
+
+	return &model.Email{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSMTPConfig() config.SMTPConfig {
+	return config.SMTPConfig{
+		Host:           "127.0.0.1",
+		Port:           1025,
+		From:           "Surway <noreply@surway.test>",
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		RetryBaseDelay: 30 * time.Second,
+		RetryMaxDelay:  time.Minute,
+		RateLimit:      2,
+		RateWindow:     24 * time.Hour,
+	}
+}
+
+func newTestResults() *model.PollResults {
+	closesAt := time.Now()
+	return &model.PollResults{
+		Poll: model.Poll{
+			ID:       "poll1",
+			Title:    "Lunch <today>",
+			Options:  []string{"Pizza", "Sushi"},
+			Schedule: model.Schedule{ClosesAt: &closesAt},
+		},
+		Votes: map[string]int{"Pizza": 3, "Sushi": 1},
+		Total: 4,
+	}
+}
+
+// stubMailer records sent emails and fails with err
+type stubMailer struct {
+	sent []model.Email
+	err  error
+}
+
+func (m *stubMailer) Send(ctx context.Context, email *model.Email) error {
+	m.sent = append(m.sent, *email)
+	return m.err
+}
+
+// smtpCatcher is local SMTP server accepting any message, like catchers used in development
+type smtpCatcher struct {
+	listener net.Listener
+	messages chan string
+}
+
+func newSMTPCatcher(t *testing.T) *smtpCatcher {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	catcher := &smtpCatcher{listener: listener, messages: make(chan string, 1)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+		reply("220 catcher ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250-catcher")
+				reply("250 8BITMIME")
+			case command == "DATA":
+				reply("354 end data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				catcher.messages <- data.String()
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return catcher
+}
+
+func (c *smtpCatcher) config() config.SMTPConfig {
+	cfg := newTestSMTPConfig()
+	cfg.Port = c.listener.Addr().(*net.TCPAddr).Port
+	return cfg
+}
+
+func TestValidateEmailNotification(t *testing.T) {
+	assert.NoError(t, validateEmailNotification(&model.CreatePollRequest{}, config.SMTPConfig{}))
+	assert.NoError(t, validateEmailNotification(&model.CreatePollRequest{NotifyEmail: "me@example.com", NotifyThreshold: 10}, newTestSMTPConfig()))
+
+	err := validateEmailNotification(&model.CreatePollRequest{NotifyEmail: "me@example.com"}, config.SMTPConfig{})
+	assert.ErrorIs(t, err, ErrInvalidPollConfig)
+	assert.Contains(t, err.Error(), "not configured")
+
+	err = validateEmailNotification(&model.CreatePollRequest{NotifyThreshold: 10}, newTestSMTPConfig())
+	assert.ErrorIs(t, err, ErrInvalidPollConfig)
+
+	err = validateEmailNotification(&model.CreatePollRequest{Type: model.PollTypeRanked, NotifyEmail: "me@example.com"}, newTestSMTPConfig())
+	assert.ErrorIs(t, err, ErrInvalidPollConfig)
+	assert.Contains(t, err.Error(), "choice polls only")
+	assert.NoError(t, validateEmailNotification(&model.CreatePollRequest{Type: model.PollTypeChoice, NotifyEmail: "me@example.com"}, newTestSMTPConfig()))
+}
+
+func TestRenderEmail(t *testing.T) {
+	t.Run("poll closed", func(t *testing.T) {
+		summary := newEmailSummary(model.EmailPollClosed, newTestResults(), 0, "http://localhost:8080", time.Now())
+		email, err := renderEmail("me@example.com", summary)
+		require.NoError(t, err)
+
+		assert.Equal(t, "me@example.com", email.To)
+		assert.Equal(t, `Poll "Lunch <today>" is closed`, email.Subject)
+		assert.Contains(t, email.Text, "Pizza: 3 (75%)")
+		assert.Contains(t, email.Text, "http://localhost:8080/api/v1/polls/poll1/results")
+		assert.Contains(t, email.HTML, "Lunch &lt;today&gt;", "title is escaped")
+		assert.Contains(t, email.HTML, "width: 25%")
+	})
+
+	t.Run("vote threshold", func(t *testing.T) {
+		summary := newEmailSummary(model.EmailVoteThreshold, newTestResults(), 4, "http://localhost:8080", time.Now())
+		email, err := renderEmail("me@example.com", summary)
+		require.NoError(t, err)
+		assert.Equal(t, `Poll "Lunch <today>" reached 4 votes`, email.Subject)
+		assert.Contains(t, email.Text, "has reached 4 votes")
+	})
+
+	t.Run("expiring poll", func(t *testing.T) {
+		results := newTestResults()
+		results.Poll.ClosesAt = nil
+		email, err := renderEmail("me@example.com", newEmailSummary(model.EmailPollClosed, results, 0, "", time.Now()))
+		require.NoError(t, err)
+		assert.Equal(t, `Final results of poll "Lunch <today>"`, email.Subject)
+	})
+}
+
+func TestBuildEmailMessage(t *testing.T) {
+	from := &mail.Address{Name: "Surway", Address: "noreply@surway.test"}
+	to := &mail.Address{Address: "me@example.com"}
+	email := &model.Email{Subject: "Опрос\r\nBcc: other@example.com", Text: "Итоги", HTML: "<p>Итоги</p>"}
+
+	data, err := buildEmailMessage(from, to, email, time.Now())
+	require.NoError(t, err)
+
+	message, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	assert.Equal(t, `"Surway" <noreply@surway.test>`, message.Header.Get("From"))
+	assert.Empty(t, message.Header.Get("Bcc"), "line break in subject doesn't start header")
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Опрос Bcc: other@example.com", subject)
+	assert.True(t, strings.HasSuffix(message.Header.Get("Message-ID"), "@surway.test>"))
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	var contents []string
+	for {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		contents = append(contents, part.Header.Get("Content-Type")+" "+string(content))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8 Итоги", "text/html; charset=utf-8 <p>Итоги</p>"}, contents)
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	catcher := newSMTPCatcher(t)
+	mailer, err := NewSMTPMailer(catcher.config())
+	require.NoError(t, err)
+
+	err = mailer.Send(context.Background(), &model.Email{To: "me@example.com", Subject: "Poll is closed", Text: "Results", HTML: "<p>Results</p>"})
+	require.NoError(t, err)
+
+	select {
+	case data := <-catcher.messages:
+		assert.Contains(t, data, "To: <me@example.com>")
+		assert.Contains(t, data, "Subject: Poll is closed")
+	case <-time.After(time.Second):
+		t.Fatal("message wasn't caught")
+	}
+
+	_, err = NewSMTPMailer(config.SMTPConfig{From: "not an address"})
+	assert.Error(t, err)
+}
+
+func TestPollService_CreatePollEmailNotification(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.SMTP = newTestSMTPConfig()
+
+	mockStorage := new(MockStorage)
+	mockStorage.On("CreatePoll", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockStorage.On("SetEmailNotification", mock.Anything, mock.Anything,
+		&model.EmailNotification{Email: "me@example.com", Threshold: 10}).Return(nil)
+	service := NewPollService(mockStorage, cfg, newTestLogger())
+
+	_, err := service.CreatePoll(context.Background(), &model.CreatePollRequest{
+		Title:           "Lunch",
+		Options:         []string{"Pizza", "Sushi"},
+		NotifyEmail:     "me@example.com",
+		NotifyThreshold: 10,
+	})
+	require.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestEmailWorker_Notify(t *testing.T) {
+	notification := &model.EmailNotification{Email: "me@example.com", Threshold: 4, ThresholdSent: true}
+
+	t.Run("queued", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("ClaimVoteThreshold", mock.Anything, "poll1").Return(notification, int64(4), nil)
+		mockStorage.On("GetResults", mock.Anything, "poll1").Return(newTestResults(), nil)
+		mockStorage.On("AddRecipientEmail", mock.Anything, "me@example.com", 24*time.Hour).Return(1, nil)
+		var queued []model.EmailDelivery
+		mockStorage.On("EnqueueEmails", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { queued = args.Get(1).([]model.EmailDelivery) }).Return(nil)
+		worker := NewEmailWorker(mockStorage, &stubMailer{}, newTestSMTPConfig(), "http://localhost:8080", newTestLogger())
+
+		worker.checkThreshold(context.Background(), "poll1")
+
+		require.Len(t, queued, 1)
+		assert.Equal(t, model.EmailVoteThreshold, queued[0].Reason)
+		assert.Equal(t, "me@example.com", queued[0].Email.To)
+		assert.Contains(t, queued[0].Email.Subject, "reached 4 votes")
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("dropped over recipient rate limit", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("ClaimVoteThreshold", mock.Anything, "poll1").Return(notification, int64(4), nil)
+		mockStorage.On("GetResults", mock.Anything, "poll1").Return(newTestResults(), nil)
+		mockStorage.On("AddRecipientEmail", mock.Anything, "me@example.com", 24*time.Hour).Return(3, nil)
+		worker := NewEmailWorker(mockStorage, &stubMailer{}, newTestSMTPConfig(), "http://localhost:8080", newTestLogger())
+
+		worker.checkThreshold(context.Background(), "poll1")
+
+		mockStorage.AssertNotCalled(t, "EnqueueEmails", mock.Anything, mock.Anything, mock.Anything)
+		mockStorage.AssertNotCalled(t, "ReleaseVoteThreshold", mock.Anything, mock.Anything)
+	})
+
+	t.Run("threshold released when email isn't queued", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("ClaimVoteThreshold", mock.Anything, "poll1").Return(notification, int64(4), nil)
+		mockStorage.On("GetResults", mock.Anything, "poll1").Return(newTestResults(), nil)
+		mockStorage.On("AddRecipientEmail", mock.Anything, "me@example.com", 24*time.Hour).Return(1, nil)
+		mockStorage.On("EnqueueEmails", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+		mockStorage.On("ReleaseVoteThreshold", mock.Anything, "poll1").Return(nil)
+		worker := NewEmailWorker(mockStorage, &stubMailer{}, newTestSMTPConfig(), "http://localhost:8080", newTestLogger())
+
+		worker.checkThreshold(context.Background(), "poll1")
+
+		mockStorage.AssertExpectations(t)
+	})
+}
+
+func TestEmailWorker_Send(t *testing.T) {
+	delivery := model.EmailDelivery{ID: "e1", PollID: "poll1", Reason: model.EmailPollClosed, Email: model.Email{To: "me@example.com"}}
+
+	t.Run("sent", func(t *testing.T) {
+		mailer := &stubMailer{}
+		worker := NewEmailWorker(new(MockStorage), mailer, newTestSMTPConfig(), "", newTestLogger())
+		worker.send(context.Background(), delivery)
+		assert.Len(t, mailer.sent, 1)
+	})
+
+	t.Run("failure is retried", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		before := time.Now()
+		mockStorage.On("EnqueueEmails", mock.Anything,
+			mock.MatchedBy(func(d []model.EmailDelivery) bool { return len(d) == 1 && d[0].Attempts == 2 }),
+			mock.MatchedBy(func(at time.Time) bool { return !at.Before(before.Add(time.Minute)) })).Return(nil)
+		worker := NewEmailWorker(mockStorage, &stubMailer{err: errors.New("451 try later")}, newTestSMTPConfig(), "", newTestLogger())
+
+		retried := delivery
+		retried.Attempts = 1
+		worker.send(context.Background(), retried)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("given up after max attempts", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		worker := NewEmailWorker(mockStorage, &stubMailer{err: errors.New("550 no such user")}, newTestSMTPConfig(), "", newTestLogger())
+
+		last := delivery
+		last.Attempts = 2
+		worker.send(context.Background(), last)
+		mockStorage.AssertNotCalled(t, "EnqueueEmails", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
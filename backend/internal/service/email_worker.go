@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/lib/random"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+)
+
+// emailPollInterval is how often worker looks for closed polls and due emails
+const emailPollInterval = time.Second
+
+// emailClaimLimit bounds polls claimed by one pass of worker and emails being sent at once
+const emailClaimLimit = 50
+
+// EmailWorker notifies poll creators by email in background. Emails are rendered when they are queued,
+// so retries don't depend on poll, and are claimed from storage, several workers share them without duplicates.
+type EmailWorker struct {
+	storage storage.Storage
+	mailer  Mailer
+	config  config.SMTPConfig
+	retries retryPolicy
+	baseURL string
+	logger  *slog.Logger
+}
+
+func NewEmailWorker(storage storage.Storage, mailer Mailer, cfg config.SMTPConfig, baseURL string, logger *slog.Logger) *EmailWorker {
+	return &EmailWorker{
+		storage: storage,
+		mailer:  mailer,
+		config:  cfg,
+		retries: retryPolicy{maxAttempts: cfg.MaxAttempts, baseDelay: cfg.RetryBaseDelay, maxDelay: cfg.RetryMaxDelay},
+		baseURL: baseURL,
+		logger:  logger,
+	}
+}
+
+// Run processes email notifications until ctx is done, emails in flight are sent before return
+func (w *EmailWorker) Run(ctx context.Context) error {
+	votes, err := w.storage.WatchVotes(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(emailPollInterval)
+	defer ticker.Stop()
+
+	runner := newDueRunner(emailClaimLimit)
+	defer runner.wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case pollID, ok := <-votes:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return errors.New("poll updates subscription closed")
+			}
+			w.checkThreshold(ctx, pollID)
+		case <-ticker.C:
+			w.processClosedPolls(ctx)
+			w.processEmails(ctx, runner)
+		}
+	}
+}
+
+// checkThreshold queues email of poll which has just reached vote threshold of its creator,
+// threshold is released when email isn't queued, so next vote claims it again
+func (w *EmailWorker) checkThreshold(ctx context.Context, pollID string) {
+	notification, ballots, err := w.storage.ClaimVoteThreshold(ctx, pollID)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to check vote threshold",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	if notification == nil {
+		return
+	}
+
+	if err := w.notify(ctx, pollID, model.EmailVoteThreshold, notification, ballots); err != nil {
+		w.logger.ErrorContext(ctx, "failed to queue email",
+			slog.String("poll_id", pollID),
+			slog.String("reason", string(model.EmailVoteThreshold)),
+			slog.String("error", err.Error()),
+		)
+		if err := w.storage.ReleaseVoteThreshold(ctx, pollID); err != nil {
+			w.logger.ErrorContext(ctx, "failed to release vote threshold",
+				slog.String("poll_id", pollID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// processClosedPolls queues emails of polls whose voting is closed
+func (w *EmailWorker) processClosedPolls(ctx context.Context) {
+	pollIDs, err := w.storage.ClaimEmailTriggers(ctx, time.Now(), emailClaimLimit)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to claim closed polls",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	for _, pollID := range pollIDs {
+		notification, err := w.storage.GetEmailNotification(ctx, pollID)
+		if err == nil && notification != nil {
+			err = w.notify(ctx, pollID, model.EmailPollClosed, notification, 0)
+		}
+		if err != nil && !errors.Is(err, storage.ErrPollNotFound) {
+			w.logger.ErrorContext(ctx, "failed to queue email",
+				slog.String("poll_id", pollID),
+				slog.String("reason", string(model.EmailPollClosed)),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// notify renders results summary of poll and queues it for creator, email is dropped once recipient is over rate limit
+func (w *EmailWorker) notify(ctx context.Context, pollID string, reason model.EmailReason, notification *model.EmailNotification, ballots int64) error {
+	results, err := w.storage.GetResults(ctx, pollID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	email, err := renderEmail(notification.Email, newEmailSummary(reason, results, ballots, w.baseURL, now))
+	if err != nil {
+		return err
+	}
+
+	if w.config.RateLimit > 0 {
+		emails, err := w.storage.AddRecipientEmail(ctx, notification.Email, w.config.RateWindow)
+		if err != nil {
+			return err
+		}
+		if emails > w.config.RateLimit {
+			w.logger.WarnContext(ctx, "email dropped, recipient is over rate limit",
+				slog.String("poll_id", pollID),
+				slog.String("reason", string(reason)),
+			)
+			return nil
+		}
+	}
+
+	delivery := model.EmailDelivery{
+		ID:     random.NewToken(),
+		PollID: pollID,
+		Reason: reason,
+		Email:  *email,
+	}
+	return w.storage.EnqueueEmails(ctx, []model.EmailDelivery{delivery}, now)
+}
+
+// processEmails starts sending of due emails in background, no more than runner has free slots for
+func (w *EmailWorker) processEmails(ctx context.Context, runner *dueRunner) {
+	free := runner.free()
+	if free == 0 {
+		return
+	}
+	deliveries, err := w.storage.ClaimEmails(ctx, time.Now(), free)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to claim emails",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	runDue(ctx, runner, deliveries, w.send)
+}
+
+// send delivers email, failed email is retried with exponential backoff and given up after max attempts
+func (w *EmailWorker) send(ctx context.Context, delivery model.EmailDelivery) {
+	err := w.mailer.Send(ctx, &delivery.Email)
+	if err == nil {
+		w.logger.InfoContext(ctx, "email sent",
+			slog.String("poll_id", delivery.PollID),
+			slog.String("reason", string(delivery.Reason)),
+		)
+		return
+	}
+
+	delivery.Attempts++
+	due, ok := w.retries.next(delivery.Attempts, time.Now())
+	if !ok {
+		w.logger.WarnContext(ctx, "email given up",
+			slog.String("poll_id", delivery.PollID),
+			slog.String("reason", string(delivery.Reason)),
+			slog.Int("attempts", delivery.Attempts),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	w.logger.WarnContext(ctx, "failed to send email",
+		slog.String("poll_id", delivery.PollID),
+		slog.String("reason", string(delivery.Reason)),
+		slog.Int("attempts", delivery.Attempts),
+		slog.String("error", err.Error()),
+	)
+	if err := w.storage.EnqueueEmails(ctx, []model.EmailDelivery{delivery}, due); err != nil {
+		w.logger.ErrorContext(ctx, "failed to retry email",
+			slog.String("poll_id", delivery.PollID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
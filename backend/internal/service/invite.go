@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/lib/random"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"log/slog"
+	"strings"
+)
+
+// CreateInvites generates single-use voter tokens of invite-only poll for its admin.
+// Only token hashes are stored, emails are returned with their tokens and forgotten.
+func (s *PollService) CreateInvites(ctx context.Context, pollID, adminToken string, req *model.CreateInvitesRequest) (*model.CreateInvitesResponse, error) {
+	poll, err := s.adminPoll(ctx, pollID, adminToken)
+	if err != nil {
+		return nil, err
+	}
+	if !poll.InviteOnly {
+		return nil, fmt.Errorf("%w: poll is not invite-only", ErrInvalidPollConfig)
+	}
+
+	invites, err := newInvites(req)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(invites))
+	for i, invite := range invites {
+		hashes[i] = hashToken(invite.Token)
+	}
+
+	usage, err := s.storage.AddInvites(ctx, pollID, hashes)
+	if err != nil {
+		if err == storage.ErrPollNotFound {
+			return nil, err
+		}
+		s.logger.ErrorContext(ctx, "failed to add invites",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to add invites: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "invites created",
+		slog.String("poll_id", pollID),
+		slog.Int("invites_count", len(invites)),
+	)
+
+	return &model.CreateInvitesResponse{
+		Invites:     invites,
+		InviteUsage: *usage,
+	}, nil
+}
+
+// newInvites generates invite per email or given number of invites
+func newInvites(req *model.CreateInvitesRequest) ([]model.Invite, error) {
+	if (req.Count == 0) == (len(req.Emails) == 0) {
+		return nil, fmt.Errorf("%w: either count or emails is required", ErrInvalidPollConfig)
+	}
+
+	if req.Count > 0 {
+		invites := make([]model.Invite, req.Count)
+		for i := range invites {
+			invites[i].Token = random.NewToken()
+		}
+		return invites, nil
+	}
+
+	invites := make([]model.Invite, 0, len(req.Emails))
+	seen := make(map[string]bool, len(req.Emails))
+	for _, email := range req.Emails {
+		key := strings.ToLower(email)
+		if seen[key] {
+			return nil, fmt.Errorf("%w: email %s is listed more than once", ErrInvalidPollConfig, email)
+		}
+		seen[key] = true
+		invites = append(invites, model.Invite{Email: email, Token: random.NewToken()})
+	}
+	return invites, nil
+}
+
+// GetInviteUsage returns invite counters of invite-only poll to its admin
+func (s *PollService) GetInviteUsage(ctx context.Context, pollID, adminToken string) (*model.InviteUsage, error) {
+	if _, err := s.adminPoll(ctx, pollID, adminToken); err != nil {
+		return nil, err
+	}
+
+	usage, err := s.storage.GetInviteUsage(ctx, pollID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get invite usage",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to get invite usage: %w", err)
+	}
+	return usage, nil
+}
+
+// adminPoll loads poll and checks admin token
+func (s *PollService) adminPoll(ctx context.Context, pollID, adminToken string) (*model.Poll, error) {
+	poll, err := s.storage.GetPoll(ctx, pollID)
+	if err != nil {
+		if err == storage.ErrPollNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	if err := checkAdminToken(poll.AdminTokenHash, adminToken); err != nil {
+		s.logger.WarnContext(ctx, "poll administration requested without valid admin token",
+			slog.String("poll_id", pollID),
+		)
+		return nil, err
+	}
+	return poll, nil
+}
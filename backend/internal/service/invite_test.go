@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollService_CreateInvites(t *testing.T) {
+	inviteOnly := &model.Poll{ID: "poll1", InviteOnly: true, AdminTokenHash: hashToken("secret")}
+
+	tests := []struct {
+		name        string
+		poll        *model.Poll
+		token       string
+		request     *model.CreateInvitesRequest
+		wantInvites int
+		wantErr     error
+	}{
+		{name: "count", poll: inviteOnly, token: "secret", request: &model.CreateInvitesRequest{Count: 3}, wantInvites: 3},
+		{
+			name:        "emails",
+			poll:        inviteOnly,
+			token:       "secret",
+			request:     &model.CreateInvitesRequest{Emails: []string{"ann@example.com", "bob@example.com"}},
+			wantInvites: 2,
+		},
+		{
+			name:    "duplicate email",
+			poll:    inviteOnly,
+			token:   "secret",
+			request: &model.CreateInvitesRequest{Emails: []string{"ann@example.com", "Ann@example.com"}},
+			wantErr: ErrInvalidPollConfig,
+		},
+		{
+			name:    "count and emails",
+			poll:    inviteOnly,
+			token:   "secret",
+			request: &model.CreateInvitesRequest{Count: 1, Emails: []string{"ann@example.com"}},
+			wantErr: ErrInvalidPollConfig,
+		},
+		{name: "neither count nor emails", poll: inviteOnly, token: "secret", request: &model.CreateInvitesRequest{}, wantErr: ErrInvalidPollConfig},
+		{
+			name:    "poll is not invite-only",
+			poll:    &model.Poll{ID: "poll1", AdminTokenHash: hashToken("secret")},
+			token:   "secret",
+			request: &model.CreateInvitesRequest{Count: 3},
+			wantErr: ErrInvalidPollConfig,
+		},
+		{name: "invalid admin token", poll: inviteOnly, token: "guess", request: &model.CreateInvitesRequest{Count: 3}, wantErr: ErrAdminForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			mockStorage.On("GetPoll", mock.Anything, "poll1").Return(tt.poll, nil)
+			var stored []string
+			if tt.wantErr == nil {
+				mockStorage.On("AddInvites", mock.Anything, "poll1", mock.Anything).
+					Run(func(args mock.Arguments) { stored = args.Get(2).([]string) }).
+					Return(&model.InviteUsage{Issued: tt.wantInvites, Remaining: tt.wantInvites}, nil)
+			}
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			response, err := service.CreateInvites(context.Background(), "poll1", tt.token, tt.request)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockStorage.AssertExpectations(t)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, response.Invites, tt.wantInvites)
+			assert.Equal(t, tt.wantInvites, response.Issued)
+			for i, invite := range response.Invites {
+				assert.Equal(t, hashToken(invite.Token), stored[i], "only token hash is stored")
+				if len(tt.request.Emails) > 0 {
+					assert.Equal(t, tt.request.Emails[i], invite.Email)
+				}
+			}
+			assert.NotEqual(t, response.Invites[0].Token, response.Invites[1].Token)
+		})
+	}
+}
+
+func TestPollService_GetInviteUsage(t *testing.T) {
+	mockStorage := new(MockStorage)
+	mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&model.Poll{ID: "poll1", InviteOnly: true, AdminTokenHash: hashToken("secret")}, nil)
+	mockStorage.On("GetInviteUsage", mock.Anything, "poll1").Return(&model.InviteUsage{Issued: 5, Used: 2, Remaining: 3}, nil)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	usage, err := service.GetInviteUsage(context.Background(), "poll1", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, &model.InviteUsage{Issued: 5, Used: 2, Remaining: 3}, usage)
+
+	_, err = service.GetInviteUsage(context.Background(), "poll1", "guess")
+	assert.ErrorIs(t, err, ErrAdminForbidden)
+}
+
+func TestPollService_Vote_Invite(t *testing.T) {
+	for _, wantErr := range []error{storage.ErrInvalidInvite, storage.ErrInviteUsed} {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&model.Poll{ID: "poll1", InviteOnly: true}, nil)
+		mockStorage.On("Vote", mock.Anything, "poll1", mock.Anything, hashToken("invite-token-0001"), mock.Anything).Return(wantErr)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.Vote(context.Background(), "poll1", &model.VoteRequest{OptionIndices: []int{0}}, "invite-token-0001", &model.PollAccess{})
+
+		assert.ErrorIs(t, err, wantErr)
+	}
+}
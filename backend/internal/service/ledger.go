@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"log/slog"
+)
+
+// AuditLedger recomputes vote ledger chain and tally of poll and compares them with vote counters for its admin
+func (s *PollService) AuditLedger(ctx context.Context, pollID, adminToken string) (*model.LedgerAudit, error) {
+	if _, err := s.adminPoll(ctx, pollID, adminToken); err != nil {
+		return nil, err
+	}
+
+	audit, err := s.storage.AuditLedger(ctx, pollID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to audit ledger",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to audit ledger: %w", err)
+	}
+
+	if !audit.Consistent {
+		s.logger.WarnContext(ctx, "vote ledger diverges from counters",
+			slog.String("poll_id", pollID),
+			slog.Bool("chain_valid", audit.ChainValid),
+			slog.Int("divergences", len(audit.Divergences)),
+		)
+	}
+	return audit, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPollService_AuditLedger(t *testing.T) {
+	poll := &model.Poll{ID: "poll1", AdminTokenHash: hashToken("secret")}
+
+	t.Run("admin gets audit", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		want := &model.LedgerAudit{PollID: "poll1", Entries: 3, ChainValid: true, Consistent: true}
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+		mockStorage.On("AuditLedger", mock.Anything, "poll1").Return(want, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		audit, err := service.AuditLedger(context.Background(), "poll1", "secret")
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, audit)
+	})
+
+	t.Run("wrong admin token", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.AuditLedger(context.Background(), "poll1", "guess")
+
+		assert.ErrorIs(t, err, ErrAdminForbidden)
+		mockStorage.AssertNotCalled(t, "AuditLedger", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"github.com/AlexeyLars/surway-service/internal/model"
+)
+
+// likertColumns returns default columns of matrix poll, five point Likert scale
+func likertColumns() []string {
+	return []string{"Strongly disagree", "Disagree", "Neutral", "Agree", "Strongly agree"}
+}
+
+// matrixStatistics computes per-row distribution and mean of picked columns, columns are scored from 1
+func matrixStatistics(rows, columns []string, counts map[int]map[int]int) map[string]model.MatrixRow {
+	if len(columns) == 0 {
+		columns = likertColumns()
+	}
+
+	stats := make(map[string]model.MatrixRow, len(rows))
+	for r, row := range rows {
+		result := model.MatrixRow{
+			Distribution: make(map[string]int, len(columns)),
+		}
+
+		sum := 0
+		for c, column := range columns {
+			count := counts[r][c]
+			result.Distribution[column] = count
+			result.Count += count
+			sum += count * (c + 1)
+		}
+		if result.Count > 0 {
+			result.Mean = float64(sum) / float64(result.Count)
+		}
+		stats[row] = result
+	}
+
+	return stats
+}
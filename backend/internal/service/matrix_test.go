@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixStatistics(t *testing.T) {
+	rows := []string{"Docs are clear", "Builds are fast"}
+	counts := map[int]map[int]int{
+		// Agree twice, strongly agree once
+		0: {3: 2, 4: 1},
+	}
+
+	stats := matrixStatistics(rows, likertColumns(), counts)
+
+	require.Len(t, stats, 2)
+
+	docs := stats["Docs are clear"]
+	assert.Equal(t, 3, docs.Count)
+	assert.InDelta(t, 13.0/3, docs.Mean, 1e-9)
+	assert.Equal(t, map[string]int{
+		"Strongly disagree": 0,
+		"Disagree":          0,
+		"Neutral":           0,
+		"Agree":             2,
+		"Strongly agree":    1,
+	}, docs.Distribution)
+
+	builds := stats["Builds are fast"]
+	assert.Equal(t, 0, builds.Count)
+	assert.Zero(t, builds.Mean)
+	assert.Len(t, builds.Distribution, 5)
+}
+
+func TestMatrixStatistics_CustomColumns(t *testing.T) {
+	stats := matrixStatistics([]string{"Onboarding"}, []string{"Bad", "Good"}, map[int]map[int]int{0: {0: 1, 1: 3}})
+
+	assert.InDelta(t, 1.75, stats["Onboarding"].Mean, 1e-9)
+	assert.Equal(t, map[string]int{"Bad": 1, "Good": 3}, stats["Onboarding"].Distribution)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+)
+
+// NPS scale and categories
+const (
+	npsMaxRating   = 10
+	npsMinPromoter = 9
+	npsMinPassive  = 7
+	npsConfidence  = 0.95
+	npsConfidenceZ = 1.959964 // two-sided z-score of 95% confidence
+	npsPercent     = 100
+)
+
+// npsOptions returns ratings of nps poll as options "0" to "10"
+func npsOptions() []string {
+	options := make([]string, npsMaxRating+1)
+	for rating := range options {
+		options[rating] = strconv.Itoa(rating)
+	}
+	return options
+}
+
+// netPromoterScore computes NPS with normal approximation confidence interval from rating counters
+func netPromoterScore(votes map[string]int) *model.NPSResults {
+	results := &model.NPSResults{}
+	for rating := 0; rating <= npsMaxRating; rating++ {
+		count := votes[strconv.Itoa(rating)]
+		switch {
+		case rating >= npsMinPromoter:
+			results.Promoters += count
+		case rating >= npsMinPassive:
+			results.Passives += count
+		default:
+			results.Detractors += count
+		}
+		results.Responses += count
+	}
+	if results.Responses == 0 {
+		return results
+	}
+
+	n := float64(results.Responses)
+	promoters := float64(results.Promoters) / n
+	detractors := float64(results.Detractors) / n
+	nps := promoters - detractors
+	results.Score = nps * npsPercent
+
+	// Each response scores +1, 0 or -1, so variance is P + D - NPS^2
+	variance := promoters + detractors - nps*nps
+	margin := npsConfidenceZ * math.Sqrt(variance/n)
+	results.Confidence = &model.ConfidenceInterval{
+		Level: npsConfidence,
+		Lower: math.Max(nps-margin, -1) * npsPercent,
+		Upper: math.Min(nps+margin, 1) * npsPercent,
+	}
+
+	return results
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetPromoterScore(t *testing.T) {
+	t.Run("mixed ratings", func(t *testing.T) {
+		// 5 promoters, 3 passives, 2 detractors
+		votes := map[string]int{"10": 3, "9": 2, "8": 2, "7": 1, "6": 1, "0": 1}
+
+		nps := netPromoterScore(votes)
+
+		assert.Equal(t, 10, nps.Responses)
+		assert.Equal(t, 5, nps.Promoters)
+		assert.Equal(t, 3, nps.Passives)
+		assert.Equal(t, 2, nps.Detractors)
+		assert.InDelta(t, 30, nps.Score, 1e-9)
+
+		// Variance 0.5 + 0.2 - 0.09 = 0.61, margin 1.96 * sqrt(0.061)
+		require.NotNil(t, nps.Confidence)
+		assert.Equal(t, 0.95, nps.Confidence.Level)
+		assert.InDelta(t, -18.41, nps.Confidence.Lower, 0.01)
+		assert.InDelta(t, 78.41, nps.Confidence.Upper, 0.01)
+	})
+
+	t.Run("all promoters", func(t *testing.T) {
+		nps := netPromoterScore(map[string]int{"9": 4, "10": 6})
+
+		assert.InDelta(t, 100, nps.Score, 1e-9)
+		require.NotNil(t, nps.Confidence)
+		assert.InDelta(t, 100, nps.Confidence.Lower, 1e-9)
+		assert.InDelta(t, 100, nps.Confidence.Upper, 1e-9)
+	})
+
+	t.Run("no responses", func(t *testing.T) {
+		nps := netPromoterScore(map[string]int{"0": 0, "10": 0})
+
+		assert.Equal(t, 0, nps.Responses)
+		assert.Zero(t, nps.Score)
+		assert.Nil(t, nps.Confidence)
+	})
+}
+
+func TestNPSOptions(t *testing.T) {
+	options := npsOptions()
+
+	require.Len(t, options, 11)
+	assert.Equal(t, "0", options[0])
+	assert.Equal(t, "10", options[10])
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+)
+
+// numericPercentiles are reported in results of numeric polls, median is reported separately
+var numericPercentiles = []int{10, 25, 75, 90}
+
+// numericStatistics computes statistics of numbers from counters by step index
+func numericStatistics(rng *model.NumericRange, counts map[int]int) *model.NumericStats {
+	if rng == nil {
+		rng = &model.NumericRange{Min: defaultNumericMin, Max: defaultNumericMax, Step: defaultNumericStep}
+	}
+	steps := rng.Steps()
+
+	count, sum := 0, 0.0
+	for step := 0; step < steps; step++ {
+		count += counts[step]
+		sum += float64(counts[step]) * rng.Value(step)
+	}
+
+	stats := &model.NumericStats{
+		Count:       count,
+		Percentiles: make(map[string]float64, len(numericPercentiles)),
+		Histogram:   numericHistogram(rng, counts),
+	}
+	if count == 0 {
+		return stats
+	}
+
+	stats.Mean = sum / float64(count)
+	// Step index is linear in number, so median of indices maps to median of numbers
+	stats.Median = rng.Min + histogramMedian(counts, 0, steps-1, count)*rng.Step
+	for _, p := range numericPercentiles {
+		stats.Percentiles[fmt.Sprintf("p%d", p)] = rng.Value(nearestRank(counts, steps, count, p))
+	}
+
+	return stats
+}
+
+// nearestRank returns step index of p-th percentile by nearest-rank method
+func nearestRank(counts map[int]int, steps, count, p int) int {
+	rank := int(math.Ceil(float64(p) / 100 * float64(count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	seen := 0
+	for step := 0; step < steps; step++ {
+		seen += counts[step]
+		if seen >= rank {
+			return step
+		}
+	}
+	return steps - 1
+}
+
+// numericHistogram groups step counters into buckets of equal number of steps
+func numericHistogram(rng *model.NumericRange, counts map[int]int) []model.HistogramBucket {
+	steps := rng.Steps()
+	buckets := rng.Buckets
+	if buckets < 1 {
+		buckets = defaultNumericBuckets
+	}
+	perBucket := (steps + buckets - 1) / buckets
+
+	histogram := make([]model.HistogramBucket, 0, buckets)
+	for first := 0; first < steps; first += perBucket {
+		last := min(first+perBucket, steps) - 1
+		bucket := model.HistogramBucket{
+			From: rng.Value(first),
+			To:   rng.Value(last),
+		}
+		for step := first; step <= last; step++ {
+			bucket.Count += counts[step]
+		}
+		histogram = append(histogram, bucket)
+	}
+
+	return histogram
+}
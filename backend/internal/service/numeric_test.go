@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumericStatistics(t *testing.T) {
+	t.Run("hours spent", func(t *testing.T) {
+		rng := &model.NumericRange{Min: 0, Max: 40, Step: 1, Buckets: 4}
+		// 1, 2, 2, 3, 5, 8, 8, 10, 20, 40
+		counts := map[int]int{1: 1, 2: 2, 3: 1, 5: 1, 8: 2, 10: 1, 20: 1, 40: 1}
+
+		stats := numericStatistics(rng, counts)
+
+		assert.Equal(t, 10, stats.Count)
+		assert.InDelta(t, 9.9, stats.Mean, 1e-9)
+		assert.Equal(t, 6.5, stats.Median)
+		assert.Equal(t, map[string]float64{"p10": 1, "p25": 2, "p75": 10, "p90": 20}, stats.Percentiles)
+		assert.Equal(t, []model.HistogramBucket{
+			{From: 0, To: 10, Count: 8},
+			{From: 11, To: 21, Count: 1},
+			{From: 22, To: 32, Count: 0},
+			{From: 33, To: 40, Count: 1},
+		}, stats.Histogram)
+	})
+
+	t.Run("fractional step", func(t *testing.T) {
+		rng := &model.NumericRange{Min: 0, Max: 2, Step: 0.5, Buckets: 10}
+		// 0.5, 1.5, 1.5
+		counts := map[int]int{1: 1, 3: 2}
+
+		stats := numericStatistics(rng, counts)
+
+		assert.Equal(t, 3, stats.Count)
+		assert.InDelta(t, 7.0/6, stats.Mean, 1e-9)
+		assert.Equal(t, 1.5, stats.Median)
+		require.Len(t, stats.Histogram, 5, "one bucket per step when steps are fewer than buckets")
+		assert.Equal(t, model.HistogramBucket{From: 1.5, To: 1.5, Count: 2}, stats.Histogram[3])
+	})
+
+	t.Run("no answers", func(t *testing.T) {
+		rng := &model.NumericRange{Min: 1, Max: 10, Step: 1, Buckets: 3}
+
+		stats := numericStatistics(rng, map[int]int{})
+
+		assert.Equal(t, 0, stats.Count)
+		assert.Zero(t, stats.Mean)
+		assert.Empty(t, stats.Percentiles)
+		assert.Len(t, stats.Histogram, 3)
+	})
+}
+
+func TestNearestRank(t *testing.T) {
+	counts := map[int]int{0: 1, 1: 1, 2: 1, 3: 1}
+
+	assert.Equal(t, 0, nearestRank(counts, 4, 4, 10))
+	assert.Equal(t, 0, nearestRank(counts, 4, 4, 25))
+	assert.Equal(t, 1, nearestRank(counts, 4, 4, 50))
+	assert.Equal(t, 3, nearestRank(counts, 4, 4, 90))
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"golang.org/x/crypto/bcrypt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrPasswordRequired returns when password-protected poll is accessed without password or valid access token
+	ErrPasswordRequired = errors.New("poll password required")
+
+	// ErrWrongPassword returns when password doesn't match poll password
+	ErrWrongPassword = errors.New("wrong poll password")
+
+	// ErrTooManyAttempts returns when client gave too many wrong passwords within rate limit window
+	ErrTooManyAttempts = errors.New("too many wrong passwords")
+)
+
+// Wrong passwords rate limit per poll and client
+const (
+	maxPasswordAttempts   = 5
+	passwordAttemptWindow = 15 * time.Minute
+
+	// maxPasswordBytes is the longest password bcrypt accepts
+	maxPasswordBytes = 72
+)
+
+// hashPassword returns salted bcrypt hash of poll password
+func hashPassword(password string) (string, error) {
+	if len(password) > maxPasswordBytes {
+		return "", fmt.Errorf("%w: password must be at most %d bytes", ErrInvalidPollConfig, maxPasswordBytes)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// signAccessToken returns access token to poll valid until expiresAt, it is "<poll>.<expiry>.<signature>"
+func signAccessToken(key []byte, pollID string, expiresAt time.Time) string {
+	payload := pollID + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(accessSignature(key, payload))
+}
+
+// verifyAccessToken reports whether token is signed with key for poll and not expired
+func verifyAccessToken(key []byte, token, pollID string, now time.Time) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != pollID {
+		return false
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, accessSignature(key, parts[0]+"."+parts[1])) {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return false
+	}
+	return now.Unix() < expiresAt
+}
+
+// accessSignature returns HMAC-SHA256 of access token payload
+func accessSignature(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// checkPollAccess lets client into password-protected poll with access token issued for it or with password.
+// Wrong passwords are counted per client, client is refused once it runs out of attempts.
+func (s *PollService) checkPollAccess(ctx context.Context, poll *model.Poll, access *model.PollAccess) error {
+	if poll.PasswordHash == "" {
+		return nil
+	}
+	if access.AccessToken != "" && verifyAccessToken(s.accessKey, access.AccessToken, poll.ID, time.Now()) {
+		return nil
+	}
+	if access.Password == "" {
+		return ErrPasswordRequired
+	}
+	return s.checkPassword(ctx, poll, access)
+}
+
+// checkPassword compares password with poll password hash. Attempt of client is counted before comparison,
+// so parallel guesses can't all pass the limit, and is forgiven when password is right.
+func (s *PollService) checkPassword(ctx context.Context, poll *model.Poll, access *model.PollAccess) error {
+	attempts, err := s.storage.AddPasswordAttempt(ctx, poll.ID, access.Client, passwordAttemptWindow)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to count password attempt",
+			slog.String("poll_id", poll.ID),
+			slog.String("error", err.Error()),
+		)
+		return fmt.Errorf("failed to check password: %w", err)
+	}
+	if attempts > maxPasswordAttempts {
+		s.logger.WarnContext(ctx, "password attempts exhausted",
+			slog.String("poll_id", poll.ID),
+			slog.String("client", access.Client),
+		)
+		return ErrTooManyAttempts
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(poll.PasswordHash), []byte(access.Password)) == nil {
+		if err := s.storage.ForgivePasswordAttempt(ctx, poll.ID, access.Client); err != nil {
+			// Client is let in anyway, the attempt only stays counted
+			s.logger.ErrorContext(ctx, "failed to forgive password attempt",
+				slog.String("poll_id", poll.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil
+	}
+
+	s.logger.WarnContext(ctx, "wrong poll password",
+		slog.String("poll_id", poll.ID),
+		slog.String("client", access.Client),
+	)
+	return ErrWrongPassword
+}
+
+// IssueAccessToken exchanges poll password for access token, token expires after AccessTokenTTL or with the poll
+func (s *PollService) IssueAccessToken(ctx context.Context, pollID string, access *model.PollAccess) (*model.PollAccessResponse, error) {
+	poll, err := s.storage.GetPoll(ctx, pollID)
+	if err != nil {
+		if err == storage.ErrPollNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	if poll.PasswordHash == "" {
+		return nil, fmt.Errorf("%w: poll is not password-protected", ErrInvalidPollConfig)
+	}
+	if err := s.checkPassword(ctx, poll, access); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.config.Auth.AccessTokenTTL)
+	if poll.ExpiresAt.Before(expiresAt) {
+		expiresAt = poll.ExpiresAt
+	}
+
+	s.logger.InfoContext(ctx, "poll access token issued",
+		slog.String("poll_id", pollID),
+	)
+
+	return &model.PollAccessResponse{
+		AccessToken: signAccessToken(s.accessKey, pollID, expiresAt),
+		ExpiresAt:   expiresAt.Truncate(time.Second),
+	}, nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newProtectedPoll(t *testing.T, password string) *model.Poll {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	return &model.Poll{
+		ID:             "poll1",
+		Options:        []string{"A", "B"},
+		Protected:      true,
+		ExpiresAt:      time.Now().Add(24 * time.Hour),
+		AdminTokenHash: hashToken("secret"),
+		PasswordHash:   string(hash),
+	}
+}
+
+func TestAccessToken(t *testing.T) {
+	key := []byte("key")
+	now := time.Now()
+	token := signAccessToken(key, "poll1", now.Add(time.Hour))
+
+	assert.True(t, verifyAccessToken(key, token, "poll1", now))
+	assert.False(t, verifyAccessToken(key, token, "poll2", now), "token of other poll")
+	assert.False(t, verifyAccessToken(key, token, "poll1", now.Add(2*time.Hour)), "expired token")
+	assert.False(t, verifyAccessToken([]byte("other"), token, "poll1", now), "token signed with other key")
+	assert.False(t, verifyAccessToken(key, token+"x", "poll1", now), "tampered signature")
+	assert.False(t, verifyAccessToken(key, "poll1.9999999999."+token[len(token)-43:], "poll1", now), "tampered expiry")
+	assert.False(t, verifyAccessToken(key, "garbage", "poll1", now))
+}
+
+func TestPollService_CreatePoll_Password(t *testing.T) {
+	mockStorage := new(MockStorage)
+	var stored *model.Poll
+	mockStorage.On("CreatePoll", mock.Anything, mock.AnythingOfType("*model.Poll"), mock.AnythingOfType("time.Duration")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.Poll) }).
+		Return(nil)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	_, err := service.CreatePoll(context.Background(), &model.CreatePollRequest{
+		Title:    "Team offsite?",
+		Options:  []string{"Riga", "Tallinn"},
+		Password: "hunter22",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, stored.Protected)
+	assert.NotContains(t, stored.PasswordHash, "hunter22")
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("hunter22")))
+}
+
+func TestPollService_Vote_Password(t *testing.T) {
+	key := []byte(newTestConfig().Auth.AccessTokenSecret)
+
+	tests := []struct {
+		name        string
+		access      model.PollAccess
+		failures    int
+		wantVote    bool
+		wantForgive bool // attempt with right password is uncounted
+		wantErr     error
+	}{
+		{name: "right password", access: model.PollAccess{Password: "hunter22"}, wantVote: true, wantForgive: true},
+		{name: "no credentials", wantErr: ErrPasswordRequired},
+		{name: "wrong password", access: model.PollAccess{Password: "hunter2"}, wantErr: ErrWrongPassword},
+		{name: "last attempt", access: model.PollAccess{Password: "hunter2"}, failures: maxPasswordAttempts - 1, wantErr: ErrWrongPassword},
+		{name: "attempts exhausted", access: model.PollAccess{Password: "hunter22"}, failures: maxPasswordAttempts, wantErr: ErrTooManyAttempts},
+		{
+			name:     "access token",
+			access:   model.PollAccess{AccessToken: signAccessToken(key, "poll1", time.Now().Add(time.Hour))},
+			wantVote: true,
+		},
+		{
+			name:    "expired access token",
+			access:  model.PollAccess{AccessToken: signAccessToken(key, "poll1", time.Now().Add(-time.Minute))},
+			wantErr: ErrPasswordRequired,
+		},
+		{
+			name:        "expired access token with password",
+			access:      model.PollAccess{Password: "hunter22", AccessToken: signAccessToken(key, "poll1", time.Now().Add(-time.Minute))},
+			wantVote:    true,
+			wantForgive: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.access.Client = "10.0.0.1"
+			mockStorage := new(MockStorage)
+			mockStorage.On("GetPoll", mock.Anything, "poll1").Return(newProtectedPoll(t, "hunter22"), nil)
+			if tt.access.Password != "" {
+				mockStorage.On("AddPasswordAttempt", mock.Anything, "poll1", "10.0.0.1", passwordAttemptWindow).Return(tt.failures+1, nil)
+			}
+			if tt.wantForgive {
+				mockStorage.On("ForgivePasswordAttempt", mock.Anything, "poll1", "10.0.0.1").Return(nil)
+			}
+			if tt.wantVote {
+				mockStorage.On("Vote", mock.Anything, "poll1", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			}
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			_, err := service.Vote(context.Background(), "poll1", &model.VoteRequest{OptionIndices: []int{0}}, "", &tt.access)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_ViewResults_Password(t *testing.T) {
+	t.Run("admin needs no password", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		poll := newProtectedPoll(t, "hunter22")
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+		mockStorage.On("GetResults", mock.Anything, "poll1").Return(&model.PollResults{Poll: *poll}, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.ViewResults(context.Background(), "poll1", &model.ResultsViewer{AdminToken: "secret"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("viewer without password", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(newProtectedPoll(t, "hunter22"), nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.ViewResults(context.Background(), "poll1", &model.ResultsViewer{})
+
+		assert.ErrorIs(t, err, ErrPasswordRequired)
+		mockStorage.AssertExpectations(t)
+	})
+}
+
+func TestPollService_IssueAccessToken(t *testing.T) {
+	t.Run("token lets client vote", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(newProtectedPoll(t, "hunter22"), nil)
+		mockStorage.On("AddPasswordAttempt", mock.Anything, "poll1", "10.0.0.1", passwordAttemptWindow).Return(1, nil)
+		mockStorage.On("ForgivePasswordAttempt", mock.Anything, "poll1", "10.0.0.1").Return(nil)
+		mockStorage.On("Vote", mock.Anything, "poll1", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		response, err := service.IssueAccessToken(context.Background(), "poll1", &model.PollAccess{Password: "hunter22", Client: "10.0.0.1"})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), response.ExpiresAt, 2*time.Second)
+
+		_, err = service.Vote(context.Background(), "poll1", &model.VoteRequest{OptionIndices: []int{1}}, "", &model.PollAccess{AccessToken: response.AccessToken})
+		assert.NoError(t, err)
+	})
+
+	t.Run("token expires with poll", func(t *testing.T) {
+		poll := newProtectedPoll(t, "hunter22")
+		poll.ExpiresAt = time.Now().Add(10 * time.Minute)
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+		mockStorage.On("AddPasswordAttempt", mock.Anything, "poll1", "10.0.0.1", passwordAttemptWindow).Return(1, nil)
+		mockStorage.On("ForgivePasswordAttempt", mock.Anything, "poll1", "10.0.0.1").Return(nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		response, err := service.IssueAccessToken(context.Background(), "poll1", &model.PollAccess{Password: "hunter22", Client: "10.0.0.1"})
+
+		require.NoError(t, err)
+		assert.WithinDuration(t, poll.ExpiresAt, response.ExpiresAt, time.Second)
+	})
+
+	t.Run("poll without password", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&model.Poll{ID: "poll1"}, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.IssueAccessToken(context.Background(), "poll1", &model.PollAccess{Password: "hunter22"})
+
+		assert.ErrorIs(t, err, ErrInvalidPollConfig)
+	})
+}
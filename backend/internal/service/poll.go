@@ -2,44 +2,170 @@ package service
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"github.com/AlexeyLars/surway-service/internal/config"
 	"github.com/AlexeyLars/surway-service/internal/lib/random"
+	"github.com/AlexeyLars/surway-service/internal/lib/sanitize"
 	"github.com/AlexeyLars/surway-service/internal/model"
 	"github.com/AlexeyLars/surway-service/internal/storage"
 	"log/slog"
 	"time"
 )
 
+// ErrInvalidPollConfig returns when poll settings don't fit together
+var ErrInvalidPollConfig = errors.New("invalid poll configuration")
+
+// Score polls defaults and limits
+const (
+	defaultScoreMin = 1
+	defaultScoreMax = 5
+	maxScaleSteps   = 100
+
+	defaultBudgetPoints = 10
+	maxBudgetPoints     = 1000
+
+	defaultNumericMin     = 0
+	defaultNumericMax     = 100
+	defaultNumericStep    = 1
+	defaultNumericBuckets = 10
+	maxNumericSteps       = 10000
+	maxNumericBuckets     = 50
+
+	defaultTextLength = 500
+	maxTextLength     = 5000
+
+	defaultTermLength = 50
+	maxTermLength     = 200
+	defaultTopTerms   = 20
+	maxTopTerms       = 100
+
+	defaultQuestionPoints = 1
+	maxQuestionPoints     = 100
+
+	minOptions          = 2
+	defaultTextsPerPage = 20
+)
+
 // PollService contains business-logic for poll working
 type PollService struct {
-	storage storage.Storage
-	config  *config.Config
-	logger  *slog.Logger
+	storage    storage.Storage
+	config     *config.Config
+	logger     *slog.Logger
+	accessKey  []byte             // signs access tokens of password-protected polls
+	receiptKey ed25519.PrivateKey // signs vote receipts
+	chat       *chatResponder     // posts messages to response URLs of chat requests
 }
 
 func NewPollService(storage storage.Storage, cfg *config.Config, logger *slog.Logger) *PollService {
+	// Without configured secret access tokens are valid until restart
+	accessKey := []byte(cfg.Auth.AccessTokenSecret)
+	if len(accessKey) == 0 {
+		accessKey = []byte(random.NewToken())
+	}
+
+	// Without configured signing key receipts are verifiable until restart
+	receiptKey, err := cfg.Receipt.PrivateKey()
+	if err != nil || receiptKey == nil {
+		_, receiptKey, _ = ed25519.GenerateKey(rand.Reader)
+	}
+
 	return &PollService{
-		storage: storage,
-		config:  cfg,
-		logger:  logger,
+		storage:    storage,
+		config:     cfg,
+		logger:     logger,
+		accessKey:  accessKey,
+		receiptKey: receiptKey,
+		chat:       newChatResponder(cfg.Chat),
 	}
 }
 
 func (s *PollService) CreatePoll(ctx context.Context, req *model.CreatePollRequest) (*model.CreatePollResponse, error) {
+	question := model.Question{
+		Type:       req.Type,
+		Tabulation: req.Tabulation,
+		Scale:      req.Scale,
+		Budget:     req.Budget,
+		AllowOther: req.AllowOther,
+		MaxLength:  req.MaxLength,
+		TopTerms:   req.TopTerms,
+		Range:      req.Range,
+		Columns:    req.Columns,
+		Options:    req.Options,
+	}
+	if err := applyQuestionSettings(&question); err != nil {
+		s.logger.WarnContext(ctx, "invalid poll settings",
+			slog.String("type", string(req.Type)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
 	// Generate short alias as ID
 	aliasLen := 7
 	pollID := random.NewRandomString(aliasLen)
+	adminToken := random.NewToken()
 
 	now := time.Now()
 	expiresAt := now.Add(s.config.Poll.DefaultTTL)
+	if err := validateSchedule(&req.Schedule, now, expiresAt); err != nil {
+		s.logger.WarnContext(ctx, "invalid poll schedule",
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := validateEmailNotification(req, s.config.SMTP); err != nil {
+		return nil, err
+	}
+
+	if len(req.Webhooks) > model.MaxWebhooks {
+		return nil, fmt.Errorf("%w: poll can have at most %d webhooks", ErrInvalidPollConfig, model.MaxWebhooks)
+	}
+	webhooks := make([]*model.Webhook, len(req.Webhooks))
+	for i := range req.Webhooks {
+		webhook, err := newWebhook(&req.Webhooks[i], now, s.config.Webhook.AllowPrivate)
+		if err != nil {
+			return nil, err
+		}
+		webhooks[i] = webhook
+	}
+
+	var passwordHash string
+	if req.Password != "" {
+		hash, err := hashPassword(req.Password)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash = hash
+	}
 
 	poll := &model.Poll{
-		ID:        pollID,
-		Title:     req.Title,
-		Options:   req.Options,
+		ID:         pollID,
+		Title:      req.Title,
+		Type:       question.Type,
+		Tabulation: question.Tabulation,
+		Scale:      question.Scale,
+		Budget:     question.Budget,
+		AllowOther: question.AllowOther,
+		MaxLength:  question.MaxLength,
+		TopTerms:   question.TopTerms,
+		Range:      question.Range,
+		Columns:    question.Columns,
+		Options:    question.Options,
+
+		ResultsVisibility: req.ResultsVisibility,
+		Schedule:          req.Schedule,
+		Protected:         passwordHash != "",
+		InviteOnly:        req.InviteOnly,
+
 		CreatedAt: now,
 		ExpiresAt: expiresAt,
+
+		AdminTokenHash: hashToken(adminToken),
+		PasswordHash:   passwordHash,
 	}
 
 	// Save in storage
@@ -54,7 +180,8 @@ func (s *PollService) CreatePoll(ctx context.Context, req *model.CreatePollReque
 	s.logger.InfoContext(ctx, "poll created",
 		slog.String("poll_id", pollID),
 		slog.String("title", req.Title),
-		slog.Int("options_count", len(req.Options)),
+		slog.String("type", string(poll.Type)),
+		slog.Int("options_count", len(poll.Options)),
 	)
 
 	// Make response with URL
@@ -63,49 +190,447 @@ func (s *PollService) CreatePoll(ctx context.Context, req *model.CreatePollReque
 		PollID:     pollID,
 		VoteURL:    fmt.Sprintf("%s/api/v1/polls/%s/vote", baseURL, pollID),
 		ResultsURL: fmt.Sprintf("%s/api/v1/polls/%s/results", baseURL, pollID),
+		AdminToken: adminToken,
+	}
+
+	if req.NotifyEmail != "" {
+		notification := &model.EmailNotification{Email: req.NotifyEmail, Threshold: req.NotifyThreshold}
+		if err := s.storage.SetEmailNotification(ctx, poll, notification); err != nil {
+			s.logger.ErrorContext(ctx, "failed to save email notification",
+				slog.String("poll_id", pollID),
+				slog.String("error", err.Error()),
+			)
+			s.discardPoll(ctx, pollID)
+			return nil, fmt.Errorf("failed to save email notification: %w", err)
+		}
+	}
+
+	if len(webhooks) > 0 {
+		registered, err := s.registerWebhooks(ctx, poll, webhooks, now)
+		if err != nil {
+			s.discardPoll(ctx, pollID)
+			return nil, err
+		}
+		response.Webhooks = registered
 	}
 
 	return response, nil
 }
 
-// Vote register chosen by user option
-func (s *PollService) Vote(ctx context.Context, pollID string, req *model.VoteRequest) error {
-	if err := s.storage.Vote(ctx, pollID, req.OptionIndices); err != nil {
+// discardPoll deletes poll whose creation failed after it was saved, so client retry doesn't leave orphan poll
+func (s *PollService) discardPoll(ctx context.Context, pollID string) {
+	if err := s.storage.DeletePoll(context.WithoutCancel(ctx), pollID); err != nil && err != storage.ErrPollNotFound {
+		s.logger.ErrorContext(ctx, "failed to discard poll",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// registerWebhooks adds webhooks given on poll creation and sends them poll.created
+func (s *PollService) registerWebhooks(ctx context.Context, poll *model.Poll, webhooks []*model.Webhook, now time.Time) ([]model.Webhook, error) {
+	registered := make([]model.Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		if err := s.addWebhook(ctx, poll, webhook); err != nil {
+			return nil, err
+		}
+		registered[i] = *webhook
+	}
+
+	event, err := newWebhookEvent(poll.ID, model.WebhookPollCreated, model.NewPollDetails(poll, now), now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.EnqueueWebhookDeliveries(ctx, webhookDeliveries(registered, event), now); err != nil {
+		s.logger.ErrorContext(ctx, "failed to enqueue poll.created",
+			slog.String("poll_id", poll.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to enqueue poll.created: %w", err)
+	}
+	return registered, nil
+}
+
+// validateSchedule checks that voting window is ordered and ends before storage expiry
+func validateSchedule(schedule *model.Schedule, now, expiresAt time.Time) error {
+	if schedule.OpensAt != nil && !schedule.OpensAt.Before(expiresAt) {
+		return fmt.Errorf("%w: opens_at must be before expiry at %s", ErrInvalidPollConfig, expiresAt.Format(time.RFC3339))
+	}
+	if schedule.ClosesAt == nil {
+		return nil
+	}
+	if !schedule.ClosesAt.After(now) {
+		return fmt.Errorf("%w: closes_at must be in the future", ErrInvalidPollConfig)
+	}
+	if schedule.ClosesAt.After(expiresAt) {
+		return fmt.Errorf("%w: closes_at must not be after expiry at %s", ErrInvalidPollConfig, expiresAt.Format(time.RFC3339))
+	}
+	if schedule.OpensAt != nil && !schedule.OpensAt.Before(*schedule.ClosesAt) {
+		return fmt.Errorf("%w: opens_at must be before closes_at", ErrInvalidPollConfig)
+	}
+	return nil
+}
+
+// GetPoll returns poll with its status and countdown
+func (s *PollService) GetPoll(ctx context.Context, pollID string) (*model.PollDetails, error) {
+	poll, err := s.storage.GetPoll(ctx, pollID)
+	if err != nil {
+		if err == storage.ErrPollNotFound {
+			return nil, err
+		}
+		s.logger.ErrorContext(ctx, "failed to get poll",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	return model.NewPollDetails(poll, time.Now()), nil
+}
+
+// ExtendPoll moves poll expiry to requested time for poll admin, at most MaxTTL after poll creation
+func (s *PollService) ExtendPoll(ctx context.Context, pollID, adminToken string, req *model.ExtendPollRequest) (*model.PollDetails, error) {
+	poll, err := s.storage.GetPoll(ctx, pollID)
+	if err != nil {
+		if err == storage.ErrPollNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	if err := checkAdminToken(poll.AdminTokenHash, adminToken); err != nil {
+		s.logger.WarnContext(ctx, "poll extension requested without valid admin token",
+			slog.String("poll_id", pollID),
+		)
+		return nil, err
+	}
+
+	if err := validateExpiry(poll, req.ExpiresAt, s.config.Poll.MaxTTL); err != nil {
+		s.logger.WarnContext(ctx, "invalid poll expiry",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	extended, err := s.storage.ExtendPoll(ctx, pollID, req.ExpiresAt)
+	if err != nil {
+		if err == storage.ErrPollNotFound {
+			return nil, err
+		}
+		// Poll was extended further concurrently
+		if err == storage.ErrExpiryNotExtended {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPollConfig, err)
+		}
+		s.logger.ErrorContext(ctx, "failed to extend poll",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to extend poll: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "poll extended",
+		slog.String("poll_id", pollID),
+		slog.Time("expires_at", extended.ExpiresAt),
+	)
+
+	return model.NewPollDetails(extended, time.Now()), nil
+}
+
+// ClosePoll stops voting in poll now for its admin, results are kept until expiry
+func (s *PollService) ClosePoll(ctx context.Context, pollID, adminToken string) (*model.PollDetails, error) {
+	if _, err := s.adminPoll(ctx, pollID, adminToken); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	closed, err := s.storage.ClosePoll(ctx, pollID, now.UTC().Truncate(time.Second))
+	if err != nil {
+		if err == storage.ErrPollNotFound {
+			return nil, err
+		}
+		s.logger.ErrorContext(ctx, "failed to close poll",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to close poll: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "poll closed",
+		slog.String("poll_id", pollID),
+	)
+
+	return model.NewPollDetails(closed, now), nil
+}
+
+// DeletePoll removes poll with its votes for its admin
+func (s *PollService) DeletePoll(ctx context.Context, pollID, adminToken string) error {
+	if _, err := s.adminPoll(ctx, pollID, adminToken); err != nil {
+		return err
+	}
+
+	if err := s.storage.DeletePoll(ctx, pollID); err != nil {
+		if err == storage.ErrPollNotFound {
+			return err
+		}
+		s.logger.ErrorContext(ctx, "failed to delete poll",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return fmt.Errorf("failed to delete poll: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "poll deleted",
+		slog.String("poll_id", pollID),
+	)
+	return nil
+}
+
+// validateExpiry checks that new expiry pushes poll expiry out and fits maximum lifetime from creation
+func validateExpiry(poll *model.Poll, expiresAt time.Time, maxTTL time.Duration) error {
+	if !expiresAt.After(poll.ExpiresAt) {
+		return fmt.Errorf("%w: expires_at must be after current expiry at %s", ErrInvalidPollConfig, poll.ExpiresAt.Format(time.RFC3339))
+	}
+	if limit := poll.CreatedAt.Add(maxTTL); expiresAt.After(limit) {
+		return fmt.Errorf("%w: expires_at must not be after %s, %s from creation", ErrInvalidPollConfig, limit.Format(time.RFC3339), maxTTL)
+	}
+	return nil
+}
+
+// applyQuestionSettings fills type specific settings of new poll or question with defaults and checks they fit together
+func applyQuestionSettings(q *model.Question) error {
+	if q.Type == "" {
+		q.Type = model.PollTypeChoice
+	}
+
+	if q.Type == model.PollTypeRanked {
+		if q.Tabulation == "" {
+			q.Tabulation = model.TabulationIRV
+		}
+	} else if q.Tabulation != "" {
+		return fmt.Errorf("%w: tabulation is supported by ranked polls only", ErrInvalidPollConfig)
+	}
+
+	if q.Type == model.PollTypeScore {
+		if q.Scale == nil {
+			q.Scale = &model.ScoreScale{Min: defaultScoreMin, Max: defaultScoreMax}
+		}
+		if q.Scale.Min >= q.Scale.Max || q.Scale.Max-q.Scale.Min > maxScaleSteps {
+			return fmt.Errorf("%w: scale must have min below max and at most %d steps", ErrInvalidPollConfig, maxScaleSteps)
+		}
+	} else if q.Scale != nil {
+		return fmt.Errorf("%w: scale is supported by score polls only", ErrInvalidPollConfig)
+	}
+
+	if q.Type == model.PollTypeBudget {
+		if q.Budget == nil {
+			q.Budget = &model.PointBudget{Points: defaultBudgetPoints}
+		}
+		if q.Budget.Points < 1 || q.Budget.Points > maxBudgetPoints {
+			return fmt.Errorf("%w: budget must be between 1 and %d points", ErrInvalidPollConfig, maxBudgetPoints)
+		}
+	} else if q.Budget != nil {
+		return fmt.Errorf("%w: budget is supported by budget polls only", ErrInvalidPollConfig)
+	}
+
+	if q.Type == model.PollTypeNumeric {
+		if q.Range == nil {
+			q.Range = &model.NumericRange{Min: defaultNumericMin, Max: defaultNumericMax}
+		}
+		if q.Range.Step == 0 {
+			q.Range.Step = defaultNumericStep
+		}
+		if q.Range.Buckets == 0 {
+			q.Range.Buckets = defaultNumericBuckets
+		}
+		if q.Range.Min >= q.Range.Max || q.Range.Step < 0 || (q.Range.Max-q.Range.Min)/q.Range.Step >= maxNumericSteps {
+			return fmt.Errorf("%w: range must have min below max, positive step and at most %d steps", ErrInvalidPollConfig, maxNumericSteps)
+		}
+		if q.Range.Buckets < 1 || q.Range.Buckets > maxNumericBuckets {
+			return fmt.Errorf("%w: histogram must have between 1 and %d buckets", ErrInvalidPollConfig, maxNumericBuckets)
+		}
+	} else if q.Range != nil {
+		return fmt.Errorf("%w: range is supported by numeric polls only", ErrInvalidPollConfig)
+	}
+
+	if q.Type == model.PollTypeMatrix {
+		if len(q.Columns) == 0 {
+			q.Columns = likertColumns()
+		}
+		if len(q.Columns) < minOptions {
+			return fmt.Errorf("%w: at least %d columns are required", ErrInvalidPollConfig, minOptions)
+		}
+	} else if len(q.Columns) > 0 {
+		return fmt.Errorf("%w: columns are supported by matrix polls only", ErrInvalidPollConfig)
+	}
+
+	// NPS ratings are fixed options, each rating has its own counter
+	if q.Type == model.PollTypeNPS {
+		if len(q.Options) > 0 {
+			return fmt.Errorf("%w: nps polls have fixed 0-10 scale", ErrInvalidPollConfig)
+		}
+		q.Options = npsOptions()
+	}
+
+	if q.Type == model.PollTypeText || q.Type == model.PollTypeNumeric || q.Type == model.PollTypeWordCloud {
+		if len(q.Options) > 0 {
+			return fmt.Errorf("%w: %s polls have no options", ErrInvalidPollConfig, q.Type)
+		}
+	} else if len(q.Options) < minOptions {
+		return fmt.Errorf("%w: at least %d options are required", ErrInvalidPollConfig, minOptions)
+	}
+
+	if q.AllowOther && q.Type != model.PollTypeChoice {
+		return fmt.Errorf("%w: other write-in is supported by choice polls only", ErrInvalidPollConfig)
+	}
+	if q.Type == model.PollTypeWordCloud {
+		if q.MaxLength == 0 {
+			q.MaxLength = defaultTermLength
+		}
+		if q.MaxLength > maxTermLength {
+			return fmt.Errorf("%w: word cloud max length must be at most %d characters", ErrInvalidPollConfig, maxTermLength)
+		}
+		if q.TopTerms == 0 {
+			q.TopTerms = defaultTopTerms
+		}
+		if q.TopTerms > maxTopTerms {
+			return fmt.Errorf("%w: at most %d top terms can be shown", ErrInvalidPollConfig, maxTopTerms)
+		}
+	} else if q.TopTerms != 0 {
+		return fmt.Errorf("%w: top terms are supported by word cloud polls only", ErrInvalidPollConfig)
+	} else if q.Type == model.PollTypeText || q.AllowOther {
+		if q.MaxLength == 0 {
+			q.MaxLength = defaultTextLength
+		}
+		if q.MaxLength > maxTextLength {
+			return fmt.Errorf("%w: max length must be at most %d characters", ErrInvalidPollConfig, maxTextLength)
+		}
+	} else if q.MaxLength != 0 {
+		return fmt.Errorf("%w: max length is supported by text polls, word clouds and write-ins only", ErrInvalidPollConfig)
+	}
+
+	if len(q.Correct) > 0 {
+		if q.Type != model.PollTypeChoice {
+			return fmt.Errorf("%w: correct options are supported by choice questions only", ErrInvalidPollConfig)
+		}
+		seen := make(map[int]bool, len(q.Correct))
+		for _, option := range q.Correct {
+			if option < 0 || option >= len(q.Options) || seen[option] {
+				return fmt.Errorf("%w: correct options must be unique indices of question options", ErrInvalidPollConfig)
+			}
+			seen[option] = true
+		}
+		if q.Points == 0 {
+			q.Points = defaultQuestionPoints
+		}
+		if q.Points > maxQuestionPoints {
+			return fmt.Errorf("%w: question gives at most %d points", ErrInvalidPollConfig, maxQuestionPoints)
+		}
+	} else if q.Points != 0 {
+		return fmt.Errorf("%w: points are supported by questions with correct options only", ErrInvalidPollConfig)
+	}
+
+	return nil
+}
+
+// Vote register chosen by user option and returns signed receipt, voter token is remembered for results visible after voting.
+// Password-protected polls require password or access token.
+func (s *PollService) Vote(ctx context.Context, pollID string, req *model.VoteRequest, voterToken string, access *model.PollAccess) (*model.VoteReceipt, error) {
+	poll, err := s.storage.GetPoll(ctx, pollID)
+	if err != nil {
 		if err == storage.ErrPollNotFound {
 			s.logger.WarnContext(ctx, "vote for non-existent poll",
 				slog.String("poll_id", pollID),
 			)
-			return err
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+	if err := s.checkPollAccess(ctx, poll, access); err != nil {
+		return nil, err
+	}
+
+	req.Text = sanitize.Text(req.Text)
+
+	receipt, err := s.newReceipt(pollID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nonce stays with voter, only ballot hash is stored
+	stored := *receipt
+	stored.Nonce = ""
+	if err := s.storage.Vote(ctx, pollID, req, voterHash(voterToken), &stored); err != nil {
+		if err == storage.ErrPollNotFound {
+			s.logger.WarnContext(ctx, "vote for non-existent poll",
+				slog.String("poll_id", pollID),
+			)
+			return nil, err
 		}
 		if err == storage.ErrInvalidOption {
 			s.logger.WarnContext(ctx, "invalid option index",
 				slog.String("poll_id", pollID),
 				slog.Any("option_indices", req.OptionIndices),
+				slog.Any("ranking", req.Ranking),
 			)
-			return err
+			return nil, err
+		}
+		if err == storage.ErrNotOpen || err == storage.ErrClosed {
+			s.logger.WarnContext(ctx, "vote outside voting window",
+				slog.String("poll_id", pollID),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+		if err == storage.ErrAlreadyVoted {
+			s.logger.WarnContext(ctx, "repeated vote",
+				slog.String("poll_id", pollID),
+			)
+			return nil, err
+		}
+		if err == storage.ErrInvalidInvite || err == storage.ErrInviteUsed {
+			s.logger.WarnContext(ctx, "vote without unused invite",
+				slog.String("poll_id", pollID),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
 		}
 		if err == storage.ErrDuplicateOption {
 			s.logger.WarnContext(ctx, "duplicate option index",
 				slog.String("poll_id", pollID),
 				slog.Any("option_indices", req.OptionIndices),
+				slog.Any("ranking", req.Ranking),
 			)
-			return err
+			return nil, err
+		}
+		if err == storage.ErrEmptyVote || err == storage.ErrVoteTypeMismatch || err == storage.ErrInvalidScore ||
+			err == storage.ErrInvalidPoints || err == storage.ErrBudgetExceeded || err == storage.ErrTextTooLong ||
+			err == storage.ErrInvalidNumber || err == storage.ErrInvalidColumn {
+			s.logger.WarnContext(ctx, "invalid ballot",
+				slog.String("poll_id", pollID),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
 		}
 
 		s.logger.ErrorContext(ctx, "failed to register votes",
 			slog.String("poll_id", pollID),
 			slog.String("error", err.Error()),
 		)
-		return fmt.Errorf("failed to register votes: %w", err)
+		return nil, fmt.Errorf("failed to register votes: %w", err)
 	}
 
 	s.logger.InfoContext(ctx, "votes registered",
 		slog.String("poll_id", pollID),
 		slog.Any("option_indices", req.OptionIndices),
-		slog.Int("votes_count", len(req.OptionIndices)),
+		slog.Any("ranking", req.Ranking),
+		slog.Any("scores", req.Scores),
+		slog.Any("points", req.Points),
+		slog.Int("votes_count", len(req.OptionIndices)+len(req.Ranking)+len(req.Scores)+len(req.Points)),
+		slog.String("receipt_id", receipt.ID),
 	)
 
-	return nil
+	return receipt, nil
 }
 
 // GetResults get vote results
@@ -126,5 +651,131 @@ func (s *PollService) GetResults(ctx context.Context, pollID string) (*model.Pol
 		return nil, fmt.Errorf("failed to get results: %w", err)
 	}
 
+	question := results.Poll.Question()
+	breakdown, err := questionBreakdown(ctx, &question, results.Votes, s.pollAnswers(pollID))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to tabulate results",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	results.Breakdown = breakdown
+	if total, ok := breakdown.Answers(); ok {
+		results.Total = total
+	}
+
 	return results, nil
 }
+
+// GetTextAnswers returns page of text answers and write-ins of poll to its admin
+func (s *PollService) GetTextAnswers(ctx context.Context, pollID, adminToken string, query *model.TextAnswersQuery) (*model.TextAnswersPage, error) {
+	poll, err := s.storage.GetPoll(ctx, pollID)
+	if err != nil {
+		if err == storage.ErrPollNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	if err := checkAdminToken(poll.AdminTokenHash, adminToken); err != nil {
+		s.logger.WarnContext(ctx, "text answers requested without valid admin token",
+			slog.String("poll_id", pollID),
+		)
+		return nil, err
+	}
+
+	limit := textsPerPage(query)
+	answers, total, err := s.storage.GetTextAnswers(ctx, pollID, query.Offset, limit)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get text answers",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to get text answers: %w", err)
+	}
+
+	return &model.TextAnswersPage{
+		Items:  answers,
+		Total:  total,
+		Offset: query.Offset,
+		Limit:  limit,
+	}, nil
+}
+
+// textsPerPage returns page size of text answers listing
+func textsPerPage(query *model.TextAnswersQuery) int {
+	if query.Limit == 0 {
+		return defaultTextsPerPage
+	}
+	return query.Limit
+}
+
+// answerSource loads stored answers of poll or survey question needed for type specific results
+type answerSource struct {
+	ballots func(context.Context) ([][]int, error)
+	scores  func(context.Context) (map[int]model.ScoreStats, error)
+	numbers func(context.Context) (map[int]int, error)
+	matrix  func(context.Context) (map[int]map[int]int, error)
+	terms   func(context.Context, int) (*model.WordCloudResults, error)
+}
+
+// pollAnswers returns answer source of poll
+func (s *PollService) pollAnswers(pollID string) answerSource {
+	return answerSource{
+		ballots: func(ctx context.Context) ([][]int, error) { return s.storage.GetBallots(ctx, pollID) },
+		scores:  func(ctx context.Context) (map[int]model.ScoreStats, error) { return s.storage.GetScores(ctx, pollID) },
+		numbers: func(ctx context.Context) (map[int]int, error) { return s.storage.GetNumbers(ctx, pollID) },
+		matrix:  func(ctx context.Context) (map[int]map[int]int, error) { return s.storage.GetMatrix(ctx, pollID) },
+		terms: func(ctx context.Context, limit int) (*model.WordCloudResults, error) {
+			return s.storage.GetTerms(ctx, pollID, limit)
+		},
+	}
+}
+
+// questionBreakdown computes type specific results of poll or survey question from vote counters and stored answers
+func questionBreakdown(ctx context.Context, question *model.Question, votes map[string]int, source answerSource) (model.Breakdown, error) {
+	var breakdown model.Breakdown
+
+	switch question.VotingType() {
+	case model.PollTypeRanked:
+		stored, err := source.ballots(ctx)
+		if err != nil {
+			return breakdown, fmt.Errorf("failed to get ballots: %w", err)
+		}
+
+		if question.Tabulation == model.TabulationSchulze {
+			breakdown.Schulze = tabulateSchulze(question.Options, stored)
+		} else {
+			breakdown.Runoff = tabulateRunoff(question.Options, stored)
+		}
+	case model.PollTypeScore:
+		stored, err := source.scores(ctx)
+		if err != nil {
+			return breakdown, fmt.Errorf("failed to get scores: %w", err)
+		}
+		breakdown.Scores = scoreStatistics(question.Options, question.Scale, stored)
+	case model.PollTypeNumeric:
+		stored, err := source.numbers(ctx)
+		if err != nil {
+			return breakdown, fmt.Errorf("failed to get numbers: %w", err)
+		}
+		breakdown.Numeric = numericStatistics(question.Range, stored)
+	case model.PollTypeNPS:
+		breakdown.NPS = netPromoterScore(votes)
+	case model.PollTypeMatrix:
+		stored, err := source.matrix(ctx)
+		if err != nil {
+			return breakdown, fmt.Errorf("failed to get matrix: %w", err)
+		}
+		breakdown.Matrix = matrixStatistics(question.Options, question.Columns, stored)
+	case model.PollTypeWordCloud:
+		stored, err := source.terms(ctx, question.TopTerms)
+		if err != nil {
+			return breakdown, fmt.Errorf("failed to get terms: %w", err)
+		}
+		breakdown.Words = stored
+	}
+
+	return breakdown, nil
+}
@@ -34,11 +34,79 @@ func (m *MockStorage) GetPoll(ctx context.Context, pollID string) (*model.Poll,
 	return args.Get(0).(*model.Poll), args.Error(1)
 }
 
-func (m *MockStorage) Vote(ctx context.Context, pollID string, optionIndices []int) error {
-	args := m.Called(ctx, pollID, optionIndices)
+func (m *MockStorage) ExtendPoll(ctx context.Context, pollID string, expiresAt time.Time) (*model.Poll, error) {
+	args := m.Called(ctx, pollID, expiresAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Poll), args.Error(1)
+}
+
+func (m *MockStorage) AddPasswordAttempt(ctx context.Context, pollID, client string, window time.Duration) (int, error) {
+	args := m.Called(ctx, pollID, client, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStorage) ForgivePasswordAttempt(ctx context.Context, pollID, client string) error {
+	args := m.Called(ctx, pollID, client)
+	return args.Error(0)
+}
+
+func (m *MockStorage) AddInvites(ctx context.Context, pollID string, invites []string) (*model.InviteUsage, error) {
+	args := m.Called(ctx, pollID, invites)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.InviteUsage), args.Error(1)
+}
+
+func (m *MockStorage) GetInviteUsage(ctx context.Context, pollID string) (*model.InviteUsage, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.InviteUsage), args.Error(1)
+}
+
+func (m *MockStorage) Vote(ctx context.Context, pollID string, req *model.VoteRequest, voter string, receipt *model.VoteReceipt) error {
+	args := m.Called(ctx, pollID, req, voter, receipt)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ClosePoll(ctx context.Context, pollID string, closesAt time.Time) (*model.Poll, error) {
+	args := m.Called(ctx, pollID, closesAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Poll), args.Error(1)
+}
+
+func (m *MockStorage) DeletePoll(ctx context.Context, pollID string) error {
+	args := m.Called(ctx, pollID)
 	return args.Error(0)
 }
 
+func (m *MockStorage) AuditLedger(ctx context.Context, pollID string) (*model.LedgerAudit, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LedgerAudit), args.Error(1)
+}
+
+func (m *MockStorage) GetReceipt(ctx context.Context, pollID, receiptID string) (*model.VoteReceipt, error) {
+	args := m.Called(ctx, pollID, receiptID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.VoteReceipt), args.Error(1)
+}
+
+func (m *MockStorage) HasVoted(ctx context.Context, pollID, voter string) (bool, error) {
+	args := m.Called(ctx, pollID, voter)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockStorage) GetResults(ctx context.Context, pollID string) (*model.PollResults, error) {
 	args := m.Called(ctx, pollID)
 	if args.Get(0) == nil {
@@ -47,6 +115,273 @@ func (m *MockStorage) GetResults(ctx context.Context, pollID string) (*model.Pol
 	return args.Get(0).(*model.PollResults), args.Error(1)
 }
 
+func (m *MockStorage) GetBallots(ctx context.Context, pollID string) ([][]int, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([][]int), args.Error(1)
+}
+
+func (m *MockStorage) GetScores(ctx context.Context, pollID string) (map[int]model.ScoreStats, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]model.ScoreStats), args.Error(1)
+}
+
+func (m *MockStorage) GetTextAnswers(ctx context.Context, pollID string, offset, limit int) ([]model.TextAnswer, int, error) {
+	args := m.Called(ctx, pollID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]model.TextAnswer), args.Int(1), args.Error(2)
+}
+
+func (m *MockStorage) GetNumbers(ctx context.Context, pollID string) (map[int]int, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]int), args.Error(1)
+}
+
+func (m *MockStorage) GetMatrix(ctx context.Context, pollID string) (map[int]map[int]int, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]map[int]int), args.Error(1)
+}
+
+func (m *MockStorage) GetTerms(ctx context.Context, pollID string, limit int) (*model.WordCloudResults, error) {
+	args := m.Called(ctx, pollID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WordCloudResults), args.Error(1)
+}
+
+func (m *MockStorage) WatchPoll(ctx context.Context, pollID string) (<-chan struct{}, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(<-chan struct{}), args.Error(1)
+}
+
+func (m *MockStorage) WatchVotes(ctx context.Context) (<-chan string, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(<-chan string), args.Error(1)
+}
+
+func (m *MockStorage) AddWebhook(ctx context.Context, poll *model.Poll, webhook *model.Webhook) error {
+	args := m.Called(ctx, poll, webhook)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ListWebhooks(ctx context.Context, pollID string) ([]model.Webhook, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (m *MockStorage) DeleteWebhook(ctx context.Context, pollID, webhookID string) error {
+	args := m.Called(ctx, pollID, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ScheduleVoteCast(ctx context.Context, pollID string, at time.Time) error {
+	args := m.Called(ctx, pollID, at)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ClaimWebhookTriggers(ctx context.Context, now time.Time, limit int) ([]model.WebhookTrigger, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WebhookTrigger), args.Error(1)
+}
+
+func (m *MockStorage) CountVoteBatch(ctx context.Context, pollID string) (int64, error) {
+	args := m.Called(ctx, pollID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStorage) EnqueueWebhookDeliveries(ctx context.Context, deliveries []model.WebhookDelivery, at time.Time) error {
+	args := m.Called(ctx, deliveries, at)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ClaimWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockStorage) RecordWebhookAttempt(ctx context.Context, pollID string, attempt *model.WebhookAttempt, dead *model.WebhookDeadLetter) error {
+	args := m.Called(ctx, pollID, attempt, dead)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetWebhookDeliveryLog(ctx context.Context, pollID string) (*model.WebhookDeliveryLog, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDeliveryLog), args.Error(1)
+}
+
+func (m *MockStorage) SetEmailNotification(ctx context.Context, poll *model.Poll, notification *model.EmailNotification) error {
+	args := m.Called(ctx, poll, notification)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetEmailNotification(ctx context.Context, pollID string) (*model.EmailNotification, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.EmailNotification), args.Error(1)
+}
+
+func (m *MockStorage) ClaimVoteThreshold(ctx context.Context, pollID string) (*model.EmailNotification, int64, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).(*model.EmailNotification), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStorage) ReleaseVoteThreshold(ctx context.Context, pollID string) error {
+	args := m.Called(ctx, pollID)
+	return args.Error(0)
+}
+
+func (m *MockStorage) AddRecipientEmail(ctx context.Context, email string, window time.Duration) (int, error) {
+	args := m.Called(ctx, email, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStorage) ClaimEmailTriggers(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockStorage) EnqueueEmails(ctx context.Context, deliveries []model.EmailDelivery, at time.Time) error {
+	args := m.Called(ctx, deliveries, at)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ClaimEmails(ctx context.Context, now time.Time, limit int) ([]model.EmailDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.EmailDelivery), args.Error(1)
+}
+
+func (m *MockStorage) SetChatResponseURL(ctx context.Context, pollID, responseURL string, ttl time.Duration) error {
+	args := m.Called(ctx, pollID, responseURL, ttl)
+	return args.Error(0)
+}
+
+func (m *MockStorage) ClaimChatUpdate(ctx context.Context, pollID string, interval time.Duration) (string, error) {
+	args := m.Called(ctx, pollID, interval)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorage) CreateSurvey(ctx context.Context, survey *model.Survey, ttl time.Duration) error {
+	args := m.Called(ctx, survey, ttl)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetSurvey(ctx context.Context, surveyID string) (*model.Survey, error) {
+	args := m.Called(ctx, surveyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Survey), args.Error(1)
+}
+
+func (m *MockStorage) SubmitSurvey(ctx context.Context, surveyID string, answers []model.Answer, voter string) error {
+	args := m.Called(ctx, surveyID, answers, voter)
+	return args.Error(0)
+}
+
+func (m *MockStorage) HasSubmitted(ctx context.Context, surveyID, voter string) (bool, error) {
+	args := m.Called(ctx, surveyID, voter)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStorage) GetSurveyResults(ctx context.Context, surveyID string) (*model.SurveyResults, error) {
+	args := m.Called(ctx, surveyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SurveyResults), args.Error(1)
+}
+
+func (m *MockStorage) GetSurveyBallots(ctx context.Context, surveyID string, question int) ([][]int, error) {
+	args := m.Called(ctx, surveyID, question)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([][]int), args.Error(1)
+}
+
+func (m *MockStorage) GetSurveyScores(ctx context.Context, surveyID string, question int) (map[int]model.ScoreStats, error) {
+	args := m.Called(ctx, surveyID, question)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]model.ScoreStats), args.Error(1)
+}
+
+func (m *MockStorage) GetSurveyTextAnswers(ctx context.Context, surveyID string, question, offset, limit int) ([]model.TextAnswer, int, error) {
+	args := m.Called(ctx, surveyID, question, offset, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]model.TextAnswer), args.Int(1), args.Error(2)
+}
+
+func (m *MockStorage) GetSurveyNumbers(ctx context.Context, surveyID string, question int) (map[int]int, error) {
+	args := m.Called(ctx, surveyID, question)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]int), args.Error(1)
+}
+
+func (m *MockStorage) GetSurveyMatrix(ctx context.Context, surveyID string, question int) (map[int]map[int]int, error) {
+	args := m.Called(ctx, surveyID, question)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]map[int]int), args.Error(1)
+}
+
+func (m *MockStorage) GetSurveyTerms(ctx context.Context, surveyID string, question, limit int) (*model.WordCloudResults, error) {
+	args := m.Called(ctx, surveyID, question, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WordCloudResults), args.Error(1)
+}
+
 func (m *MockStorage) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -61,6 +396,11 @@ func newTestConfig() *config.Config {
 		},
 		Poll: config.PollConfig{
 			DefaultTTL: 168 * time.Hour,
+			MaxTTL:     720 * time.Hour,
+		},
+		Auth: config.AuthConfig{
+			AccessTokenSecret: "test-secret",
+			AccessTokenTTL:    time.Hour,
 		},
 	}
 }
@@ -147,6 +487,338 @@ func TestPollService_CreatePoll(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "ranked poll defaults to instant-runoff",
+			request: &model.CreatePollRequest{
+				Title:   "Team name?",
+				Type:    model.PollTypeRanked,
+				Options: []string{"Owls", "Foxes", "Bears"},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.Type == model.PollTypeRanked && p.Tabulation == model.TabulationIRV
+				}), mock.AnythingOfType("time.Duration")).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "ranked poll with schulze tabulation",
+			request: &model.CreatePollRequest{
+				Title:      "Architecture council",
+				Type:       model.PollTypeRanked,
+				Tabulation: model.TabulationSchulze,
+				Options:    []string{"Monolith", "Services"},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.Tabulation == model.TabulationSchulze
+				}), mock.AnythingOfType("time.Duration")).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "score poll defaults to five point scale",
+			request: &model.CreatePollRequest{
+				Title:   "Rate the talks",
+				Type:    model.PollTypeScore,
+				Options: []string{"Keynote", "Workshop"},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.Scale != nil && p.Scale.Min == 1 && p.Scale.Max == 5
+				}), mock.AnythingOfType("time.Duration")).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "score poll with inverted scale",
+			request: &model.CreatePollRequest{
+				Title:   "Rate the talks",
+				Type:    model.PollTypeScore,
+				Scale:   &model.ScoreScale{Min: 10, Max: 0},
+				Options: []string{"Keynote", "Workshop"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "scale for choice poll",
+			request: &model.CreatePollRequest{
+				Title:   "Lunch?",
+				Scale:   &model.ScoreScale{Min: 1, Max: 10},
+				Options: []string{"Pizza", "Sushi"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "quadratic budget poll",
+			request: &model.CreatePollRequest{
+				Title:   "Sprint priorities",
+				Type:    model.PollTypeBudget,
+				Budget:  &model.PointBudget{Points: 25, Quadratic: true},
+				Options: []string{"Search", "Export", "Dark mode"},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.Type == model.PollTypeBudget && p.Budget.Points == 25 && p.Budget.Quadratic
+				}), mock.AnythingOfType("time.Duration")).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "budget poll defaults to ten points",
+			request: &model.CreatePollRequest{
+				Title:   "Sprint priorities",
+				Type:    model.PollTypeBudget,
+				Options: []string{"Search", "Export"},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.Budget != nil && p.Budget.Points == 10 && !p.Budget.Quadratic
+				}), mock.AnythingOfType("time.Duration")).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "budget poll without points",
+			request: &model.CreatePollRequest{
+				Title:   "Sprint priorities",
+				Type:    model.PollTypeBudget,
+				Budget:  &model.PointBudget{Points: 0},
+				Options: []string{"Search", "Export"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "tabulation for choice poll",
+			request: &model.CreatePollRequest{
+				Title:      "Lunch?",
+				Tabulation: model.TabulationSchulze,
+				Options:    []string{"Pizza", "Sushi"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "single option",
+			request: &model.CreatePollRequest{
+				Title:   "Lunch?",
+				Options: []string{"Pizza"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "text poll defaults to 500 characters",
+			request: &model.CreatePollRequest{
+				Title: "Any feedback?",
+				Type:  model.PollTypeText,
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.Type == model.PollTypeText && p.MaxLength == 500 && len(p.Options) == 0
+				}), mock.AnythingOfType("time.Duration")).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "text poll with options",
+			request: &model.CreatePollRequest{
+				Title:   "Any feedback?",
+				Type:    model.PollTypeText,
+				Options: []string{"Yes", "No"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "choice poll with other write-in",
+			request: &model.CreatePollRequest{
+				Title:      "Favorite editor?",
+				AllowOther: true,
+				MaxLength:  40,
+				Options:    []string{"Vim", "Emacs"},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.AllowOther && p.MaxLength == 40
+				}), mock.AnythingOfType("time.Duration")).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "other write-in for ranked poll",
+			request: &model.CreatePollRequest{
+				Title:      "Favorite editor?",
+				Type:       model.PollTypeRanked,
+				AllowOther: true,
+				Options:    []string{"Vim", "Emacs"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "max length without text",
+			request: &model.CreatePollRequest{
+				Title:     "Lunch?",
+				MaxLength: 100,
+				Options:   []string{"Pizza", "Sushi"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "matrix poll defaults to Likert columns",
+			request: &model.CreatePollRequest{
+				Title:   "How do you feel about the team?",
+				Type:    model.PollTypeMatrix,
+				Options: []string{"Docs are clear", "Builds are fast"},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return len(p.Columns) == 5 && p.Columns[0] == "Strongly disagree" && len(p.Options) == 2
+				}), mock.AnythingOfType("time.Duration")).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "matrix poll with single column",
+			request: &model.CreatePollRequest{
+				Title:   "How do you feel about the team?",
+				Type:    model.PollTypeMatrix,
+				Columns: []string{"Agree"},
+				Options: []string{"Docs are clear", "Builds are fast"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "columns for choice poll",
+			request: &model.CreatePollRequest{
+				Title:   "Lunch?",
+				Columns: []string{"Yes", "No"},
+				Options: []string{"Pizza", "Sushi"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "word cloud poll defaults",
+			request: &model.CreatePollRequest{
+				Title: "Describe the sprint in one word",
+				Type:  model.PollTypeWordCloud,
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.Type == model.PollTypeWordCloud && p.MaxLength == 50 && p.TopTerms == 20 && len(p.Options) == 0
+				}), mock.AnythingOfType("time.Duration")).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "word cloud poll with too many top terms",
+			request: &model.CreatePollRequest{
+				Title:    "Describe the sprint in one word",
+				Type:     model.PollTypeWordCloud,
+				TopTerms: 101,
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "top terms for choice poll",
+			request: &model.CreatePollRequest{
+				Title:    "Lunch?",
+				TopTerms: 5,
+				Options:  []string{"Pizza", "Sushi"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "nps poll has eleven ratings",
+			request: &model.CreatePollRequest{
+				Title: "How likely are you to recommend us?",
+				Type:  model.PollTypeNPS,
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.Type == model.PollTypeNPS && len(p.Options) == 11 && p.Options[10] == "10"
+				}), mock.AnythingOfType("time.Duration")).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "nps poll with custom options",
+			request: &model.CreatePollRequest{
+				Title:   "How likely are you to recommend us?",
+				Type:    model.PollTypeNPS,
+				Options: []string{"Low", "High"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "numeric poll defaults to 0-100",
+			request: &model.CreatePollRequest{
+				Title: "Hours spent on reviews?",
+				Type:  model.PollTypeNumeric,
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.Range != nil && p.Range.Min == 0 && p.Range.Max == 100 && p.Range.Step == 1 && p.Range.Buckets == 10
+				}), mock.AnythingOfType("time.Duration")).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "numeric poll with too many steps",
+			request: &model.CreatePollRequest{
+				Title: "Hours spent on reviews?",
+				Type:  model.PollTypeNumeric,
+				Range: &model.NumericRange{Min: 0, Max: 100, Step: 0.001},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "numeric poll with negative step",
+			request: &model.CreatePollRequest{
+				Title: "Hours spent on reviews?",
+				Type:  model.PollTypeNumeric,
+				Range: &model.NumericRange{Min: 0, Max: 10, Step: -1},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "range for score poll",
+			request: &model.CreatePollRequest{
+				Title:   "Rate talks",
+				Type:    model.PollTypeScore,
+				Range:   &model.NumericRange{Min: 0, Max: 10, Step: 1},
+				Options: []string{"Keynote", "Workshop"},
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
+		{
+			name: "max length above limit",
+			request: &model.CreatePollRequest{
+				Title:     "Any feedback?",
+				Type:      model.PollTypeText,
+				MaxLength: 100000,
+			},
+			setupMock: func(m *MockStorage) {},
+			wantErr:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -195,7 +867,7 @@ func TestPollService_Vote(t *testing.T) {
 				OptionIndices: []int{0},
 			},
 			setupMock: func(m *MockStorage) {
-				m.On("Vote", mock.Anything, "test123", []int{0}).
+				m.On("Vote", mock.Anything, "test123", &model.VoteRequest{OptionIndices: []int{0}}, mock.Anything, mock.Anything).
 					Return(nil)
 			},
 			expectedError: nil,
@@ -207,7 +879,7 @@ func TestPollService_Vote(t *testing.T) {
 				OptionIndices: []int{0, 2, 3},
 			},
 			setupMock: func(m *MockStorage) {
-				m.On("Vote", mock.Anything, "test123", []int{0, 2, 3}).
+				m.On("Vote", mock.Anything, "test123", &model.VoteRequest{OptionIndices: []int{0, 2, 3}}, mock.Anything, mock.Anything).
 					Return(nil)
 			},
 			expectedError: nil,
@@ -219,7 +891,7 @@ func TestPollService_Vote(t *testing.T) {
 				OptionIndices: []int{0, 1, 2, 3, 4},
 			},
 			setupMock: func(m *MockStorage) {
-				m.On("Vote", mock.Anything, "test123", []int{0, 1, 2, 3, 4}).
+				m.On("Vote", mock.Anything, "test123", &model.VoteRequest{OptionIndices: []int{0, 1, 2, 3, 4}}, mock.Anything, mock.Anything).
 					Return(nil)
 			},
 			expectedError: nil,
@@ -231,7 +903,7 @@ func TestPollService_Vote(t *testing.T) {
 				OptionIndices: []int{0},
 			},
 			setupMock: func(m *MockStorage) {
-				m.On("Vote", mock.Anything, "nonexistent", []int{0}).
+				m.On("Vote", mock.Anything, "nonexistent", &model.VoteRequest{OptionIndices: []int{0}}, mock.Anything, mock.Anything).
 					Return(storage.ErrPollNotFound)
 			},
 			expectedError: storage.ErrPollNotFound,
@@ -243,7 +915,7 @@ func TestPollService_Vote(t *testing.T) {
 				OptionIndices: []int{999},
 			},
 			setupMock: func(m *MockStorage) {
-				m.On("Vote", mock.Anything, "test123", []int{999}).
+				m.On("Vote", mock.Anything, "test123", &model.VoteRequest{OptionIndices: []int{999}}, mock.Anything, mock.Anything).
 					Return(storage.ErrInvalidOption)
 			},
 			expectedError: storage.ErrInvalidOption,
@@ -255,7 +927,7 @@ func TestPollService_Vote(t *testing.T) {
 				OptionIndices: []int{0, 0, 1},
 			},
 			setupMock: func(m *MockStorage) {
-				m.On("Vote", mock.Anything, "test123", []int{0, 0, 1}).
+				m.On("Vote", mock.Anything, "test123", &model.VoteRequest{OptionIndices: []int{0, 0, 1}}, mock.Anything, mock.Anything).
 					Return(storage.ErrDuplicateOption)
 			},
 			expectedError: storage.ErrDuplicateOption,
@@ -267,11 +939,83 @@ func TestPollService_Vote(t *testing.T) {
 				OptionIndices: []int{0},
 			},
 			setupMock: func(m *MockStorage) {
-				m.On("Vote", mock.Anything, "test123", []int{0}).
+				m.On("Vote", mock.Anything, "test123", &model.VoteRequest{OptionIndices: []int{0}}, mock.Anything, mock.Anything).
 					Return(errors.New("storage error"))
 			},
 			expectedError: errors.New("storage error"),
 		},
+		{
+			name:   "successful ranked ballot",
+			pollID: "ranked123",
+			request: &model.VoteRequest{
+				Ranking: []int{2, 0, 1},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("Vote", mock.Anything, "ranked123", &model.VoteRequest{Ranking: []int{2, 0, 1}}, mock.Anything, mock.Anything).
+					Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "ranking sent to choice poll",
+			pollID: "test123",
+			request: &model.VoteRequest{
+				Ranking: []int{1, 0},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("Vote", mock.Anything, "test123", &model.VoteRequest{Ranking: []int{1, 0}}, mock.Anything, mock.Anything).
+					Return(storage.ErrVoteTypeMismatch)
+			},
+			expectedError: storage.ErrVoteTypeMismatch,
+		},
+		{
+			name:   "score out of scale",
+			pollID: "score1",
+			request: &model.VoteRequest{
+				Scores: map[int]int{0: 9},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("Vote", mock.Anything, "score1", &model.VoteRequest{Scores: map[int]int{0: 9}}, mock.Anything, mock.Anything).
+					Return(storage.ErrInvalidScore)
+			},
+			expectedError: storage.ErrInvalidScore,
+		},
+		{
+			name:   "points over budget",
+			pollID: "budget1",
+			request: &model.VoteRequest{
+				Points: map[int]int{0: 8, 1: 8},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("Vote", mock.Anything, "budget1", &model.VoteRequest{Points: map[int]int{0: 8, 1: 8}}, mock.Anything, mock.Anything).
+					Return(storage.ErrBudgetExceeded)
+			},
+			expectedError: storage.ErrBudgetExceeded,
+		},
+		{
+			name:   "text answer is sanitized",
+			pollID: "text1",
+			request: &model.VoteRequest{
+				Text: "  Great\u200b   talk!\r\n\r\n\r\nMore demos\u202e please ",
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("Vote", mock.Anything, "text1", &model.VoteRequest{Text: "Great talk!\n\nMore demos please"}, mock.Anything, mock.Anything).
+					Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "text answer too long",
+			pollID: "text1",
+			request: &model.VoteRequest{
+				Text: "Way too long",
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("Vote", mock.Anything, "text1", &model.VoteRequest{Text: "Way too long"}, mock.Anything, mock.Anything).
+					Return(storage.ErrTextTooLong)
+			},
+			expectedError: storage.ErrTextTooLong,
+		},
 		{
 			name:   "vote with empty indices",
 			pollID: "test123",
@@ -279,7 +1023,7 @@ func TestPollService_Vote(t *testing.T) {
 				OptionIndices: []int{},
 			},
 			setupMock: func(m *MockStorage) {
-				m.On("Vote", mock.Anything, "test123", []int{}).
+				m.On("Vote", mock.Anything, "test123", &model.VoteRequest{OptionIndices: []int{}}, mock.Anything, mock.Anything).
 					Return(nil)
 			},
 			expectedError: nil,
@@ -290,6 +1034,7 @@ func TestPollService_Vote(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Arrange
 			mockStorage := new(MockStorage)
+			mockStorage.On("GetPoll", mock.Anything, tt.pollID).Return(&model.Poll{ID: tt.pollID}, nil)
 			tt.setupMock(mockStorage)
 
 			cfg := newTestConfig()
@@ -298,14 +1043,18 @@ func TestPollService_Vote(t *testing.T) {
 			ctx := context.Background()
 
 			// Act
-			err := service.Vote(ctx, tt.pollID, tt.request)
+			_, err := service.Vote(ctx, tt.pollID, tt.request, "", &model.PollAccess{})
 
 			// Assert
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 				if errors.Is(tt.expectedError, storage.ErrPollNotFound) ||
 					errors.Is(tt.expectedError, storage.ErrInvalidOption) ||
-					errors.Is(tt.expectedError, storage.ErrDuplicateOption) {
+					errors.Is(tt.expectedError, storage.ErrDuplicateOption) ||
+					errors.Is(tt.expectedError, storage.ErrVoteTypeMismatch) ||
+					errors.Is(tt.expectedError, storage.ErrInvalidScore) ||
+					errors.Is(tt.expectedError, storage.ErrBudgetExceeded) ||
+					errors.Is(tt.expectedError, storage.ErrTextTooLong) {
 					assert.ErrorIs(t, err, tt.expectedError)
 				}
 			} else {
@@ -405,6 +1154,228 @@ func TestPollService_GetResults(t *testing.T) {
 				assert.True(t, res.Votes["Popular"] > res.Votes["Unpopular"])
 			},
 		},
+		{
+			name:   "ranked poll includes runoff",
+			pollID: "ranked123",
+			setupMock: func(m *MockStorage) {
+				results := &model.PollResults{
+					Poll: model.Poll{
+						ID:      "ranked123",
+						Title:   "Team name?",
+						Type:    model.PollTypeRanked,
+						Options: []string{"Owls", "Foxes", "Bears"},
+					},
+					Votes: map[string]int{
+						"Owls":  2,
+						"Foxes": 2,
+						"Bears": 1,
+					},
+					Total: 5,
+				}
+				ballots := [][]int{{0, 2}, {0}, {1, 0}, {1}, {2, 1}}
+				m.On("GetResults", mock.Anything, "ranked123").
+					Return(results, nil)
+				m.On("GetBallots", mock.Anything, "ranked123").
+					Return(ballots, nil)
+			},
+			expectedError: nil,
+			validateRes: func(t *testing.T, res *model.PollResults) {
+				require.NotNil(t, res.Runoff)
+				assert.Equal(t, 5, res.Runoff.Ballots)
+				assert.Len(t, res.Runoff.Rounds, 2)
+				assert.Equal(t, "Bears", res.Runoff.Rounds[0].Eliminated)
+				assert.Equal(t, "Foxes", res.Runoff.Winner)
+			},
+		},
+		{
+			name:   "ranked poll with schulze tabulation",
+			pollID: "schulze1",
+			setupMock: func(m *MockStorage) {
+				results := &model.PollResults{
+					Poll: model.Poll{
+						ID:         "schulze1",
+						Type:       model.PollTypeRanked,
+						Tabulation: model.TabulationSchulze,
+						Options:    []string{"A", "B", "C"},
+					},
+					Votes: map[string]int{"A": 2, "B": 1, "C": 0},
+					Total: 3,
+				}
+				m.On("GetResults", mock.Anything, "schulze1").
+					Return(results, nil)
+				m.On("GetBallots", mock.Anything, "schulze1").
+					Return([][]int{{0, 1}, {0, 2}, {1, 2}}, nil)
+			},
+			expectedError: nil,
+			validateRes: func(t *testing.T, res *model.PollResults) {
+				assert.Nil(t, res.Runoff)
+				require.NotNil(t, res.Schulze)
+				assert.Equal(t, "A", res.Schulze.Winner)
+				assert.Equal(t, [][]string{{"A"}, {"B"}, {"C"}}, res.Schulze.Ranking)
+			},
+		},
+		{
+			name:   "score poll includes statistics",
+			pollID: "score1",
+			setupMock: func(m *MockStorage) {
+				results := &model.PollResults{
+					Poll: model.Poll{
+						ID:      "score1",
+						Type:    model.PollTypeScore,
+						Scale:   &model.ScoreScale{Min: 1, Max: 5},
+						Options: []string{"Keynote", "Workshop"},
+					},
+					Votes: map[string]int{"Keynote": 3, "Workshop": 0},
+					Total: 3,
+				}
+				scores := map[int]model.ScoreStats{
+					0: {Count: 3, Sum: 11, Distribution: map[int]int{2: 1, 4: 1, 5: 1}},
+				}
+				m.On("GetResults", mock.Anything, "score1").
+					Return(results, nil)
+				m.On("GetScores", mock.Anything, "score1").
+					Return(scores, nil)
+			},
+			expectedError: nil,
+			validateRes: func(t *testing.T, res *model.PollResults) {
+				require.Len(t, res.Scores, 2)
+
+				keynote := res.Scores["Keynote"]
+				assert.Equal(t, 3, keynote.Count)
+				assert.InDelta(t, 11.0/3, keynote.Mean, 1e-9)
+				assert.Equal(t, 4.0, keynote.Median)
+				assert.Equal(t, map[int]int{1: 0, 2: 1, 3: 0, 4: 1, 5: 1}, keynote.Distribution)
+
+				workshop := res.Scores["Workshop"]
+				assert.Equal(t, 0, workshop.Count)
+				assert.Equal(t, 0.0, workshop.Mean)
+				assert.Len(t, workshop.Distribution, 5)
+			},
+		},
+		{
+			name:   "numeric poll includes statistics",
+			pollID: "numeric1",
+			setupMock: func(m *MockStorage) {
+				results := &model.PollResults{
+					Poll: model.Poll{
+						ID:    "numeric1",
+						Type:  model.PollTypeNumeric,
+						Range: &model.NumericRange{Min: 0, Max: 8, Step: 2, Buckets: 10},
+					},
+					Votes: map[string]int{},
+				}
+				m.On("GetResults", mock.Anything, "numeric1").
+					Return(results, nil)
+				m.On("GetNumbers", mock.Anything, "numeric1").
+					Return(map[int]int{1: 2, 4: 1}, nil)
+			},
+			expectedError: nil,
+			validateRes: func(t *testing.T, res *model.PollResults) {
+				require.NotNil(t, res.Numeric)
+				assert.Equal(t, 3, res.Total)
+				assert.Equal(t, 3, res.Numeric.Count)
+				assert.InDelta(t, 4.0, res.Numeric.Mean, 1e-9)
+				assert.Equal(t, 2.0, res.Numeric.Median)
+				assert.Len(t, res.Numeric.Histogram, 5)
+			},
+		},
+		{
+			name:   "nps poll includes score",
+			pollID: "nps1",
+			setupMock: func(m *MockStorage) {
+				results := &model.PollResults{
+					Poll: model.Poll{
+						ID:      "nps1",
+						Type:    model.PollTypeNPS,
+						Options: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10"},
+					},
+					Votes: map[string]int{"0": 1, "1": 0, "2": 0, "3": 0, "4": 0, "5": 0, "6": 0, "7": 0, "8": 1, "9": 1, "10": 1},
+					Total: 4,
+				}
+				m.On("GetResults", mock.Anything, "nps1").
+					Return(results, nil)
+			},
+			expectedError: nil,
+			validateRes: func(t *testing.T, res *model.PollResults) {
+				require.NotNil(t, res.NPS)
+				assert.Equal(t, 4, res.NPS.Responses)
+				assert.InDelta(t, 25, res.NPS.Score, 1e-9)
+				assert.NotNil(t, res.NPS.Confidence)
+			},
+		},
+		{
+			name:   "matrix poll includes row statistics",
+			pollID: "matrix1",
+			setupMock: func(m *MockStorage) {
+				results := &model.PollResults{
+					Poll: model.Poll{
+						ID:      "matrix1",
+						Type:    model.PollTypeMatrix,
+						Columns: []string{"Disagree", "Neutral", "Agree"},
+						Options: []string{"Docs are clear", "Builds are fast"},
+					},
+					Votes: map[string]int{"Docs are clear": 2, "Builds are fast": 1},
+					Total: 3,
+				}
+				m.On("GetResults", mock.Anything, "matrix1").
+					Return(results, nil)
+				m.On("GetMatrix", mock.Anything, "matrix1").
+					Return(map[int]map[int]int{0: {0: 1, 2: 1}, 1: {1: 1}}, nil)
+			},
+			expectedError: nil,
+			validateRes: func(t *testing.T, res *model.PollResults) {
+				require.Len(t, res.Matrix, 2)
+				assert.InDelta(t, 2.0, res.Matrix["Docs are clear"].Mean, 1e-9)
+				assert.Equal(t, 1, res.Matrix["Builds are fast"].Distribution["Neutral"])
+			},
+		},
+		{
+			name:   "word cloud poll includes top terms",
+			pollID: "cloud1",
+			setupMock: func(m *MockStorage) {
+				results := &model.PollResults{
+					Poll: model.Poll{
+						ID:       "cloud1",
+						Type:     model.PollTypeWordCloud,
+						TopTerms: 2,
+					},
+					Votes: map[string]int{},
+				}
+				m.On("GetResults", mock.Anything, "cloud1").
+					Return(results, nil)
+				m.On("GetTerms", mock.Anything, "cloud1", 2).
+					Return(&model.WordCloudResults{
+						Answers:  5,
+						Distinct: 3,
+						Terms:    []model.TermCount{{Term: "chaotic", Count: 3}, {Term: "calm", Count: 1}},
+					}, nil)
+			},
+			expectedError: nil,
+			validateRes: func(t *testing.T, res *model.PollResults) {
+				require.NotNil(t, res.Words)
+				assert.Equal(t, 5, res.Total)
+				assert.Equal(t, 3, res.Words.Distinct)
+				assert.Equal(t, "chaotic", res.Words.Terms[0].Term)
+			},
+		},
+		{
+			name:   "ranked poll ballots error",
+			pollID: "ranked123",
+			setupMock: func(m *MockStorage) {
+				results := &model.PollResults{
+					Poll: model.Poll{
+						ID:      "ranked123",
+						Type:    model.PollTypeRanked,
+						Options: []string{"A", "B"},
+					},
+				}
+				m.On("GetResults", mock.Anything, "ranked123").
+					Return(results, nil)
+				m.On("GetBallots", mock.Anything, "ranked123").
+					Return(nil, errors.New("storage error"))
+			},
+			expectedError: errors.New("storage error"),
+		},
 		{
 			name:   "poll not found",
 			pollID: "nonexistent",
@@ -484,7 +1455,8 @@ func BenchmarkPollService_CreatePoll(b *testing.B) {
 
 func BenchmarkPollService_Vote_SingleOption(b *testing.B) {
 	mockStorage := new(MockStorage)
-	mockStorage.On("Vote", mock.Anything, mock.Anything, mock.Anything).
+	mockStorage.On("GetPoll", mock.Anything, "test123").Return(&model.Poll{ID: "test123"}, nil)
+	mockStorage.On("Vote", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(nil)
 
 	cfg := newTestConfig()
@@ -498,13 +1470,14 @@ func BenchmarkPollService_Vote_SingleOption(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = service.Vote(ctx, "test123", req)
+		_, _ = service.Vote(ctx, "test123", req, "", &model.PollAccess{})
 	}
 }
 
 func BenchmarkPollService_Vote_MultipleOptions(b *testing.B) {
 	mockStorage := new(MockStorage)
-	mockStorage.On("Vote", mock.Anything, mock.Anything, mock.Anything).
+	mockStorage.On("GetPoll", mock.Anything, "test123").Return(&model.Poll{ID: "test123"}, nil)
+	mockStorage.On("Vote", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(nil)
 
 	cfg := newTestConfig()
@@ -518,7 +1491,7 @@ func BenchmarkPollService_Vote_MultipleOptions(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = service.Vote(ctx, "test123", req)
+		_, _ = service.Vote(ctx, "test123", req, "", &model.PollAccess{})
 	}
 }
 
@@ -577,7 +1550,8 @@ func TestPollService_EdgeCases(t *testing.T) {
 
 	t.Run("concurrent votes on same poll", func(t *testing.T) {
 		mockStorage := new(MockStorage)
-		mockStorage.On("Vote", mock.Anything, mock.Anything, mock.Anything).
+		mockStorage.On("GetPoll", mock.Anything, "test123").Return(&model.Poll{ID: "test123"}, nil)
+		mockStorage.On("Vote", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 			Return(nil)
 
 		cfg := newTestConfig()
@@ -594,7 +1568,7 @@ func TestPollService_EdgeCases(t *testing.T) {
 				req := &model.VoteRequest{
 					OptionIndices: []int{index % 3}, // Rotate through options
 				}
-				_ = service.Vote(ctx, "test123", req)
+				_, _ = service.Vote(ctx, "test123", req, "", &model.PollAccess{})
 				done <- true
 			}(i)
 		}
@@ -611,7 +1585,8 @@ func TestPollService_EdgeCases(t *testing.T) {
 	t.Run("vote with maximum allowed options", func(t *testing.T) {
 		mockStorage := new(MockStorage)
 		maxIndices := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
-		mockStorage.On("Vote", mock.Anything, "test123", maxIndices).
+		mockStorage.On("GetPoll", mock.Anything, "test123").Return(&model.Poll{ID: "test123"}, nil)
+		mockStorage.On("Vote", mock.Anything, "test123", &model.VoteRequest{OptionIndices: maxIndices}, mock.Anything, mock.Anything).
 			Return(nil)
 
 		cfg := newTestConfig()
@@ -623,7 +1598,7 @@ func TestPollService_EdgeCases(t *testing.T) {
 			OptionIndices: maxIndices,
 		}
 
-		err := service.Vote(ctx, "test123", req)
+		_, err := service.Vote(ctx, "test123", req, "", &model.PollAccess{})
 		assert.NoError(t, err)
 		mockStorage.AssertExpectations(t)
 	})
@@ -675,17 +1650,18 @@ func TestPollService_RealisticScenarios(t *testing.T) {
 		pollID := createResp.PollID
 
 		// 2. Multiple users vote
-		mockStorage.On("Vote", mock.Anything, pollID, []int{0}).Return(nil).Once()
-		mockStorage.On("Vote", mock.Anything, pollID, []int{1}).Return(nil).Once()
-		mockStorage.On("Vote", mock.Anything, pollID, []int{0, 2}).Return(nil).Once()
+		mockStorage.On("GetPoll", mock.Anything, pollID).Return(&model.Poll{ID: pollID}, nil).Times(3)
+		mockStorage.On("Vote", mock.Anything, pollID, &model.VoteRequest{OptionIndices: []int{0}}, mock.Anything, mock.Anything).Return(nil).Once()
+		mockStorage.On("Vote", mock.Anything, pollID, &model.VoteRequest{OptionIndices: []int{1}}, mock.Anything, mock.Anything).Return(nil).Once()
+		mockStorage.On("Vote", mock.Anything, pollID, &model.VoteRequest{OptionIndices: []int{0, 2}}, mock.Anything, mock.Anything).Return(nil).Once()
 
-		err = service.Vote(ctx, pollID, &model.VoteRequest{OptionIndices: []int{0}})
+		_, err = service.Vote(ctx, pollID, &model.VoteRequest{OptionIndices: []int{0}}, "", &model.PollAccess{})
 		assert.NoError(t, err)
 
-		err = service.Vote(ctx, pollID, &model.VoteRequest{OptionIndices: []int{1}})
+		_, err = service.Vote(ctx, pollID, &model.VoteRequest{OptionIndices: []int{1}}, "", &model.PollAccess{})
 		assert.NoError(t, err)
 
-		err = service.Vote(ctx, pollID, &model.VoteRequest{OptionIndices: []int{0, 2}})
+		_, err = service.Vote(ctx, pollID, &model.VoteRequest{OptionIndices: []int{0, 2}}, "", &model.PollAccess{})
 		assert.NoError(t, err)
 
 		// 3. Get results
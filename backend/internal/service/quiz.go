@@ -0,0 +1,30 @@
+package service
+
+import (
+	"github.com/AlexeyLars/surway-service/internal/model"
+)
+
+// quizStatistics fills maximum and mean score of quiz and correct answer rate of its graded questions
+func quizStatistics(results *model.SurveyResults) {
+	questions := results.Survey.Questions
+	for n := range questions {
+		if !questions[n].Graded() {
+			continue
+		}
+		results.Quiz.MaxScore += questions[n].Points
+
+		quiz := results.Questions[n].Quiz
+		if quiz != nil && quiz.Answered > 0 {
+			quiz.Rate = float64(quiz.Correct) / float64(quiz.Answered)
+		}
+	}
+
+	submissions, sum := 0, 0
+	for score, count := range results.Quiz.Distribution {
+		submissions += count
+		sum += score * count
+	}
+	if submissions > 0 {
+		results.Quiz.Mean = float64(sum) / float64(submissions)
+	}
+}
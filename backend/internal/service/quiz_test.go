@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQuiz(expiresAt time.Time) *model.Survey {
+	return &model.Survey{
+		ID:    "survey1",
+		Title: "Onboarding check",
+		Questions: []model.Question{
+			{Title: "Where are secrets stored?", Type: model.PollTypeChoice, Options: []string{"Vault", "Wiki", "Repo"}, Correct: []int{0}, Points: 2},
+			{Title: "Which branches are protected?", Type: model.PollTypeChoice, Options: []string{"main", "release", "feature"}, Correct: []int{0, 1}, Points: 1},
+			{Title: "How was the training?", Type: model.PollTypeText, MaxLength: 500},
+		},
+		ExpiresAt: expiresAt,
+	}
+}
+
+func TestPollService_CreateSurvey_Quiz(t *testing.T) {
+	tests := []struct {
+		name     string
+		question model.Question
+		wantErr  bool
+		points   int
+	}{
+		{name: "points default to one", question: model.Question{Title: "Q", Options: []string{"A", "B"}, Correct: []int{1}}, points: 1},
+		{name: "custom points", question: model.Question{Title: "Q", Options: []string{"A", "B"}, Correct: []int{0, 1}, Points: 5}, points: 5},
+		{name: "correct option out of range", question: model.Question{Title: "Q", Options: []string{"A", "B"}, Correct: []int{2}}, wantErr: true},
+		{name: "duplicate correct option", question: model.Question{Title: "Q", Options: []string{"A", "B"}, Correct: []int{0, 0}}, wantErr: true},
+		{name: "correct options of ranked question", question: model.Question{Title: "Q", Type: model.PollTypeRanked, Options: []string{"A", "B"}, Correct: []int{0}}, wantErr: true},
+		{name: "points without correct options", question: model.Question{Title: "Q", Options: []string{"A", "B"}, Points: 3}, wantErr: true},
+		{name: "too many points", question: model.Question{Title: "Q", Options: []string{"A", "B"}, Correct: []int{0}, Points: 101}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			if !tt.wantErr {
+				mockStorage.On("CreateSurvey", mock.Anything, mock.MatchedBy(func(s *model.Survey) bool {
+					return s.Questions[0].Points == tt.points
+				}), mock.AnythingOfType("time.Duration")).Return(nil)
+			}
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			_, err := service.CreateSurvey(context.Background(), &model.CreateSurveyRequest{
+				Title:     "Onboarding check",
+				Questions: []model.Question{tt.question},
+			})
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidPollConfig)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_SubmitSurvey_Quiz(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []model.Answer
+		score   int
+		correct []int
+	}{
+		{
+			name:    "all correct",
+			answers: []model.Answer{{Question: 0, OptionIndices: []int{0}}, {Question: 1, OptionIndices: []int{1, 0}}},
+			score:   3,
+			correct: []int{0, 1},
+		},
+		{
+			name:    "partial selection is wrong",
+			answers: []model.Answer{{Question: 0, OptionIndices: []int{0}}, {Question: 1, OptionIndices: []int{0}}},
+			score:   2,
+			correct: []int{0},
+		},
+		{
+			name:    "extra selection is wrong",
+			answers: []model.Answer{{Question: 1, OptionIndices: []int{0, 1, 2}}},
+			score:   0,
+			correct: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			mockStorage.On("GetSurvey", mock.Anything, "survey1").Return(newTestQuiz(time.Now().Add(time.Hour)), nil)
+			mockStorage.On("SubmitSurvey", mock.Anything, "survey1", mock.Anything, mock.Anything).Return(nil)
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			score, err := service.SubmitSurvey(context.Background(), "survey1", &model.SubmitSurveyRequest{Answers: tt.answers}, "", &model.PollAccess{})
+
+			require.NoError(t, err)
+			require.NotNil(t, score)
+			assert.Equal(t, tt.score, score.Score)
+			assert.Equal(t, 3, score.MaxScore)
+			assert.Equal(t, tt.correct, score.Correct)
+		})
+	}
+
+	t.Run("survey without correct options has no score", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetSurvey", mock.Anything, "survey1").Return(newTestSurvey(), nil)
+		mockStorage.On("SubmitSurvey", mock.Anything, "survey1", mock.Anything, mock.Anything).Return(nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		score, err := service.SubmitSurvey(context.Background(), "survey1", &model.SubmitSurveyRequest{
+			Answers: []model.Answer{{Question: 0, Scores: map[int]int{0: 3}}},
+		}, "", &model.PollAccess{})
+
+		require.NoError(t, err)
+		assert.Nil(t, score)
+	})
+}
+
+func TestPollService_GetSurvey_HidesCorrectAnswers(t *testing.T) {
+	t.Run("open quiz", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetSurvey", mock.Anything, "survey1").Return(newTestQuiz(time.Now().Add(time.Hour)), nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		definition, err := service.GetSurvey(context.Background(), "survey1")
+
+		require.NoError(t, err)
+		assert.Nil(t, definition.Questions[0].Correct)
+		assert.Nil(t, definition.Questions[1].Correct)
+		assert.Equal(t, 2, definition.Questions[0].Points)
+	})
+
+	t.Run("closed quiz", func(t *testing.T) {
+		quiz := newTestQuiz(time.Now().Add(time.Hour))
+		closesAt := time.Now().Add(-time.Minute)
+		quiz.ClosesAt = &closesAt
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetSurvey", mock.Anything, "survey1").Return(quiz, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		definition, err := service.GetSurvey(context.Background(), "survey1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, 1}, definition.Questions[1].Correct)
+	})
+}
+
+func TestPollService_CreateSurvey_ClosingTime(t *testing.T) {
+	questions := []model.Question{{Title: "Q", Options: []string{"A", "B"}, Correct: []int{0}}}
+	tests := []struct {
+		name     string
+		closesAt time.Time
+		wantErr  bool
+	}{
+		{name: "before expiry", closesAt: time.Now().Add(time.Hour)},
+		{name: "in the past", closesAt: time.Now().Add(-time.Minute), wantErr: true},
+		{name: "after expiry", closesAt: time.Now().Add(200 * time.Hour), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			mockStorage.On("CreateSurvey", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			_, err := service.CreateSurvey(context.Background(), &model.CreateSurveyRequest{Title: "Quiz", Questions: questions, Schedule: model.Schedule{ClosesAt: &tt.closesAt}})
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidPollConfig)
+				mockStorage.AssertNotCalled(t, "CreateSurvey", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPollService_GetSurveyResults_Quiz(t *testing.T) {
+	mockStorage := new(MockStorage)
+	mockStorage.On("GetSurveyResults", mock.Anything, "survey1").Return(&model.SurveyResults{
+		Survey:    *newTestQuiz(time.Now().Add(time.Hour)),
+		Responses: 4,
+		Quiz:      &model.QuizResults{Distribution: map[int]int{0: 1, 2: 1, 3: 2}},
+		Questions: []model.QuestionResults{
+			{Question: 0, Votes: map[string]int{"Vault": 3, "Wiki": 1, "Repo": 0}, Total: 4, Quiz: &model.QuestionQuiz{Answered: 4, Correct: 3}},
+			{Question: 1, Votes: map[string]int{"main": 3, "release": 2, "feature": 1}, Total: 6, Quiz: &model.QuestionQuiz{Answered: 3, Correct: 2}},
+			{Question: 2, Votes: map[string]int{}, Total: 1},
+		},
+	}, nil)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	res, err := service.GetSurveyResults(context.Background(), "survey1")
+
+	require.NoError(t, err)
+	require.NotNil(t, res.Quiz)
+	assert.Equal(t, 3, res.Quiz.MaxScore)
+	assert.InDelta(t, 2.0, res.Quiz.Mean, 1e-9)
+	assert.InDelta(t, 0.75, res.Questions[0].Quiz.Rate, 1e-9)
+	assert.InDelta(t, 2.0/3, res.Questions[1].Quiz.Rate, 1e-9)
+	assert.Nil(t, res.Questions[2].Quiz)
+	// Rates are shown while quiz is open, correct options are not
+	assert.Nil(t, res.Survey.Questions[0].Correct)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/lib/random"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"log/slog"
+	"time"
+)
+
+// receiptAlgorithm is signature algorithm of vote receipts
+const receiptAlgorithm = "ed25519"
+
+// newReceipt returns receipt of ballot signed with receipt key.
+// Ballot hash is salted with nonce, so it can't be matched against possible ballots.
+func (s *PollService) newReceipt(pollID string, req *model.VoteRequest) (*model.VoteReceipt, error) {
+	nonce := random.NewToken()
+	hash, err := ballotHash(nonce, req)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &model.VoteReceipt{
+		ID:         random.NewToken(),
+		PollID:     pollID,
+		BallotHash: hash,
+		Timestamp:  time.Now().UTC(),
+		Nonce:      nonce,
+	}
+	receipt.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.receiptKey, receiptMessage(receipt)))
+	return receipt, nil
+}
+
+// ballotHash returns hex SHA-256 of nonce and ballot
+func ballotHash(nonce string, req *model.VoteRequest) (string, error) {
+	ballot, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal ballot: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(nonce+"\n"), ballot...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// receiptMessage returns signed part of receipt, it is "<poll>\n<ballot hash>\n<timestamp>"
+func receiptMessage(receipt *model.VoteReceipt) []byte {
+	return []byte(receipt.PollID + "\n" + receipt.BallotHash + "\n" + receipt.Timestamp.UTC().Format(time.RFC3339Nano))
+}
+
+// verifyReceipt reports whether receipt is signed with key
+func verifyReceipt(key ed25519.PublicKey, receipt *model.VoteReceipt) bool {
+	signature, err := base64.StdEncoding.DecodeString(receipt.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(key, receiptMessage(receipt), signature)
+}
+
+// GetReceipt returns stored receipt of vote counted in poll with result of its signature check.
+// Receipt carries only ballot hash, so ballot content is not revealed.
+func (s *PollService) GetReceipt(ctx context.Context, pollID, receiptID string) (*model.ReceiptStatus, error) {
+	receipt, err := s.storage.GetReceipt(ctx, pollID, receiptID)
+	if err != nil {
+		if err == storage.ErrReceiptNotFound {
+			s.logger.WarnContext(ctx, "unknown receipt requested",
+				slog.String("poll_id", pollID),
+			)
+			return nil, err
+		}
+		s.logger.ErrorContext(ctx, "failed to get receipt",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	return &model.ReceiptStatus{
+		VoteReceipt: *receipt,
+		Counted:     true,
+		Valid:       verifyReceipt(s.receiptKey.Public().(ed25519.PublicKey), receipt),
+	}, nil
+}
+
+// ReceiptKey returns public key verifying vote receipts
+func (s *PollService) ReceiptKey() *model.ReceiptKey {
+	return &model.ReceiptKey{
+		Algorithm: receiptAlgorithm,
+		PublicKey: base64.StdEncoding.EncodeToString(s.receiptKey.Public().(ed25519.PublicKey)),
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptSignature(t *testing.T) {
+	service := NewPollService(new(MockStorage), newTestConfig(), newTestLogger())
+	key := service.receiptKey.Public().(ed25519.PublicKey)
+
+	receipt, err := service.newReceipt("poll1", &model.VoteRequest{OptionIndices: []int{1}})
+	require.NoError(t, err)
+
+	assert.True(t, verifyReceipt(key, receipt))
+
+	hash, err := ballotHash(receipt.Nonce, &model.VoteRequest{OptionIndices: []int{1}})
+	require.NoError(t, err)
+	assert.Equal(t, hash, receipt.BallotHash, "voter recomputes ballot hash from nonce")
+
+	other, err := ballotHash(receipt.Nonce, &model.VoteRequest{OptionIndices: []int{0}})
+	require.NoError(t, err)
+	assert.NotEqual(t, other, receipt.BallotHash)
+
+	tampered := *receipt
+	tampered.BallotHash = other
+	assert.False(t, verifyReceipt(key, &tampered), "tampered ballot hash")
+
+	tampered = *receipt
+	tampered.PollID = "poll2"
+	assert.False(t, verifyReceipt(key, &tampered), "receipt of other poll")
+
+	tampered = *receipt
+	tampered.Timestamp = receipt.Timestamp.Add(time.Second)
+	assert.False(t, verifyReceipt(key, &tampered), "tampered timestamp")
+
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	assert.False(t, verifyReceipt(otherKey, receipt), "receipt signed with other key")
+}
+
+func TestPollService_ReceiptKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	cfg := newTestConfig()
+	cfg.Receipt = config.ReceiptConfig{SigningKey: base64.StdEncoding.EncodeToString(seed)}
+
+	first := NewPollService(new(MockStorage), cfg, newTestLogger())
+	second := NewPollService(new(MockStorage), cfg, newTestLogger())
+
+	key := first.ReceiptKey()
+	assert.Equal(t, "ed25519", key.Algorithm)
+	assert.Equal(t, key.PublicKey, second.ReceiptKey().PublicKey, "configured key survives restart")
+	assert.NotEqual(t, key.PublicKey, NewPollService(new(MockStorage), newTestConfig(), newTestLogger()).ReceiptKey().PublicKey)
+}
+
+func TestPollService_Vote_Receipt(t *testing.T) {
+	mockStorage := new(MockStorage)
+	var stored *model.VoteReceipt
+	mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&model.Poll{ID: "poll1", Options: []string{"A", "B"}}, nil)
+	mockStorage.On("Vote", mock.Anything, "poll1", mock.Anything, mock.Anything, mock.AnythingOfType("*model.VoteReceipt")).
+		Run(func(args mock.Arguments) { stored = args.Get(4).(*model.VoteReceipt) }).
+		Return(nil)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	receipt, err := service.Vote(context.Background(), "poll1", &model.VoteRequest{OptionIndices: []int{0}}, "", &model.PollAccess{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "poll1", receipt.PollID)
+	assert.NotEmpty(t, receipt.Nonce)
+	assert.True(t, verifyReceipt(service.receiptKey.Public().(ed25519.PublicKey), receipt))
+
+	require.NotNil(t, stored)
+	assert.Empty(t, stored.Nonce, "nonce is not stored")
+	assert.Equal(t, receipt.ID, stored.ID)
+	assert.Equal(t, receipt.Signature, stored.Signature)
+}
+
+func TestPollService_GetReceipt(t *testing.T) {
+	t.Run("counted receipt", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+		receipt, err := service.newReceipt("poll1", &model.VoteRequest{OptionIndices: []int{0}})
+		require.NoError(t, err)
+		receipt.Nonce = ""
+		mockStorage.On("GetReceipt", mock.Anything, "poll1", receipt.ID).Return(receipt, nil)
+
+		status, err := service.GetReceipt(context.Background(), "poll1", receipt.ID)
+
+		require.NoError(t, err)
+		assert.True(t, status.Counted)
+		assert.True(t, status.Valid)
+		assert.Equal(t, receipt.BallotHash, status.BallotHash)
+	})
+
+	t.Run("receipt signed with other key", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		receipt, err := NewPollService(mockStorage, newTestConfig(), newTestLogger()).
+			newReceipt("poll1", &model.VoteRequest{OptionIndices: []int{0}})
+		require.NoError(t, err)
+		mockStorage.On("GetReceipt", mock.Anything, "poll1", receipt.ID).Return(receipt, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		status, err := service.GetReceipt(context.Background(), "poll1", receipt.ID)
+
+		require.NoError(t, err)
+		assert.True(t, status.Counted)
+		assert.False(t, status.Valid)
+	})
+
+	t.Run("unknown receipt", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetReceipt", mock.Anything, "poll1", "missing").Return(nil, storage.ErrReceiptNotFound)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.GetReceipt(context.Background(), "poll1", "missing")
+
+		assert.ErrorIs(t, err, storage.ErrReceiptNotFound)
+	})
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"github.com/AlexeyLars/surway-service/internal/model"
+)
+
+// tabulateRunoff counts ranked ballots using instant-runoff voting.
+// Each round every ballot counts for its most preferred continuing option.
+// Option with majority of non-exhausted ballots wins, otherwise the weakest
+// option is eliminated and its ballots are transferred to next preferences.
+func tabulateRunoff(options []string, ballots [][]int) *model.RunoffResults {
+	results := &model.RunoffResults{
+		Ballots: len(ballots),
+		Rounds:  []model.RunoffRound{},
+	}
+
+	continuing := make([]bool, len(options))
+	for i := range continuing {
+		continuing[i] = true
+	}
+
+	var history [][]int
+	for round := 1; ; round++ {
+		counts := make([]int, len(options))
+		exhausted := 0
+		for _, ballot := range ballots {
+			if top := topPreference(ballot, continuing); top >= 0 {
+				counts[top]++
+			} else {
+				exhausted++
+			}
+		}
+		history = append(history, counts)
+
+		current := model.RunoffRound{
+			Round:     round,
+			Tallies:   make(map[string]int),
+			Exhausted: exhausted,
+		}
+		for i, option := range options {
+			if continuing[i] {
+				current.Tallies[option] = counts[i]
+			}
+		}
+
+		active := len(ballots) - exhausted
+		if active == 0 {
+			results.Rounds = append(results.Rounds, current)
+			return results
+		}
+
+		for i := range options {
+			if continuing[i] && counts[i]*2 > active {
+				results.Rounds = append(results.Rounds, current)
+				results.Winner = options[i]
+				return results
+			}
+		}
+
+		loser := runoffLoser(continuing, history)
+		continuing[loser] = false
+		current.Eliminated = options[loser]
+		results.Rounds = append(results.Rounds, current)
+	}
+}
+
+// topPreference returns most preferred continuing option of ballot or -1 if ballot is exhausted
+func topPreference(ballot []int, continuing []bool) int {
+	for _, idx := range ballot {
+		if idx >= 0 && idx < len(continuing) && continuing[idx] {
+			return idx
+		}
+	}
+	return -1
+}
+
+// runoffLoser picks continuing option with the fewest votes in the last round.
+// Ties are broken by looking back at earlier rounds, and if options are tied
+// in every round the one listed last in the poll is eliminated.
+func runoffLoser(continuing []bool, history [][]int) int {
+	var candidates []int
+	for i, ok := range continuing {
+		if ok {
+			candidates = append(candidates, i)
+		}
+	}
+
+	for r := len(history) - 1; r >= 0 && len(candidates) > 1; r-- {
+		counts := history[r]
+		fewest := counts[candidates[0]]
+		for _, idx := range candidates[1:] {
+			fewest = min(fewest, counts[idx])
+		}
+
+		var weakest []int
+		for _, idx := range candidates {
+			if counts[idx] == fewest {
+				weakest = append(weakest, idx)
+			}
+		}
+		candidates = weakest
+	}
+
+	return candidates[len(candidates)-1]
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTabulateRunoff(t *testing.T) {
+	tests := []struct {
+		name           string
+		options        []string
+		ballots        [][]int
+		wantWinner     string
+		wantRounds     int
+		wantEliminated []string
+	}{
+		{
+			name:       "majority in first round",
+			options:    []string{"A", "B", "C"},
+			ballots:    [][]int{{0, 1}, {0}, {0, 2}, {1, 0}, {2}},
+			wantWinner: "A",
+			wantRounds: 1,
+		},
+		{
+			name:    "transfers decide winner",
+			options: []string{"A", "B", "C"},
+			// A leads first preferences but C voters prefer B
+			ballots: [][]int{
+				{0}, {0}, {0}, {0},
+				{1, 0}, {1}, {1},
+				{2, 1}, {2, 1},
+			},
+			wantWinner:     "B",
+			wantRounds:     2,
+			wantEliminated: []string{"C"},
+		},
+		{
+			name:    "exhausted ballots leave majority of continuing",
+			options: []string{"A", "B", "C"},
+			ballots: [][]int{
+				{0}, {0}, {0},
+				{1}, {1},
+				{2}, {2},
+			},
+			// C and B are tied, B listed earlier in the poll survives
+			wantWinner:     "A",
+			wantRounds:     2,
+			wantEliminated: []string{"C"},
+		},
+		{
+			name:    "tie broken by earlier rounds",
+			options: []string{"A", "B", "C", "D"},
+			ballots: [][]int{
+				{0}, {0}, {0}, {0},
+				{1}, {1}, {1},
+				{2, 1}, {2, 1},
+				{3, 2, 1},
+			},
+			// D out first, then B and C tie but C had fewer votes in round 1
+			wantWinner:     "B",
+			wantRounds:     3,
+			wantEliminated: []string{"D", "C"},
+		},
+		{
+			name:       "no ballots",
+			options:    []string{"A", "B"},
+			ballots:    [][]int{},
+			wantWinner: "",
+			wantRounds: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := tabulateRunoff(tt.options, tt.ballots)
+			require.NotNil(t, res)
+
+			assert.Equal(t, len(tt.ballots), res.Ballots)
+			assert.Equal(t, tt.wantWinner, res.Winner)
+			require.Len(t, res.Rounds, tt.wantRounds)
+
+			var eliminated []string
+			for i, round := range res.Rounds {
+				assert.Equal(t, i+1, round.Round)
+				if round.Eliminated != "" {
+					eliminated = append(eliminated, round.Eliminated)
+				}
+			}
+			assert.Equal(t, tt.wantEliminated, eliminated)
+		})
+	}
+}
+
+func TestTabulateRunoff_RoundTallies(t *testing.T) {
+	options := []string{"A", "B", "C"}
+	ballots := [][]int{{0}, {0}, {1, 2}, {1}, {2, 1}, {2}}
+
+	res := tabulateRunoff(options, ballots)
+	require.Len(t, res.Rounds, 2)
+
+	// All tied in every round: C listed last is eliminated
+	assert.Equal(t, map[string]int{"A": 2, "B": 2, "C": 2}, res.Rounds[0].Tallies)
+	assert.Equal(t, 0, res.Rounds[0].Exhausted)
+	assert.Equal(t, "C", res.Rounds[0].Eliminated)
+
+	// One C ballot transfers to B, the other one is exhausted
+	assert.Equal(t, map[string]int{"A": 2, "B": 3}, res.Rounds[1].Tallies)
+	assert.Equal(t, 1, res.Rounds[1].Exhausted)
+	assert.Empty(t, res.Rounds[1].Eliminated)
+	assert.Equal(t, "B", res.Winner)
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollService_CreatePoll_Schedule(t *testing.T) {
+	at := func(d time.Duration) *time.Time {
+		t := time.Now().Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		schedule model.Schedule
+		wantErr  bool
+	}{
+		{name: "no schedule"},
+		{name: "opens tomorrow", schedule: model.Schedule{OpensAt: at(24 * time.Hour)}},
+		{name: "opens and closes", schedule: model.Schedule{OpensAt: at(time.Hour), ClosesAt: at(2 * time.Hour)}},
+		{name: "opened in the past", schedule: model.Schedule{OpensAt: at(-time.Hour), ClosesAt: at(time.Hour)}},
+		{name: "closes in the past", schedule: model.Schedule{ClosesAt: at(-time.Minute)}, wantErr: true},
+		{name: "closes before opening", schedule: model.Schedule{OpensAt: at(2 * time.Hour), ClosesAt: at(time.Hour)}, wantErr: true},
+		{name: "closes after expiry", schedule: model.Schedule{ClosesAt: at(200 * time.Hour)}, wantErr: true},
+		{name: "opens after expiry", schedule: model.Schedule{OpensAt: at(200 * time.Hour)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			if !tt.wantErr {
+				mockStorage.On("CreatePoll", mock.Anything, mock.MatchedBy(func(p *model.Poll) bool {
+					return p.OpensAt == tt.schedule.OpensAt && p.ClosesAt == tt.schedule.ClosesAt
+				}), mock.AnythingOfType("time.Duration")).Return(nil)
+			}
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			_, err := service.CreatePoll(context.Background(), &model.CreatePollRequest{
+				Title:    "Standup topic?",
+				Options:  []string{"Release", "Hiring"},
+				Schedule: tt.schedule,
+			})
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidPollConfig)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_GetPoll(t *testing.T) {
+	now := time.Now()
+	opensAt := now.Add(90 * time.Minute)
+	closesAt := now.Add(30 * time.Minute)
+	closedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name     string
+		poll     *model.Poll
+		status   model.PollStatus
+		opensIn  time.Duration
+		closesIn time.Duration
+	}{
+		{
+			name:    "scheduled",
+			poll:    &model.Poll{ID: "poll1", Schedule: model.Schedule{OpensAt: &opensAt}, ExpiresAt: now.Add(time.Hour * 24)},
+			status:  model.PollStatusScheduled,
+			opensIn: 90 * time.Minute,
+		},
+		{
+			name:     "open until closing time",
+			poll:     &model.Poll{ID: "poll1", Schedule: model.Schedule{ClosesAt: &closesAt}, ExpiresAt: now.Add(time.Hour * 24)},
+			status:   model.PollStatusOpen,
+			closesIn: 30 * time.Minute,
+		},
+		{
+			name:     "open until expiry",
+			poll:     &model.Poll{ID: "poll1", ExpiresAt: now.Add(time.Hour)},
+			status:   model.PollStatusOpen,
+			closesIn: time.Hour,
+		},
+		{
+			name:   "closed",
+			poll:   &model.Poll{ID: "poll1", Schedule: model.Schedule{ClosesAt: &closedAt}, ExpiresAt: now.Add(time.Hour * 24)},
+			status: model.PollStatusClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			mockStorage.On("GetPoll", mock.Anything, "poll1").Return(tt.poll, nil)
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			details, err := service.GetPoll(context.Background(), "poll1")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, details.Status)
+			if tt.opensIn > 0 {
+				require.NotNil(t, details.OpensIn)
+				assert.InDelta(t, tt.opensIn.Seconds(), float64(*details.OpensIn), 2)
+			} else {
+				assert.Nil(t, details.OpensIn)
+			}
+			if tt.closesIn > 0 {
+				require.NotNil(t, details.ClosesIn)
+				assert.InDelta(t, tt.closesIn.Seconds(), float64(*details.ClosesIn), 2)
+			} else {
+				assert.Nil(t, details.ClosesIn)
+			}
+		})
+	}
+}
+
+func TestPollService_Vote_OutsideWindow(t *testing.T) {
+	for _, wantErr := range []error{storage.ErrNotOpen, storage.ErrClosed} {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&model.Poll{ID: "poll1"}, nil)
+		mockStorage.On("Vote", mock.Anything, "poll1", mock.Anything, mock.Anything, mock.Anything).Return(wantErr)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.Vote(context.Background(), "poll1", &model.VoteRequest{OptionIndices: []int{0}}, "", &model.PollAccess{})
+
+		assert.ErrorIs(t, err, wantErr)
+	}
+}
+
+func TestPollService_ExtendPoll(t *testing.T) {
+	createdAt := time.Now().Add(-24 * time.Hour)
+	expiresAt := createdAt.Add(168 * time.Hour)
+
+	tests := []struct {
+		name       string
+		token      string
+		expiresAt  time.Time
+		storageErr error
+		wantErr    error
+	}{
+		{name: "extend by a week", token: "secret", expiresAt: expiresAt.Add(168 * time.Hour)},
+		{name: "extend to maximum lifetime", token: "secret", expiresAt: createdAt.Add(720 * time.Hour)},
+		{name: "invalid admin token", token: "guess", expiresAt: expiresAt.Add(time.Hour), wantErr: ErrAdminForbidden},
+		{name: "beyond maximum lifetime", token: "secret", expiresAt: createdAt.Add(721 * time.Hour), wantErr: ErrInvalidPollConfig},
+		{name: "shorten lifetime", token: "secret", expiresAt: expiresAt.Add(-time.Hour), wantErr: ErrInvalidPollConfig},
+		{name: "same expiry", token: "secret", expiresAt: expiresAt, wantErr: ErrInvalidPollConfig},
+		{
+			name:       "extended further concurrently",
+			token:      "secret",
+			expiresAt:  expiresAt.Add(time.Hour),
+			storageErr: storage.ErrExpiryNotExtended,
+			wantErr:    ErrInvalidPollConfig,
+		},
+		{
+			name:       "expired meanwhile",
+			token:      "secret",
+			expiresAt:  expiresAt.Add(time.Hour),
+			storageErr: storage.ErrPollNotFound,
+			wantErr:    storage.ErrPollNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll := &model.Poll{
+				ID:             "poll1",
+				Options:        []string{"A", "B"},
+				CreatedAt:      createdAt,
+				ExpiresAt:      expiresAt,
+				AdminTokenHash: hashToken("secret"),
+			}
+			mockStorage := new(MockStorage)
+			mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+			if tt.storageErr != nil {
+				mockStorage.On("ExtendPoll", mock.Anything, "poll1", tt.expiresAt).Return(nil, tt.storageErr)
+			} else if tt.wantErr == nil {
+				extended := *poll
+				extended.ExpiresAt = tt.expiresAt
+				mockStorage.On("ExtendPoll", mock.Anything, "poll1", tt.expiresAt).Return(&extended, nil)
+			}
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			details, err := service.ExtendPoll(context.Background(), "poll1", tt.token, &model.ExtendPollRequest{ExpiresAt: tt.expiresAt})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expiresAt, details.ExpiresAt)
+				assert.Equal(t, model.PollStatusOpen, details.Status)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_ClosePoll(t *testing.T) {
+	poll := &model.Poll{ID: "poll1", AdminTokenHash: hashToken("secret"), ExpiresAt: time.Now().Add(24 * time.Hour)}
+
+	t.Run("admin closes poll", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+		var closesAt time.Time
+		closed := *poll
+		closed.ClosesAt = &closesAt
+		mockStorage.On("ClosePoll", mock.Anything, "poll1", mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) { closesAt = args.Get(2).(time.Time) }).
+			Return(&closed, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		details, err := service.ClosePoll(context.Background(), "poll1", "secret")
+
+		require.NoError(t, err)
+		assert.Equal(t, model.PollStatusClosed, details.Status)
+		require.NotNil(t, details.ClosesAt)
+		assert.WithinDuration(t, time.Now(), *details.ClosesAt, 2*time.Second)
+	})
+
+	t.Run("wrong admin token", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.ClosePoll(context.Background(), "poll1", "guess")
+
+		assert.ErrorIs(t, err, ErrAdminForbidden)
+		mockStorage.AssertNotCalled(t, "ClosePoll", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPollService_DeletePoll(t *testing.T) {
+	poll := &model.Poll{ID: "poll1", AdminTokenHash: hashToken("secret")}
+
+	t.Run("admin deletes poll", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+		mockStorage.On("DeletePoll", mock.Anything, "poll1").Return(nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		err := service.DeletePoll(context.Background(), "poll1", "secret")
+
+		assert.NoError(t, err)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("poll deleted concurrently", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+		mockStorage.On("DeletePoll", mock.Anything, "poll1").Return(storage.ErrPollNotFound)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		err := service.DeletePoll(context.Background(), "poll1", "secret")
+
+		assert.ErrorIs(t, err, storage.ErrPollNotFound)
+	})
+
+	t.Run("wrong admin token", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		err := service.DeletePoll(context.Background(), "poll1", "guess")
+
+		assert.ErrorIs(t, err, ErrAdminForbidden)
+		mockStorage.AssertNotCalled(t, "DeletePoll", mock.Anything, mock.Anything)
+	})
+}
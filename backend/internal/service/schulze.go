@@ -0,0 +1,106 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+)
+
+// tabulateSchulze counts ranked ballots using the Schulze method.
+// Options ranked on a ballot are preferred over unranked ones, unranked
+// options are considered equal to each other.
+func tabulateSchulze(options []string, ballots [][]int) *model.SchulzeResults {
+	n := len(options)
+	pairwise := newMatrix(n)
+
+	for _, ballot := range ballots {
+		position := make([]int, n)
+		for i := range position {
+			position[i] = n // unranked
+		}
+		for pos, idx := range ballot {
+			if idx >= 0 && idx < n && position[idx] == n {
+				position[idx] = pos
+			}
+		}
+
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if i != j && position[i] < position[j] {
+					pairwise[i][j]++
+				}
+			}
+		}
+	}
+
+	// Strongest paths via Floyd-Warshall widest path
+	paths := newMatrix(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j && pairwise[i][j] > pairwise[j][i] {
+				paths[i][j] = pairwise[i][j]
+			}
+		}
+	}
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				if j == i || j == k {
+					continue
+				}
+				paths[i][j] = max(paths[i][j], min(paths[i][k], paths[k][j]))
+			}
+		}
+	}
+
+	// Option beats another one when its strongest path is stronger,
+	// ordering is made by the number of beaten options
+	wins := make([]int, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j && paths[i][j] > paths[j][i] {
+				wins[i]++
+			}
+		}
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return wins[order[a]] > wins[order[b]]
+	})
+
+	ranking := [][]string{}
+	for pos, idx := range order {
+		if pos > 0 && wins[idx] == wins[order[pos-1]] {
+			ranking[len(ranking)-1] = append(ranking[len(ranking)-1], options[idx])
+			continue
+		}
+		ranking = append(ranking, []string{options[idx]})
+	}
+
+	results := &model.SchulzeResults{
+		Ballots:        len(ballots),
+		Pairwise:       pairwise,
+		StrongestPaths: paths,
+		Ranking:        ranking,
+	}
+	if len(ballots) > 0 && len(ranking) > 0 && len(ranking[0]) == 1 {
+		results.Winner = ranking[0][0]
+	}
+
+	return results
+}
+
+func newMatrix(n int) [][]int {
+	m := make([][]int, n)
+	for i := range m {
+		m[i] = make([]int, n)
+	}
+	return m
+}
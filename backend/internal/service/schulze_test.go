@@ -0,0 +1,140 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expandBallots repeats each ranking the given number of times
+func expandBallots(groups []struct {
+	count   int
+	ranking []int
+}) [][]int {
+	var ballots [][]int
+	for _, g := range groups {
+		for i := 0; i < g.count; i++ {
+			ballots = append(ballots, g.ranking)
+		}
+	}
+	return ballots
+}
+
+func TestTabulateSchulze_WikipediaExample(t *testing.T) {
+	// Example election from the Schulze method article: 45 voters, 5 candidates
+	const a, b, c, d, e = 0, 1, 2, 3, 4
+	options := []string{"A", "B", "C", "D", "E"}
+	ballots := expandBallots([]struct {
+		count   int
+		ranking []int
+	}{
+		{5, []int{a, c, b, e, d}},
+		{5, []int{a, d, e, c, b}},
+		{8, []int{b, e, d, a, c}},
+		{3, []int{c, a, b, e, d}},
+		{7, []int{c, a, e, b, d}},
+		{2, []int{c, b, a, d, e}},
+		{7, []int{d, c, e, b, a}},
+		{8, []int{e, b, a, d, c}},
+	})
+
+	res := tabulateSchulze(options, ballots)
+	require.NotNil(t, res)
+
+	assert.Equal(t, 45, res.Ballots)
+	assert.Equal(t, [][]int{
+		{0, 20, 26, 30, 22},
+		{25, 0, 16, 33, 18},
+		{19, 29, 0, 17, 24},
+		{15, 12, 28, 0, 14},
+		{23, 27, 21, 31, 0},
+	}, res.Pairwise)
+	assert.Equal(t, [][]int{
+		{0, 28, 28, 30, 24},
+		{25, 0, 28, 33, 24},
+		{25, 29, 0, 29, 24},
+		{25, 28, 28, 0, 24},
+		{25, 28, 28, 31, 0},
+	}, res.StrongestPaths)
+	assert.Equal(t, [][]string{{"E"}, {"A"}, {"C"}, {"B"}, {"D"}}, res.Ranking)
+	assert.Equal(t, "E", res.Winner)
+}
+
+func TestTabulateSchulze_TennesseeCapital(t *testing.T) {
+	// Classic example where Condorcet and instant-runoff winners differ
+	const memphis, nashville, chattanooga, knoxville = 0, 1, 2, 3
+	options := []string{"Memphis", "Nashville", "Chattanooga", "Knoxville"}
+	ballots := expandBallots([]struct {
+		count   int
+		ranking []int
+	}{
+		{42, []int{memphis, nashville, chattanooga, knoxville}},
+		{26, []int{nashville, chattanooga, knoxville, memphis}},
+		{15, []int{chattanooga, knoxville, nashville, memphis}},
+		{17, []int{knoxville, chattanooga, nashville, memphis}},
+	})
+
+	res := tabulateSchulze(options, ballots)
+	assert.Equal(t, 68, res.Pairwise[nashville][chattanooga])
+	assert.Equal(t, 32, res.Pairwise[chattanooga][nashville])
+	assert.Equal(t, [][]string{{"Nashville"}, {"Chattanooga"}, {"Knoxville"}, {"Memphis"}}, res.Ranking)
+	assert.Equal(t, "Nashville", res.Winner)
+
+	runoff := tabulateRunoff(options, ballots)
+	assert.Equal(t, "Knoxville", runoff.Winner)
+}
+
+func TestTabulateSchulze_CenterSqueeze(t *testing.T) {
+	const left, center, right = 0, 1, 2
+	options := []string{"Left", "Center", "Right"}
+	ballots := expandBallots([]struct {
+		count   int
+		ranking []int
+	}{
+		{35, []int{left, center, right}},
+		{33, []int{right, center, left}},
+		{16, []int{center, left, right}},
+		{16, []int{center, right, left}},
+	})
+
+	res := tabulateSchulze(options, ballots)
+	assert.Equal(t, "Center", res.Winner)
+
+	runoff := tabulateRunoff(options, ballots)
+	assert.Equal(t, "Left", runoff.Winner)
+}
+
+func TestTabulateSchulze_Cycle(t *testing.T) {
+	// Rock-paper-scissors preferences give no Condorcet winner
+	options := []string{"Rock", "Paper", "Scissors"}
+	ballots := [][]int{{0, 2, 1}, {1, 0, 2}, {2, 1, 0}}
+
+	res := tabulateSchulze(options, ballots)
+	assert.Equal(t, [][]string{{"Rock", "Paper", "Scissors"}}, res.Ranking)
+	assert.Empty(t, res.Winner)
+}
+
+func TestTabulateSchulze_PartialBallots(t *testing.T) {
+	// Ranked options beat unranked ones, unranked are equal
+	options := []string{"A", "B", "C"}
+	ballots := [][]int{{0}, {0}, {1}}
+
+	res := tabulateSchulze(options, ballots)
+	assert.Equal(t, [][]int{
+		{0, 2, 2},
+		{1, 0, 1},
+		{0, 0, 0},
+	}, res.Pairwise)
+	assert.Equal(t, [][]string{{"A"}, {"B"}, {"C"}}, res.Ranking)
+	assert.Equal(t, "A", res.Winner)
+}
+
+func TestTabulateSchulze_NoBallots(t *testing.T) {
+	options := []string{"A", "B"}
+
+	res := tabulateSchulze(options, [][]int{})
+	assert.Equal(t, 0, res.Ballots)
+	assert.Equal(t, [][]string{{"A", "B"}}, res.Ranking)
+	assert.Empty(t, res.Winner)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"github.com/AlexeyLars/surway-service/internal/model"
+)
+
+// scoreStatistics completes raw per-option score tallies with mean, median and full distribution
+func scoreStatistics(options []string, scale *model.ScoreScale, tallies map[int]model.ScoreStats) map[string]model.ScoreStats {
+	if scale == nil {
+		scale = &model.ScoreScale{Min: defaultScoreMin, Max: defaultScoreMax}
+	}
+
+	stats := make(map[string]model.ScoreStats, len(options))
+	for i, option := range options {
+		tally := tallies[i]
+
+		distribution := make(map[int]int, scale.Max-scale.Min+1)
+		for score := scale.Min; score <= scale.Max; score++ {
+			distribution[score] = tally.Distribution[score]
+		}
+
+		result := model.ScoreStats{
+			Count:        tally.Count,
+			Sum:          tally.Sum,
+			Distribution: distribution,
+		}
+		if tally.Count > 0 {
+			result.Mean = float64(tally.Sum) / float64(tally.Count)
+			result.Median = histogramMedian(distribution, scale.Min, scale.Max, tally.Count)
+		}
+		stats[option] = result
+	}
+
+	return stats
+}
+
+// histogramMedian finds median of count values distributed over integer buckets in [lo, hi]
+func histogramMedian(histogram map[int]int, lo, hi, count int) float64 {
+	// Median is the mean of values at positions (count-1)/2 and count/2
+	lower, upper := (count-1)/2, count/2
+	lowerValue, upperValue := 0, 0
+
+	seen := 0
+	for value := lo; value <= hi; value++ {
+		n := histogram[value]
+		if seen <= lower && lower < seen+n {
+			lowerValue = value
+		}
+		if seen <= upper && upper < seen+n {
+			upperValue = value
+			break
+		}
+		seen += n
+	}
+
+	return float64(lowerValue+upperValue) / 2
+}
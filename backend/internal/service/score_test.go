@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogramMedian(t *testing.T) {
+	tests := []struct {
+		name      string
+		histogram map[int]int
+		count     int
+		want      float64
+	}{
+		{
+			name:      "single value",
+			histogram: map[int]int{3: 1},
+			count:     1,
+			want:      3,
+		},
+		{
+			name:      "odd count",
+			histogram: map[int]int{1: 2, 4: 1, 5: 2},
+			count:     5,
+			want:      4,
+		},
+		{
+			name:      "even count between buckets",
+			histogram: map[int]int{2: 1, 5: 1},
+			count:     2,
+			want:      3.5,
+		},
+		{
+			name:      "even count inside bucket",
+			histogram: map[int]int{1: 1, 3: 2, 5: 1},
+			count:     4,
+			want:      3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, histogramMedian(tt.histogram, 1, 5, tt.count))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"log/slog"
+	"time"
+)
+
+// resultsStreamInterval is minimal time between two results sent to live results stream,
+// votes cast meanwhile are sent together
+const resultsStreamInterval = time.Second
+
+// WatchResults streams poll results, current results are sent right away and then again after votes.
+// Results visibility is checked once, channel is closed when ctx is done or poll expires,
+// expiry is noticed by timer, so stream of poll without votes is closed too.
+func (s *PollService) WatchResults(ctx context.Context, pollID string, viewer *model.ResultsViewer) (<-chan *model.PollResults, error) {
+	if err := s.checkPollResultsAccess(ctx, pollID, viewer); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Subscribe before reading results so that no vote falls between them
+	updates, err := s.storage.WatchPoll(ctx, pollID)
+	if err != nil {
+		cancel()
+		s.logger.ErrorContext(ctx, "failed to watch poll",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to watch poll: %w", err)
+	}
+
+	results, err := s.GetResults(ctx, pollID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	stream := make(chan *model.PollResults, 1)
+	stream <- results
+
+	go func() {
+		defer cancel()
+		defer close(stream)
+
+		// Poll may be extended meanwhile, so expiry is checked against stored poll when timer fires
+		expiry := time.NewTimer(time.Until(results.Poll.ExpiresAt))
+		defer expiry.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-updates:
+				if !ok {
+					return
+				}
+			case <-expiry.C:
+			}
+
+			results, err := s.GetResults(ctx, pollID)
+			if err != nil {
+				if err != storage.ErrPollNotFound && ctx.Err() == nil {
+					s.logger.ErrorContext(ctx, "failed to refresh live results",
+						slog.String("poll_id", pollID),
+						slog.String("error", err.Error()),
+					)
+				}
+				return
+			}
+			if !time.Now().Before(results.Poll.ExpiresAt) {
+				return
+			}
+			expiry.Reset(time.Until(results.Poll.ExpiresAt))
+
+			select {
+			case <-ctx.Done():
+				return
+			case stream <- results:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(resultsStreamInterval):
+			}
+		}
+	}()
+
+	return stream, nil
+}
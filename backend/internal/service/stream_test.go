@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollService_WatchResults(t *testing.T) {
+	mockStorage := new(MockStorage)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan struct{}, 1)
+	expiresAt := time.Now().Add(time.Hour)
+	mockStorage.On("GetPoll", mock.Anything, "poll1").
+		Return(&model.Poll{ID: "poll1", Options: []string{"A", "B"}, ExpiresAt: expiresAt}, nil)
+	mockStorage.On("WatchPoll", mock.Anything, "poll1").
+		Return((<-chan struct{})(updates), nil)
+	mockStorage.On("GetResults", mock.Anything, "poll1").
+		Return(&model.PollResults{
+			Poll:  model.Poll{ID: "poll1", Options: []string{"A", "B"}, ExpiresAt: expiresAt},
+			Votes: map[string]int{"A": 1, "B": 0},
+			Total: 1,
+		}, nil).Once()
+	mockStorage.On("GetResults", mock.Anything, "poll1").
+		Return(&model.PollResults{
+			Poll:  model.Poll{ID: "poll1", Options: []string{"A", "B"}, ExpiresAt: expiresAt},
+			Votes: map[string]int{"A": 1, "B": 1},
+			Total: 2,
+		}, nil).Once()
+
+	stream, err := service.WatchResults(ctx, "poll1", &model.ResultsViewer{})
+	require.NoError(t, err)
+
+	initial := <-stream
+	assert.Equal(t, 1, initial.Total)
+
+	updates <- struct{}{}
+	select {
+	case updated := <-stream:
+		assert.Equal(t, 2, updated.Total)
+	case <-time.After(time.Second):
+		t.Fatal("results were not sent after vote")
+	}
+
+	cancel()
+	close(updates)
+	for range stream {
+	}
+	mockStorage.AssertExpectations(t)
+}
+
+func TestPollService_WatchResults_Expiry(t *testing.T) {
+	mockStorage := new(MockStorage)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	poll := model.Poll{ID: "poll1", Options: []string{"A", "B"}, ExpiresAt: time.Now().Add(50 * time.Millisecond)}
+	mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&poll, nil)
+	mockStorage.On("WatchPoll", mock.Anything, "poll1").Return((<-chan struct{})(make(chan struct{})), nil)
+	mockStorage.On("GetResults", mock.Anything, "poll1").Return(&model.PollResults{Poll: poll}, nil).Once()
+	mockStorage.On("GetResults", mock.Anything, "poll1").Return(nil, storage.ErrPollNotFound).Once()
+
+	stream, err := service.WatchResults(context.Background(), "poll1", &model.ResultsViewer{})
+	require.NoError(t, err)
+	<-stream
+
+	select {
+	case _, ok := <-stream:
+		assert.False(t, ok, "stream is closed without votes once poll expires")
+	case <-time.After(time.Second):
+		t.Fatal("stream was not closed after expiry")
+	}
+	mockStorage.AssertExpectations(t)
+}
+
+func TestPollService_WatchResults_NotFound(t *testing.T) {
+	mockStorage := new(MockStorage)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	mockStorage.On("GetPoll", mock.Anything, "missing").
+		Return(nil, storage.ErrPollNotFound)
+
+	stream, err := service.WatchResults(context.Background(), "missing", &model.ResultsViewer{})
+	assert.ErrorIs(t, err, storage.ErrPollNotFound)
+	assert.Nil(t, stream)
+	mockStorage.AssertNotCalled(t, "WatchPoll", mock.Anything, mock.Anything)
+}
+
+func TestPollService_WatchResults_Hidden(t *testing.T) {
+	mockStorage := new(MockStorage)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	mockStorage.On("GetPoll", mock.Anything, "poll1").
+		Return(&model.Poll{ID: "poll1", ResultsVisibility: model.ResultsAfterClose, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	stream, err := service.WatchResults(context.Background(), "poll1", &model.ResultsViewer{})
+	assert.ErrorIs(t, err, ErrResultsHidden)
+	assert.Nil(t, stream)
+	mockStorage.AssertNotCalled(t, "WatchPoll", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/lib/random"
+	"github.com/AlexeyLars/surway-service/internal/lib/sanitize"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+)
+
+// CreateSurvey creates survey with ordered list of questions
+func (s *PollService) CreateSurvey(ctx context.Context, req *model.CreateSurveyRequest) (*model.CreateSurveyResponse, error) {
+	questions := make([]model.Question, len(req.Questions))
+	for n, question := range req.Questions {
+		if err := applyQuestionSettings(&question); err != nil {
+			s.logger.WarnContext(ctx, "invalid question settings",
+				slog.Int("question", n),
+				slog.String("error", err.Error()),
+			)
+			return nil, fmt.Errorf("question %d: %w", n, err)
+		}
+		questions[n] = question
+	}
+	if err := validateBranching(questions); err != nil {
+		s.logger.WarnContext(ctx, "invalid survey branching",
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	// Generate short alias as ID
+	aliasLen := 7
+	surveyID := random.NewRandomString(aliasLen)
+	adminToken := random.NewToken()
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.Poll.DefaultTTL)
+	if err := validateSchedule(&req.Schedule, now, expiresAt); err != nil {
+		s.logger.WarnContext(ctx, "invalid survey schedule",
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	survey := &model.Survey{
+		ID:        surveyID,
+		Title:     req.Title,
+		Questions: questions,
+
+		ResultsVisibility: req.ResultsVisibility,
+		Schedule:          req.Schedule,
+
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+
+		AdminTokenHash: hashToken(adminToken),
+	}
+
+	if err := s.storage.CreateSurvey(ctx, survey, s.config.Poll.DefaultTTL); err != nil {
+		s.logger.ErrorContext(ctx, "failed to create survey",
+			slog.String("survey_id", surveyID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to create survey: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "survey created",
+		slog.String("survey_id", surveyID),
+		slog.String("title", req.Title),
+		slog.Int("questions_count", len(questions)),
+	)
+
+	baseURL := s.config.Server.BaseURL
+	response := &model.CreateSurveyResponse{
+		SurveyID:     surveyID,
+		SurveyURL:    fmt.Sprintf("%s/api/v1/surveys/%s", baseURL, surveyID),
+		ResponsesURL: fmt.Sprintf("%s/api/v1/surveys/%s/responses", baseURL, surveyID),
+		ResultsURL:   fmt.Sprintf("%s/api/v1/surveys/%s/results", baseURL, surveyID),
+		AdminToken:   adminToken,
+	}
+
+	return response, nil
+}
+
+// GetSurvey returns survey definition with branching graph, poll is returned as one-question survey
+func (s *PollService) GetSurvey(ctx context.Context, surveyID string) (*model.SurveyDefinition, error) {
+	survey, _, err := s.loadSurvey(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	if !survey.Closed(time.Now()) {
+		survey.HideCorrectAnswers()
+	}
+
+	return &model.SurveyDefinition{
+		Survey:    *survey,
+		Branching: survey.Branching(),
+	}, nil
+}
+
+// SubmitSurvey registers answers to survey questions in one submission, quiz submissions are scored.
+// Access credentials apply to password-protected poll answered as one-question survey.
+func (s *PollService) SubmitSurvey(ctx context.Context, surveyID string, req *model.SubmitSurveyRequest, voterToken string, access *model.PollAccess) (*model.QuizScore, error) {
+	survey, fromPoll, err := s.loadSurvey(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range req.Answers {
+		req.Answers[i].Text = sanitize.Text(req.Answers[i].Text)
+	}
+
+	if err := checkReachableAnswers(survey, req.Answers); err != nil {
+		s.logger.WarnContext(ctx, "incomplete survey submission",
+			slog.String("survey_id", surveyID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	// Poll has the only question, answer to it is a regular vote
+	if fromPoll {
+		for i, answer := range req.Answers {
+			if answer.Question != 0 {
+				return nil, &storage.AnswerError{Question: answer.Question, Err: storage.ErrInvalidQuestion}
+			}
+			if i > 0 {
+				return nil, &storage.AnswerError{Question: 0, Err: storage.ErrDuplicateAnswer}
+			}
+		}
+		_, err := s.Vote(ctx, surveyID, req.Answers[0].VoteRequest(), voterToken, access)
+		return nil, err
+	}
+
+	if err := s.storage.SubmitSurvey(ctx, surveyID, req.Answers, voterHash(voterToken)); err != nil {
+		var answerErr *storage.AnswerError
+		if errors.As(err, &answerErr) {
+			s.logger.WarnContext(ctx, "invalid survey answer",
+				slog.String("survey_id", surveyID),
+				slog.Int("question", answerErr.Question),
+				slog.String("error", answerErr.Err.Error()),
+			)
+			return nil, err
+		}
+		if err == storage.ErrSurveyNotFound {
+			return nil, err
+		}
+		if err == storage.ErrAlreadyVoted {
+			s.logger.WarnContext(ctx, "repeated survey submission",
+				slog.String("survey_id", surveyID),
+			)
+			return nil, err
+		}
+		if err == storage.ErrNotOpen || err == storage.ErrClosed {
+			s.logger.WarnContext(ctx, "survey submitted outside voting window",
+				slog.String("survey_id", surveyID),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+
+		s.logger.ErrorContext(ctx, "failed to submit survey",
+			slog.String("survey_id", surveyID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to submit survey: %w", err)
+	}
+
+	score := survey.Grade(req.Answers)
+
+	attrs := []any{
+		slog.String("survey_id", surveyID),
+		slog.Int("answers_count", len(req.Answers)),
+	}
+	if score != nil {
+		attrs = append(attrs, slog.Int("score", score.Score))
+	}
+	s.logger.InfoContext(ctx, "survey submitted", attrs...)
+
+	return score, nil
+}
+
+// GetSurveyResults returns per-question results of survey, poll results are returned as one-question survey
+func (s *PollService) GetSurveyResults(ctx context.Context, surveyID string) (*model.SurveyResults, error) {
+	results, err := s.storage.GetSurveyResults(ctx, surveyID)
+	if err == storage.ErrSurveyNotFound {
+		pollResults, err := s.GetResults(ctx, surveyID)
+		if err == storage.ErrPollNotFound {
+			return nil, storage.ErrSurveyNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.SurveyResults{
+			Survey: pollResults.Poll.Survey(),
+			Questions: []model.QuestionResults{{
+				Question:  0,
+				Votes:     pollResults.Votes,
+				Total:     pollResults.Total,
+				Breakdown: pollResults.Breakdown,
+			}},
+		}, nil
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get survey results",
+			slog.String("survey_id", surveyID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to get survey results: %w", err)
+	}
+
+	for n := range results.Survey.Questions {
+		breakdown, err := questionBreakdown(ctx, &results.Survey.Questions[n], results.Questions[n].Votes, s.surveyAnswers(surveyID, n))
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to tabulate survey results",
+				slog.String("survey_id", surveyID),
+				slog.Int("question", n),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+		results.Questions[n].Breakdown = breakdown
+		if total, ok := breakdown.Answers(); ok {
+			results.Questions[n].Total = total
+		}
+	}
+
+	if results.Quiz != nil {
+		quizStatistics(results)
+	}
+	if !results.Survey.Closed(time.Now()) {
+		results.Survey.HideCorrectAnswers()
+	}
+
+	return results, nil
+}
+
+// GetSurveyTextAnswers returns page of text answers and write-ins of survey question to survey admin
+func (s *PollService) GetSurveyTextAnswers(ctx context.Context, surveyID string, question int, adminToken string, query *model.TextAnswersQuery) (*model.TextAnswersPage, error) {
+	survey, fromPoll, err := s.loadSurvey(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkAdminToken(survey.AdminTokenHash, adminToken); err != nil {
+		s.logger.WarnContext(ctx, "text answers requested without valid admin token",
+			slog.String("survey_id", surveyID),
+		)
+		return nil, err
+	}
+	if question < 0 || question >= len(survey.Questions) {
+		return nil, storage.ErrInvalidQuestion
+	}
+
+	limit := textsPerPage(query)
+	var answers []model.TextAnswer
+	var total int
+	if fromPoll {
+		answers, total, err = s.storage.GetTextAnswers(ctx, surveyID, query.Offset, limit)
+	} else {
+		answers, total, err = s.storage.GetSurveyTextAnswers(ctx, surveyID, question, query.Offset, limit)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get text answers",
+			slog.String("survey_id", surveyID),
+			slog.Int("question", question),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to get text answers: %w", err)
+	}
+
+	return &model.TextAnswersPage{
+		Items:  answers,
+		Total:  total,
+		Offset: query.Offset,
+		Limit:  limit,
+	}, nil
+}
+
+// surveyAnswers returns answer source of survey question
+func (s *PollService) surveyAnswers(surveyID string, question int) answerSource {
+	return answerSource{
+		ballots: func(ctx context.Context) ([][]int, error) { return s.storage.GetSurveyBallots(ctx, surveyID, question) },
+		scores: func(ctx context.Context) (map[int]model.ScoreStats, error) {
+			return s.storage.GetSurveyScores(ctx, surveyID, question)
+		},
+		numbers: func(ctx context.Context) (map[int]int, error) {
+			return s.storage.GetSurveyNumbers(ctx, surveyID, question)
+		},
+		matrix: func(ctx context.Context) (map[int]map[int]int, error) {
+			return s.storage.GetSurveyMatrix(ctx, surveyID, question)
+		},
+		terms: func(ctx context.Context, limit int) (*model.WordCloudResults, error) {
+			return s.storage.GetSurveyTerms(ctx, surveyID, question, limit)
+		},
+	}
+}
+
+// loadSurvey gets survey by ID, falling back to poll with the same ID as one-question survey
+func (s *PollService) loadSurvey(ctx context.Context, surveyID string) (*model.Survey, bool, error) {
+	survey, err := s.storage.GetSurvey(ctx, surveyID)
+	if err == nil {
+		return survey, false, nil
+	}
+	if err != storage.ErrSurveyNotFound {
+		s.logger.ErrorContext(ctx, "failed to get survey",
+			slog.String("survey_id", surveyID),
+			slog.String("error", err.Error()),
+		)
+		return nil, false, fmt.Errorf("failed to get survey: %w", err)
+	}
+
+	poll, err := s.storage.GetPoll(ctx, surveyID)
+	if err == storage.ErrPollNotFound {
+		s.logger.WarnContext(ctx, "non-existent survey requested",
+			slog.String("survey_id", surveyID),
+		)
+		return nil, false, storage.ErrSurveyNotFound
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get poll",
+			slog.String("poll_id", surveyID),
+			slog.String("error", err.Error()),
+		)
+		return nil, false, fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	pollSurvey := poll.Survey()
+	return &pollSurvey, true, nil
+}
+
+// validateBranching checks that show_if conditions refer to earlier choice questions and their options
+func validateBranching(questions []model.Question) error {
+	for n, question := range questions {
+		for _, condition := range question.ShowIf {
+			if condition.Question < 0 || condition.Question >= n {
+				return fmt.Errorf("%w: question %d: condition must refer to an earlier question", ErrInvalidPollConfig, n)
+			}
+			source := &questions[condition.Question]
+			if source.VotingType() != model.PollTypeChoice {
+				return fmt.Errorf("%w: question %d: condition must refer to a choice question", ErrInvalidPollConfig, n)
+			}
+			for _, option := range condition.Options {
+				if option < 0 || option >= len(source.Options) {
+					return fmt.Errorf("%w: question %d: condition option out of range", ErrInvalidPollConfig, n)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkReachableAnswers checks that only questions shown by branching conditions are answered
+// and every shown required question is answered
+func checkReachableAnswers(survey *model.Survey, answers []model.Answer) error {
+	answered := make(map[int]*model.Answer, len(answers))
+	for i := range answers {
+		if _, ok := answered[answers[i].Question]; !ok {
+			answered[answers[i].Question] = &answers[i]
+		}
+	}
+
+	reachable := survey.Reachable(answered)
+	for n, question := range survey.Questions {
+		_, ok := answered[n]
+		if ok && !reachable[n] {
+			return &storage.AnswerError{Question: n, Err: storage.ErrUnreachableAnswer}
+		}
+		if question.Required && reachable[n] && !ok {
+			return &storage.AnswerError{Question: n, Err: storage.ErrMissingAnswer}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,436 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSurvey() *model.Survey {
+	return &model.Survey{
+		ID:    "survey1",
+		Title: "Pulse survey",
+		Questions: []model.Question{
+			{Title: "How is your workload?", Type: model.PollTypeScore, Scale: &model.ScoreScale{Min: 1, Max: 5}, Options: []string{"This week", "This month"}, Required: true},
+			{Title: "What should we improve first?", Type: model.PollTypeRanked, Tabulation: model.TabulationIRV, Options: []string{"Meetings", "Tooling", "Docs"}},
+			{Title: "Which perks do you use?", Type: model.PollTypeChoice, Options: []string{"Gym", "Lunch"}},
+		},
+	}
+}
+
+func TestPollService_CreateSurvey(t *testing.T) {
+	t.Run("questions get default settings", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("CreateSurvey", mock.Anything, mock.MatchedBy(func(s *model.Survey) bool {
+			return len(s.Questions) == 3 &&
+				s.Questions[0].Type == model.PollTypeChoice &&
+				s.Questions[1].Tabulation == model.TabulationIRV &&
+				s.Questions[2].Scale != nil && s.Questions[2].Scale.Max == 5
+		}), mock.AnythingOfType("time.Duration")).Return(nil)
+
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+		res, err := service.CreateSurvey(context.Background(), &model.CreateSurveyRequest{
+			Title: "Pulse survey",
+			Questions: []model.Question{
+				{Title: "Perks?", Options: []string{"Gym", "Lunch"}},
+				{Title: "Improve first?", Type: model.PollTypeRanked, Options: []string{"Meetings", "Docs"}},
+				{Title: "Workload?", Type: model.PollTypeScore, Options: []string{"Week", "Month"}},
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, res.SurveyID, 7)
+		assert.Contains(t, res.ResponsesURL, "/surveys/"+res.SurveyID+"/responses")
+		assert.Contains(t, res.ResultsURL, "/surveys/"+res.SurveyID+"/results")
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("invalid question settings", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.CreateSurvey(context.Background(), &model.CreateSurveyRequest{
+			Title: "Pulse survey",
+			Questions: []model.Question{
+				{Title: "Perks?", Options: []string{"Gym", "Lunch"}},
+				{Title: "Workload?", Tabulation: model.TabulationSchulze, Options: []string{"Week", "Month"}},
+			},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidPollConfig)
+		assert.Contains(t, err.Error(), "question 1")
+		mockStorage.AssertNotCalled(t, "CreateSurvey", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPollService_SubmitSurvey(t *testing.T) {
+	tests := []struct {
+		name          string
+		answers       []model.Answer
+		setupMock     func(*MockStorage)
+		expectedError error
+	}{
+		{
+			name: "all questions answered",
+			answers: []model.Answer{
+				{Question: 0, Scores: map[int]int{0: 4, 1: 2}},
+				{Question: 1, Ranking: []int{1, 0}},
+				{Question: 2, OptionIndices: []int{0, 1}},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("GetSurvey", mock.Anything, "survey1").Return(newTestSurvey(), nil)
+				m.On("SubmitSurvey", mock.Anything, "survey1", mock.AnythingOfType("[]model.Answer"), mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "optional questions skipped",
+			answers: []model.Answer{
+				{Question: 0, Scores: map[int]int{0: 3}},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("GetSurvey", mock.Anything, "survey1").Return(newTestSurvey(), nil)
+				m.On("SubmitSurvey", mock.Anything, "survey1", mock.AnythingOfType("[]model.Answer"), mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "required question missing",
+			answers: []model.Answer{
+				{Question: 2, OptionIndices: []int{0}},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("GetSurvey", mock.Anything, "survey1").Return(newTestSurvey(), nil)
+			},
+			expectedError: storage.ErrMissingAnswer,
+		},
+		{
+			name: "invalid answer rejected by storage",
+			answers: []model.Answer{
+				{Question: 0, Scores: map[int]int{0: 9}},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("GetSurvey", mock.Anything, "survey1").Return(newTestSurvey(), nil)
+				m.On("SubmitSurvey", mock.Anything, "survey1", mock.AnythingOfType("[]model.Answer"), mock.Anything).
+					Return(&storage.AnswerError{Question: 0, Err: storage.ErrInvalidScore})
+			},
+			expectedError: storage.ErrInvalidScore,
+		},
+		{
+			name: "poll answered as one-question survey",
+			answers: []model.Answer{
+				{Question: 0, OptionIndices: []int{1}},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("GetSurvey", mock.Anything, "survey1").Return(nil, storage.ErrSurveyNotFound)
+				m.On("GetPoll", mock.Anything, "survey1").
+					Return(&model.Poll{ID: "survey1", Title: "Lunch?", Options: []string{"Pizza", "Sushi"}}, nil)
+				m.On("Vote", mock.Anything, "survey1", &model.VoteRequest{OptionIndices: []int{1}}, mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "poll has no second question",
+			answers: []model.Answer{
+				{Question: 0, OptionIndices: []int{1}},
+				{Question: 1, OptionIndices: []int{0}},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("GetSurvey", mock.Anything, "survey1").Return(nil, storage.ErrSurveyNotFound)
+				m.On("GetPoll", mock.Anything, "survey1").
+					Return(&model.Poll{ID: "survey1", Title: "Lunch?", Options: []string{"Pizza", "Sushi"}}, nil)
+			},
+			expectedError: storage.ErrInvalidQuestion,
+		},
+		{
+			name: "neither survey nor poll",
+			answers: []model.Answer{
+				{Question: 0, OptionIndices: []int{0}},
+			},
+			setupMock: func(m *MockStorage) {
+				m.On("GetSurvey", mock.Anything, "survey1").Return(nil, storage.ErrSurveyNotFound)
+				m.On("GetPoll", mock.Anything, "survey1").Return(nil, storage.ErrPollNotFound)
+			},
+			expectedError: storage.ErrSurveyNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			tt.setupMock(mockStorage)
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			_, err := service.SubmitSurvey(context.Background(), "survey1", &model.SubmitSurveyRequest{Answers: tt.answers}, "", &model.PollAccess{})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_GetSurveyResults(t *testing.T) {
+	t.Run("per-question breakdown", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		survey := newTestSurvey()
+		mockStorage.On("GetSurveyResults", mock.Anything, "survey1").Return(&model.SurveyResults{
+			Survey:    *survey,
+			Responses: 2,
+			Questions: []model.QuestionResults{
+				{Question: 0, Votes: map[string]int{"This week": 2, "This month": 1}, Total: 3},
+				{Question: 1, Votes: map[string]int{"Meetings": 1, "Tooling": 1, "Docs": 0}, Total: 2},
+				{Question: 2, Votes: map[string]int{"Gym": 1, "Lunch": 2}, Total: 3},
+			},
+		}, nil)
+		mockStorage.On("GetSurveyScores", mock.Anything, "survey1", 0).Return(map[int]model.ScoreStats{
+			0: {Count: 2, Sum: 8, Distribution: map[int]int{4: 2}},
+			1: {Count: 1, Sum: 2, Distribution: map[int]int{2: 1}},
+		}, nil)
+		mockStorage.On("GetSurveyBallots", mock.Anything, "survey1", 1).Return([][]int{{0, 1}, {1}}, nil)
+
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+		res, err := service.GetSurveyResults(context.Background(), "survey1")
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, res.Responses)
+		require.Len(t, res.Questions, 3)
+		assert.Equal(t, 4.0, res.Questions[0].Scores["This week"].Mean)
+		require.NotNil(t, res.Questions[1].Runoff)
+		assert.Equal(t, 2, res.Questions[1].Runoff.Ballots)
+		assert.Nil(t, res.Questions[2].Runoff)
+		assert.Nil(t, res.Questions[2].Scores)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("poll results as one-question survey", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetSurveyResults", mock.Anything, "poll1").Return(nil, storage.ErrSurveyNotFound)
+		mockStorage.On("GetResults", mock.Anything, "poll1").Return(&model.PollResults{
+			Poll:  model.Poll{ID: "poll1", Title: "Lunch?", Options: []string{"Pizza", "Sushi"}},
+			Votes: map[string]int{"Pizza": 3, "Sushi": 1},
+			Total: 4,
+		}, nil)
+
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+		res, err := service.GetSurveyResults(context.Background(), "poll1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "poll1", res.Survey.ID)
+		require.Len(t, res.Survey.Questions, 1)
+		assert.Equal(t, model.PollTypeChoice, res.Survey.Questions[0].Type)
+		assert.True(t, res.Survey.Questions[0].Required)
+		require.Len(t, res.Questions, 1)
+		assert.Equal(t, 4, res.Questions[0].Total)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetSurveyResults", mock.Anything, "survey1").Return(nil, errors.New("storage error"))
+
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+		res, err := service.GetSurveyResults(context.Background(), "survey1")
+
+		assert.Error(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+func newBranchingSurvey() *model.Survey {
+	return &model.Survey{
+		ID:    "survey1",
+		Title: "Onboarding",
+		Questions: []model.Question{
+			{Title: "Did you get a laptop?", Type: model.PollTypeChoice, Options: []string{"Yes", "No"}, Required: true},
+			{Title: "Rate your laptop", Type: model.PollTypeScore, Scale: &model.ScoreScale{Min: 1, Max: 5}, Options: []string{"Speed", "Screen"}, Required: true,
+				ShowIf: []model.Condition{{Question: 0, Options: []int{0}}}},
+			{Title: "What is missing?", Type: model.PollTypeChoice, Options: []string{"Laptop", "Monitor", "Keyboard"}, Required: true,
+				ShowIf: []model.Condition{{Question: 0, Options: []int{1}}}},
+			{Title: "When should it arrive?", Type: model.PollTypeChoice, Options: []string{"Today", "This week"},
+				ShowIf: []model.Condition{{Question: 2, Options: []int{0}}}},
+			{Title: "How was your first day?", Type: model.PollTypeScore, Scale: &model.ScoreScale{Min: 1, Max: 5}, Options: []string{"Overall"}, Required: true},
+		},
+	}
+}
+
+func TestPollService_CreateSurvey_Branching(t *testing.T) {
+	tests := []struct {
+		name          string
+		questions     []model.Question
+		expectedError error
+	}{
+		{
+			name: "condition on earlier choice question",
+			questions: []model.Question{
+				{Title: "Laptop?", Options: []string{"Yes", "No"}},
+				{Title: "Missing?", Options: []string{"Laptop", "Monitor"}, ShowIf: []model.Condition{{Question: 0, Options: []int{1}}}},
+			},
+		},
+		{
+			name: "condition on later question",
+			questions: []model.Question{
+				{Title: "Laptop?", Options: []string{"Yes", "No"}, ShowIf: []model.Condition{{Question: 1, Options: []int{0}}}},
+				{Title: "Missing?", Options: []string{"Laptop", "Monitor"}},
+			},
+			expectedError: ErrInvalidPollConfig,
+		},
+		{
+			name: "condition on itself",
+			questions: []model.Question{
+				{Title: "Laptop?", Options: []string{"Yes", "No"}, ShowIf: []model.Condition{{Question: 0, Options: []int{0}}}},
+				{Title: "Missing?", Options: []string{"Laptop", "Monitor"}},
+			},
+			expectedError: ErrInvalidPollConfig,
+		},
+		{
+			name: "condition on ranked question",
+			questions: []model.Question{
+				{Title: "Priorities?", Type: model.PollTypeRanked, Options: []string{"Docs", "Tools"}},
+				{Title: "Missing?", Options: []string{"Laptop", "Monitor"}, ShowIf: []model.Condition{{Question: 0, Options: []int{0}}}},
+			},
+			expectedError: ErrInvalidPollConfig,
+		},
+		{
+			name: "condition option out of range",
+			questions: []model.Question{
+				{Title: "Laptop?", Options: []string{"Yes", "No"}},
+				{Title: "Missing?", Options: []string{"Laptop", "Monitor"}, ShowIf: []model.Condition{{Question: 0, Options: []int{2}}}},
+			},
+			expectedError: ErrInvalidPollConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			if tt.expectedError == nil {
+				mockStorage.On("CreateSurvey", mock.Anything, mock.AnythingOfType("*model.Survey"), mock.AnythingOfType("time.Duration")).Return(nil)
+			}
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			_, err := service.CreateSurvey(context.Background(), &model.CreateSurveyRequest{
+				Title:     "Onboarding",
+				Questions: tt.questions,
+			})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_SubmitSurvey_Branching(t *testing.T) {
+	tests := []struct {
+		name          string
+		answers       []model.Answer
+		expectedError error
+		errorQuestion int
+	}{
+		{
+			name: "yes branch",
+			answers: []model.Answer{
+				{Question: 0, OptionIndices: []int{0}},
+				{Question: 1, Scores: map[int]int{0: 4, 1: 5}},
+				{Question: 4, Scores: map[int]int{0: 5}},
+			},
+		},
+		{
+			name: "no branch with nested optional question",
+			answers: []model.Answer{
+				{Question: 0, OptionIndices: []int{1}},
+				{Question: 2, OptionIndices: []int{0}},
+				{Question: 3, OptionIndices: []int{1}},
+				{Question: 4, Scores: map[int]int{0: 3}},
+			},
+		},
+		{
+			name: "hidden question answered",
+			answers: []model.Answer{
+				{Question: 0, OptionIndices: []int{1}},
+				{Question: 1, Scores: map[int]int{0: 4}},
+				{Question: 2, OptionIndices: []int{1}},
+				{Question: 4, Scores: map[int]int{0: 3}},
+			},
+			expectedError: storage.ErrUnreachableAnswer,
+			errorQuestion: 1,
+		},
+		{
+			name: "nested question hidden by earlier answer",
+			answers: []model.Answer{
+				{Question: 0, OptionIndices: []int{1}},
+				{Question: 2, OptionIndices: []int{1}},
+				{Question: 3, OptionIndices: []int{0}},
+				{Question: 4, Scores: map[int]int{0: 3}},
+			},
+			expectedError: storage.ErrUnreachableAnswer,
+			errorQuestion: 3,
+		},
+		{
+			name: "required question on taken branch missing",
+			answers: []model.Answer{
+				{Question: 0, OptionIndices: []int{1}},
+				{Question: 4, Scores: map[int]int{0: 3}},
+			},
+			expectedError: storage.ErrMissingAnswer,
+			errorQuestion: 2,
+		},
+		{
+			name: "branches hidden when root unanswered",
+			answers: []model.Answer{
+				{Question: 4, Scores: map[int]int{0: 3}},
+			},
+			expectedError: storage.ErrMissingAnswer,
+			errorQuestion: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			mockStorage.On("GetSurvey", mock.Anything, "survey1").Return(newBranchingSurvey(), nil)
+			if tt.expectedError == nil {
+				mockStorage.On("SubmitSurvey", mock.Anything, "survey1", tt.answers, mock.Anything).Return(nil)
+			}
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			_, err := service.SubmitSurvey(context.Background(), "survey1", &model.SubmitSurveyRequest{Answers: tt.answers}, "", &model.PollAccess{})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				var answerErr *storage.AnswerError
+				require.ErrorAs(t, err, &answerErr)
+				assert.Equal(t, tt.errorQuestion, answerErr.Question)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_GetSurvey_Branching(t *testing.T) {
+	mockStorage := new(MockStorage)
+	mockStorage.On("GetSurvey", mock.Anything, "survey1").Return(newBranchingSurvey(), nil)
+
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+	res, err := service.GetSurvey(context.Background(), "survey1")
+
+	require.NoError(t, err)
+	assert.Len(t, res.Questions, 5)
+	assert.Equal(t, []model.BranchEdge{
+		{From: 0, To: 1, Options: []int{0}},
+		{From: 0, To: 2, Options: []int{1}},
+		{From: 2, To: 3, Options: []int{0}},
+	}, res.Branching)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"log/slog"
+	"time"
+)
+
+// ErrResultsHidden returns when results visibility of poll or survey doesn't let viewer see results
+var ErrResultsHidden = errors.New("results are hidden")
+
+// voterHash returns stored form of voter token, votes without token are not remembered
+func voterHash(voterToken string) string {
+	if voterToken == "" {
+		return ""
+	}
+	return hashToken(voterToken)
+}
+
+// resultsAccess describes poll or survey whose results are requested
+type resultsAccess struct {
+	visibility     model.ResultsVisibility
+	closed         bool
+	adminTokenHash string
+	hasVoted       func(ctx context.Context, voter string) (bool, error)
+}
+
+// checkResultsAccess reports whether viewer can see results, admin token holder always can
+func checkResultsAccess(ctx context.Context, access resultsAccess, viewer *model.ResultsViewer) error {
+	switch access.visibility {
+	case model.ResultsAlways:
+		return nil
+	case model.ResultsAfterClose:
+		if access.closed {
+			return nil
+		}
+	case model.ResultsAfterVote:
+		if access.closed {
+			return nil
+		}
+		if viewer.VoterToken != "" {
+			voted, err := access.hasVoted(ctx, hashToken(viewer.VoterToken))
+			if err != nil {
+				return err
+			}
+			if voted {
+				return nil
+			}
+		}
+	}
+
+	if viewer.AdminToken != "" {
+		return checkAdminToken(access.adminTokenHash, viewer.AdminToken)
+	}
+	return ErrResultsHidden
+}
+
+// ViewResults returns poll results if its results visibility lets viewer see them.
+// Password-protected polls require password or access token, unless viewer is poll admin.
+func (s *PollService) ViewResults(ctx context.Context, pollID string, viewer *model.ResultsViewer) (*model.PollResults, error) {
+	if err := s.checkPollResultsAccess(ctx, pollID, viewer); err != nil {
+		return nil, err
+	}
+	return s.GetResults(ctx, pollID)
+}
+
+// checkPollResultsAccess loads poll and checks that viewer can see its results
+func (s *PollService) checkPollResultsAccess(ctx context.Context, pollID string, viewer *model.ResultsViewer) error {
+	poll, err := s.storage.GetPoll(ctx, pollID)
+	if err != nil {
+		if err == storage.ErrPollNotFound {
+			s.logger.WarnContext(ctx, "results requested for non-existent poll",
+				slog.String("poll_id", pollID),
+			)
+			return err
+		}
+		return fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	if viewer.AdminToken == "" || checkAdminToken(poll.AdminTokenHash, viewer.AdminToken) != nil {
+		if err := s.checkPollAccess(ctx, poll, &viewer.PollAccess); err != nil {
+			return err
+		}
+	}
+
+	access := resultsAccess{
+		visibility:     poll.Visibility(),
+		closed:         poll.Closed(time.Now()),
+		adminTokenHash: poll.AdminTokenHash,
+		hasVoted: func(ctx context.Context, voter string) (bool, error) {
+			return s.storage.HasVoted(ctx, pollID, voter)
+		},
+	}
+	if err := checkResultsAccess(ctx, access, viewer); err != nil {
+		if err == ErrResultsHidden || err == ErrAdminForbidden {
+			s.logger.InfoContext(ctx, "poll results hidden from viewer",
+				slog.String("poll_id", pollID),
+				slog.String("visibility", string(poll.Visibility())),
+			)
+			return err
+		}
+		s.logger.ErrorContext(ctx, "failed to check results access",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return fmt.Errorf("failed to check results access: %w", err)
+	}
+	return nil
+}
+
+// ViewSurveyResults returns survey results if its results visibility lets viewer see them,
+// visibility and password of poll apply when poll is requested as one-question survey
+func (s *PollService) ViewSurveyResults(ctx context.Context, surveyID string, viewer *model.ResultsViewer) (*model.SurveyResults, error) {
+	survey, fromPoll, err := s.loadSurvey(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	if fromPoll {
+		if err := s.checkPollResultsAccess(ctx, surveyID, viewer); err != nil {
+			if err == storage.ErrPollNotFound {
+				return nil, storage.ErrSurveyNotFound
+			}
+			return nil, err
+		}
+		return s.GetSurveyResults(ctx, surveyID)
+	}
+
+	access := resultsAccess{
+		visibility:     survey.Visibility(),
+		closed:         survey.Closed(time.Now()),
+		adminTokenHash: survey.AdminTokenHash,
+		hasVoted: func(ctx context.Context, voter string) (bool, error) {
+			return s.storage.HasSubmitted(ctx, surveyID, voter)
+		},
+	}
+	if err := checkResultsAccess(ctx, access, viewer); err != nil {
+		if err == ErrResultsHidden || err == ErrAdminForbidden {
+			s.logger.InfoContext(ctx, "survey results hidden from viewer",
+				slog.String("survey_id", surveyID),
+				slog.String("visibility", string(survey.Visibility())),
+			)
+			return nil, err
+		}
+		s.logger.ErrorContext(ctx, "failed to check results access",
+			slog.String("survey_id", surveyID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to check results access: %w", err)
+	}
+
+	return s.GetSurveyResults(ctx, surveyID)
+}
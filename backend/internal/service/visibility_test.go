@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollService_ViewResults(t *testing.T) {
+	open := time.Now().Add(time.Hour)
+	closed := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		visibility model.ResultsVisibility
+		expiresAt  time.Time
+		viewer     model.ResultsViewer
+		voted      bool
+		wantErr    error
+	}{
+		{name: "default is always", expiresAt: open},
+		{name: "always", visibility: model.ResultsAlways, expiresAt: open},
+		{name: "after vote without token", visibility: model.ResultsAfterVote, expiresAt: open, wantErr: ErrResultsHidden},
+		{name: "after vote before voting", visibility: model.ResultsAfterVote, expiresAt: open, viewer: model.ResultsViewer{VoterToken: "voter-token-0001"}, wantErr: ErrResultsHidden},
+		{name: "after vote by voter", visibility: model.ResultsAfterVote, expiresAt: open, viewer: model.ResultsViewer{VoterToken: "voter-token-0001"}, voted: true},
+		{name: "after vote once closed", visibility: model.ResultsAfterVote, expiresAt: closed},
+		{name: "after close while open", visibility: model.ResultsAfterClose, expiresAt: open, viewer: model.ResultsViewer{VoterToken: "voter-token-0001"}, voted: true, wantErr: ErrResultsHidden},
+		{name: "after close once closed", visibility: model.ResultsAfterClose, expiresAt: closed},
+		{name: "creator only", visibility: model.ResultsCreator, expiresAt: closed, wantErr: ErrResultsHidden},
+		{name: "creator with admin token", visibility: model.ResultsCreator, expiresAt: open, viewer: model.ResultsViewer{AdminToken: "secret"}},
+		{name: "creator with wrong admin token", visibility: model.ResultsCreator, expiresAt: open, viewer: model.ResultsViewer{AdminToken: "guess"}, wantErr: ErrAdminForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := new(MockStorage)
+			poll := &model.Poll{
+				ID:                "poll1",
+				Options:           []string{"A", "B"},
+				ResultsVisibility: tt.visibility,
+				ExpiresAt:         tt.expiresAt,
+				AdminTokenHash:    hashToken("secret"),
+			}
+			mockStorage.On("GetPoll", mock.Anything, "poll1").Return(poll, nil)
+			mockStorage.On("HasVoted", mock.Anything, "poll1", hashToken("voter-token-0001")).Return(tt.voted, nil).Maybe()
+			mockStorage.On("GetResults", mock.Anything, "poll1").Return(&model.PollResults{
+				Poll:  *poll,
+				Votes: map[string]int{"A": 1, "B": 0},
+				Total: 1,
+			}, nil).Maybe()
+			service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+			res, err := service.ViewResults(context.Background(), "poll1", &tt.viewer)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, res)
+				mockStorage.AssertNotCalled(t, "GetResults", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 1, res.Total)
+			}
+		})
+	}
+}
+
+func TestPollService_ViewSurveyResults(t *testing.T) {
+	t.Run("survey voters", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		survey := newTestSurvey()
+		survey.ResultsVisibility = model.ResultsAfterVote
+		survey.ExpiresAt = time.Now().Add(time.Hour)
+		mockStorage.On("GetSurvey", mock.Anything, "survey1").Return(survey, nil)
+		mockStorage.On("HasSubmitted", mock.Anything, "survey1", hashToken("voter-token-0001")).Return(false, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.ViewSurveyResults(context.Background(), "survey1", &model.ResultsViewer{VoterToken: "voter-token-0001"})
+
+		assert.ErrorIs(t, err, ErrResultsHidden)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("poll as survey keeps poll visibility", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("GetSurvey", mock.Anything, "poll1").Return(nil, storage.ErrSurveyNotFound)
+		mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&model.Poll{
+			ID:                "poll1",
+			Options:           []string{"A", "B"},
+			ResultsVisibility: model.ResultsAfterVote,
+			ExpiresAt:         time.Now().Add(time.Hour),
+		}, nil)
+		mockStorage.On("HasVoted", mock.Anything, "poll1", hashToken("voter-token-0001")).Return(false, nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.ViewSurveyResults(context.Background(), "poll1", &model.ResultsViewer{VoterToken: "voter-token-0001"})
+
+		assert.ErrorIs(t, err, ErrResultsHidden)
+		mockStorage.AssertExpectations(t)
+	})
+}
+
+func TestPollService_Vote_RemembersVoterHash(t *testing.T) {
+	mockStorage := new(MockStorage)
+	req := &model.VoteRequest{OptionIndices: []int{0}}
+	mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&model.Poll{ID: "poll1"}, nil)
+	mockStorage.On("Vote", mock.Anything, "poll1", req, hashToken("voter-token-0001"), mock.Anything).Return(nil)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	_, err := service.Vote(context.Background(), "poll1", req, "voter-token-0001", &model.PollAccess{})
+
+	require.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+}
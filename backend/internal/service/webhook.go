@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/lib/random"
+	"github.com/AlexeyLars/surway-service/internal/lib/safehttp"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"log/slog"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// webhookIDLength is length of generated webhook ID
+const webhookIDLength = 10
+
+// AddWebhook registers webhook of poll for its admin, secret is returned once
+func (s *PollService) AddWebhook(ctx context.Context, pollID, adminToken string, req *model.CreateWebhookRequest) (*model.Webhook, error) {
+	poll, err := s.adminPoll(ctx, pollID, adminToken)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := newWebhook(req, time.Now(), s.config.Webhook.AllowPrivate)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.addWebhook(ctx, poll, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// addWebhook saves webhook of poll
+func (s *PollService) addWebhook(ctx context.Context, poll *model.Poll, webhook *model.Webhook) error {
+	if err := s.storage.AddWebhook(ctx, poll, webhook); err != nil {
+		if err == storage.ErrPollNotFound || err == storage.ErrTooManyWebhooks {
+			return err
+		}
+		s.logger.ErrorContext(ctx, "failed to add webhook",
+			slog.String("poll_id", poll.ID),
+			slog.String("error", err.Error()),
+		)
+		return fmt.Errorf("failed to add webhook: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "webhook added",
+		slog.String("poll_id", poll.ID),
+		slog.String("webhook_id", webhook.ID),
+	)
+	return nil
+}
+
+// ListWebhooks returns webhooks of poll without secrets to its admin
+func (s *PollService) ListWebhooks(ctx context.Context, pollID, adminToken string) ([]model.Webhook, error) {
+	if _, err := s.adminPoll(ctx, pollID, adminToken); err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.storage.ListWebhooks(ctx, pollID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list webhooks",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes webhook of poll for its admin
+func (s *PollService) DeleteWebhook(ctx context.Context, pollID, adminToken, webhookID string) error {
+	if _, err := s.adminPoll(ctx, pollID, adminToken); err != nil {
+		return err
+	}
+
+	if err := s.storage.DeleteWebhook(ctx, pollID, webhookID); err != nil {
+		if err == storage.ErrWebhookNotFound {
+			return err
+		}
+		s.logger.ErrorContext(ctx, "failed to delete webhook",
+			slog.String("poll_id", pollID),
+			slog.String("webhook_id", webhookID),
+			slog.String("error", err.Error()),
+		)
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "webhook deleted",
+		slog.String("poll_id", pollID),
+		slog.String("webhook_id", webhookID),
+	)
+	return nil
+}
+
+// GetWebhookDeliveries returns delivery log and dead letters of poll webhooks to its admin
+func (s *PollService) GetWebhookDeliveries(ctx context.Context, pollID, adminToken string) (*model.WebhookDeliveryLog, error) {
+	if _, err := s.adminPoll(ctx, pollID, adminToken); err != nil {
+		return nil, err
+	}
+
+	log, err := s.storage.GetWebhookDeliveryLog(ctx, pollID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get webhook delivery log",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return nil, fmt.Errorf("failed to get webhook delivery log: %w", err)
+	}
+	return log, nil
+}
+
+// newWebhook validates request and generates webhook with its secret, private addresses are rejected unless allowed
+func newWebhook(req *model.CreateWebhookRequest, now time.Time, allowPrivate bool) (*model.Webhook, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("%w: webhook url must be absolute http or https URL", ErrInvalidPollConfig)
+	}
+	if !allowPrivate {
+		if err := safehttp.CheckHost(target); err != nil {
+			return nil, fmt.Errorf("%w: webhook url must not point to private network", ErrInvalidPollConfig)
+		}
+	}
+
+	events := model.WebhookEventTypes
+	if len(req.Events) > 0 {
+		events = make([]model.WebhookEventType, 0, len(req.Events))
+		for _, event := range req.Events {
+			if !slices.Contains(model.WebhookEventTypes, event) {
+				return nil, fmt.Errorf("%w: unknown webhook event %s", ErrInvalidPollConfig, event)
+			}
+			if !slices.Contains(events, event) {
+				events = append(events, event)
+			}
+		}
+	}
+
+	return &model.Webhook{
+		ID:        random.NewRandomString(webhookIDLength),
+		URL:       req.URL,
+		Events:    slices.Clone(events),
+		Secret:    random.NewToken(),
+		CreatedAt: now,
+	}, nil
+}
+
+// newWebhookEvent returns event of poll with data encoded as JSON
+func newWebhookEvent(pollID string, eventType model.WebhookEventType, data any, now time.Time) (*model.WebhookEvent, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return &model.WebhookEvent{
+		ID:        random.NewToken(),
+		Type:      eventType,
+		PollID:    pollID,
+		CreatedAt: now,
+		Data:      encoded,
+	}, nil
+}
+
+// webhookDeliveries returns deliveries of event to webhooks subscribed to it
+func webhookDeliveries(webhooks []model.Webhook, event *model.WebhookEvent) []model.WebhookDelivery {
+	var deliveries []model.WebhookDelivery
+	for i := range webhooks {
+		if !webhooks[i].Subscribed(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			ID:        random.NewToken(),
+			WebhookID: webhooks[i].ID,
+			Event:     *event,
+		})
+	}
+	return deliveries
+}
+
+// subscribed reports whether any webhook receives events of type
+func subscribed(webhooks []model.Webhook, eventType model.WebhookEventType) bool {
+	return slices.ContainsFunc(webhooks, func(webhook model.Webhook) bool {
+		return webhook.Subscribed(eventType)
+	})
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebhookConfig() config.WebhookConfig {
+	return config.WebhookConfig{
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		RetryBaseDelay: 10 * time.Second,
+		RetryMaxDelay:  30 * time.Second,
+		BatchInterval:  10 * time.Second,
+		AllowPrivate:   true, // receivers are test servers on loopback
+	}
+}
+
+func TestNewWebhook(t *testing.T) {
+	now := time.Now()
+
+	webhook, err := newWebhook(&model.CreateWebhookRequest{URL: "https://example.com/hook"}, now, false)
+	require.NoError(t, err)
+	assert.Len(t, webhook.ID, webhookIDLength)
+	assert.NotEmpty(t, webhook.Secret)
+	assert.Equal(t, model.WebhookEventTypes, webhook.Events, "all events by default")
+
+	webhook, err = newWebhook(&model.CreateWebhookRequest{
+		URL:    "http://example.com/hook",
+		Events: []model.WebhookEventType{model.WebhookVoteCast, model.WebhookVoteCast, model.WebhookPollClosed},
+	}, now, false)
+	require.NoError(t, err)
+	assert.Equal(t, []model.WebhookEventType{model.WebhookVoteCast, model.WebhookPollClosed}, webhook.Events)
+
+	for _, target := range []string{"ftp://example.com/hook", "/hook", "https://"} {
+		_, err = newWebhook(&model.CreateWebhookRequest{URL: target}, now, false)
+		assert.ErrorIs(t, err, ErrInvalidPollConfig, target)
+	}
+
+	for _, target := range []string{"http://127.0.0.1:6379/", "http://169.254.169.254/latest/meta-data", "http://localhost:8080/hook", "http://[::1]/hook"} {
+		_, err = newWebhook(&model.CreateWebhookRequest{URL: target}, now, false)
+		assert.ErrorIs(t, err, ErrInvalidPollConfig, target)
+	}
+	_, err = newWebhook(&model.CreateWebhookRequest{URL: "http://localhost:8080/hook"}, now, true)
+	assert.NoError(t, err, "private address is allowed for local testing")
+}
+
+func TestPollService_CreatePoll_Webhooks(t *testing.T) {
+	mockStorage := new(MockStorage)
+	var deliveries []model.WebhookDelivery
+	mockStorage.On("CreatePoll", mock.Anything, mock.AnythingOfType("*model.Poll"), mock.AnythingOfType("time.Duration")).Return(nil)
+	mockStorage.On("AddWebhook", mock.Anything, mock.AnythingOfType("*model.Poll"), mock.AnythingOfType("*model.Webhook")).Return(nil).Twice()
+	mockStorage.On("EnqueueWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { deliveries = args.Get(1).([]model.WebhookDelivery) }).
+		Return(nil)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	response, err := service.CreatePoll(context.Background(), &model.CreatePollRequest{
+		Title:   "Lunch?",
+		Options: []string{"Pizza", "Sushi"},
+		Webhooks: []model.CreateWebhookRequest{
+			{URL: "https://example.com/all"},
+			{URL: "https://example.com/votes", Events: []model.WebhookEventType{model.WebhookVoteCast}},
+		},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, response.Webhooks, 2)
+	assert.NotEmpty(t, response.Webhooks[0].Secret, "secret is shown on creation")
+
+	require.Len(t, deliveries, 1, "poll.created goes to subscribed webhooks only")
+	assert.Equal(t, response.Webhooks[0].ID, deliveries[0].WebhookID)
+	assert.Equal(t, model.WebhookPollCreated, deliveries[0].Event.Type)
+	assert.Equal(t, response.PollID, deliveries[0].Event.PollID)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestPollService_CreatePoll_WebhookFailure(t *testing.T) {
+	t.Run("invalid webhook is rejected before poll is saved", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.CreatePoll(context.Background(), &model.CreatePollRequest{
+			Title:    "Lunch?",
+			Options:  []string{"Pizza", "Sushi"},
+			Webhooks: []model.CreateWebhookRequest{{URL: "http://169.254.169.254/latest/meta-data"}},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidPollConfig)
+		mockStorage.AssertNotCalled(t, "CreatePoll", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("poll is deleted when webhooks aren't saved", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		var pollID string
+		mockStorage.On("CreatePoll", mock.Anything, mock.AnythingOfType("*model.Poll"), mock.AnythingOfType("time.Duration")).
+			Run(func(args mock.Arguments) { pollID = args.Get(1).(*model.Poll).ID }).Return(nil)
+		mockStorage.On("AddWebhook", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+		mockStorage.On("DeletePoll", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+		service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+		_, err := service.CreatePoll(context.Background(), &model.CreatePollRequest{
+			Title:    "Lunch?",
+			Options:  []string{"Pizza", "Sushi"},
+			Webhooks: []model.CreateWebhookRequest{{URL: "https://example.com/hook"}},
+		})
+
+		require.Error(t, err)
+		mockStorage.AssertCalled(t, "DeletePoll", mock.Anything, pollID)
+	})
+}
+
+func TestPollService_ListWebhooks(t *testing.T) {
+	mockStorage := new(MockStorage)
+	mockStorage.On("GetPoll", mock.Anything, "poll1").Return(&model.Poll{ID: "poll1", AdminTokenHash: hashToken("admin")}, nil)
+	mockStorage.On("ListWebhooks", mock.Anything, "poll1").Return([]model.Webhook{{ID: "hook1", Secret: "secret"}}, nil)
+	service := NewPollService(mockStorage, newTestConfig(), newTestLogger())
+
+	webhooks, err := service.ListWebhooks(context.Background(), "poll1", "admin")
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Empty(t, webhooks[0].Secret, "secret is not shown again")
+
+	_, err = service.ListWebhooks(context.Background(), "poll1", "guess")
+	assert.ErrorIs(t, err, ErrAdminForbidden)
+}
+
+func TestWebhookSignature(t *testing.T) {
+	body := []byte(`{"type":"vote.cast"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), webhookSignature("secret", 1700000000, body))
+	assert.NotEqual(t, webhookSignature("secret", 1700000000, body), webhookSignature("secret", 1700000001, body))
+}
+
+func TestWebhookWorker_Backoff(t *testing.T) {
+	worker := NewWebhookWorker(new(MockStorage), newTestWebhookConfig(), newTestLogger())
+
+	assert.Equal(t, 10*time.Second, worker.retries.backoff(1))
+	assert.Equal(t, 20*time.Second, worker.retries.backoff(2))
+	assert.Equal(t, 30*time.Second, worker.retries.backoff(3), "capped by max delay")
+	assert.Equal(t, 30*time.Second, worker.retries.backoff(80), "shift overflow")
+
+	now := time.Now()
+	due, ok := worker.retries.next(2, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(20*time.Second), due)
+	_, ok = worker.retries.next(3, now)
+	assert.False(t, ok, "given up after max attempts")
+}
+
+func TestWebhookWorker_Deliver(t *testing.T) {
+	event := model.WebhookEvent{ID: "evt1", Type: model.WebhookVoteCast, PollID: "poll1", CreatedAt: time.Now()}
+
+	t.Run("signed request", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		mockStorage := new(MockStorage)
+		mockStorage.On("ListWebhooks", mock.Anything, "poll1").Return([]model.Webhook{{ID: "hook1", URL: server.URL, Secret: "secret"}}, nil)
+		mockStorage.On("RecordWebhookAttempt", mock.Anything, "poll1",
+			mock.MatchedBy(func(a *model.WebhookAttempt) bool {
+				return a.Status == model.WebhookDelivered && a.StatusCode == http.StatusOK && a.Attempt == 1
+			}), (*model.WebhookDeadLetter)(nil)).Return(nil)
+		worker := NewWebhookWorker(mockStorage, newTestWebhookConfig(), newTestLogger())
+
+		worker.deliver(context.Background(), model.WebhookDelivery{ID: "d1", WebhookID: "hook1", Event: event})
+
+		require.NotNil(t, received)
+		assert.Equal(t, "vote.cast", received.Header.Get(webhookEventHeader))
+		assert.Equal(t, "d1", received.Header.Get(webhookDeliveryHeader))
+		timestamp := received.Header.Get(webhookTimestampHeader)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(timestamp + "." + string(body)))
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), received.Header.Get(webhookSignatureHeader))
+
+		var sent model.WebhookEvent
+		require.NoError(t, json.Unmarshal(body, &sent))
+		assert.Equal(t, "evt1", sent.ID)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("failure is retried", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		mockStorage := new(MockStorage)
+		var retried []model.WebhookDelivery
+		var retryAt time.Time
+		mockStorage.On("ListWebhooks", mock.Anything, "poll1").Return([]model.Webhook{{ID: "hook1", URL: server.URL, Secret: "secret"}}, nil)
+		mockStorage.On("EnqueueWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				retried = args.Get(1).([]model.WebhookDelivery)
+				retryAt = args.Get(2).(time.Time)
+			}).
+			Return(nil)
+		mockStorage.On("RecordWebhookAttempt", mock.Anything, "poll1",
+			mock.MatchedBy(func(a *model.WebhookAttempt) bool {
+				return a.Status == model.WebhookRetrying && a.StatusCode == http.StatusServiceUnavailable
+			}), (*model.WebhookDeadLetter)(nil)).Return(nil)
+		worker := NewWebhookWorker(mockStorage, newTestWebhookConfig(), newTestLogger())
+
+		worker.deliver(context.Background(), model.WebhookDelivery{ID: "d1", WebhookID: "hook1", Event: event, Attempts: 1})
+
+		require.Len(t, retried, 1)
+		assert.Equal(t, 2, retried[0].Attempts)
+		assert.WithinDuration(t, time.Now().Add(20*time.Second), retryAt, 5*time.Second)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("last attempt goes to dead letters", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		mockStorage := new(MockStorage)
+		var dead *model.WebhookDeadLetter
+		mockStorage.On("ListWebhooks", mock.Anything, "poll1").Return([]model.Webhook{{ID: "hook1", URL: server.URL, Secret: "secret"}}, nil)
+		mockStorage.On("RecordWebhookAttempt", mock.Anything, "poll1", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { dead = args.Get(3).(*model.WebhookDeadLetter) }).
+			Return(nil)
+		worker := NewWebhookWorker(mockStorage, newTestWebhookConfig(), newTestLogger())
+
+		worker.deliver(context.Background(), model.WebhookDelivery{ID: "d1", WebhookID: "hook1", Event: event, Attempts: 2})
+
+		require.NotNil(t, dead)
+		assert.Equal(t, "d1", dead.ID)
+		assert.Equal(t, 3, dead.Attempts)
+		mockStorage.AssertNotCalled(t, "EnqueueWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("deleted webhook", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("ListWebhooks", mock.Anything, "poll1").Return([]model.Webhook{}, nil)
+		worker := NewWebhookWorker(mockStorage, newTestWebhookConfig(), newTestLogger())
+
+		worker.deliver(context.Background(), model.WebhookDelivery{ID: "d1", WebhookID: "hook1", Event: event})
+
+		mockStorage.AssertNotCalled(t, "RecordWebhookAttempt", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWebhookWorker_Trigger(t *testing.T) {
+	webhooks := []model.Webhook{
+		{ID: "hook1", Events: []model.WebhookEventType{model.WebhookVoteCast}},
+		{ID: "hook2", Events: []model.WebhookEventType{model.WebhookPollClosed}},
+	}
+
+	t.Run("vote batch", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		var deliveries []model.WebhookDelivery
+		mockStorage.On("ListWebhooks", mock.Anything, "poll1").Return(webhooks, nil)
+		mockStorage.On("CountVoteBatch", mock.Anything, "poll1").Return(int64(3), nil)
+		mockStorage.On("GetResults", mock.Anything, "poll1").Return(&model.PollResults{Total: 7}, nil)
+		mockStorage.On("EnqueueWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { deliveries = args.Get(1).([]model.WebhookDelivery) }).
+			Return(nil)
+		worker := NewWebhookWorker(mockStorage, newTestWebhookConfig(), newTestLogger())
+
+		err := worker.trigger(context.Background(), model.WebhookTrigger{PollID: "poll1", Type: model.WebhookVoteCast}, time.Now())
+
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, "hook1", deliveries[0].WebhookID)
+		var data model.VoteCastData
+		require.NoError(t, json.Unmarshal(deliveries[0].Event.Data, &data))
+		assert.Equal(t, int64(3), data.Votes)
+		assert.Equal(t, 7, data.Results.Total)
+	})
+
+	t.Run("no new votes", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("ListWebhooks", mock.Anything, "poll1").Return(webhooks, nil)
+		mockStorage.On("CountVoteBatch", mock.Anything, "poll1").Return(int64(0), nil)
+		worker := NewWebhookWorker(mockStorage, newTestWebhookConfig(), newTestLogger())
+
+		err := worker.trigger(context.Background(), model.WebhookTrigger{PollID: "poll1", Type: model.WebhookVoteCast}, time.Now())
+
+		require.NoError(t, err)
+		mockStorage.AssertNotCalled(t, "EnqueueWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("no subscribers", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockStorage.On("ListWebhooks", mock.Anything, "poll1").Return(webhooks, nil)
+		worker := NewWebhookWorker(mockStorage, newTestWebhookConfig(), newTestLogger())
+
+		err := worker.trigger(context.Background(), model.WebhookTrigger{PollID: "poll1", Type: model.WebhookPollExpired}, time.Now())
+
+		require.NoError(t, err)
+		mockStorage.AssertNotCalled(t, "EnqueueWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/lib/safehttp"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+)
+
+// Headers of webhook request
+const (
+	webhookEventHeader     = "X-Surway-Event"
+	webhookDeliveryHeader  = "X-Surway-Delivery"
+	webhookTimestampHeader = "X-Surway-Timestamp"
+	webhookSignatureHeader = "X-Surway-Signature"
+)
+
+// webhookPollInterval is how often worker looks for due events and deliveries
+const webhookPollInterval = time.Second
+
+// webhookClaimLimit bounds events claimed by one pass of worker and deliveries being sent at once
+const webhookClaimLimit = 50
+
+// WebhookWorker turns poll events into webhook requests in background, so voting doesn't wait for them.
+// Events and deliveries are claimed from storage, several workers share them without duplicates.
+type WebhookWorker struct {
+	storage storage.Storage
+	config  config.WebhookConfig
+	client  *http.Client
+	retries retryPolicy
+	logger  *slog.Logger
+
+	// pending holds when vote.cast scheduled by this worker is due, so every vote doesn't reach storage
+	pending map[string]time.Time
+}
+
+func NewWebhookWorker(storage storage.Storage, cfg config.WebhookConfig, logger *slog.Logger) *WebhookWorker {
+	return &WebhookWorker{
+		storage: storage,
+		config:  cfg,
+		client:  safehttp.NewClient(cfg.Timeout, cfg.AllowPrivate),
+		retries: retryPolicy{maxAttempts: cfg.MaxAttempts, baseDelay: cfg.RetryBaseDelay, maxDelay: cfg.RetryMaxDelay},
+		logger:  logger,
+		pending: make(map[string]time.Time),
+	}
+}
+
+// Run processes webhook events until ctx is done, requests in flight are completed before return
+func (w *WebhookWorker) Run(ctx context.Context) error {
+	votes, err := w.storage.WatchVotes(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	runner := newDueRunner(webhookClaimLimit)
+	defer runner.wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case pollID, ok := <-votes:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return errors.New("poll updates subscription closed")
+			}
+			w.scheduleVoteCast(ctx, pollID)
+		case <-ticker.C:
+			w.processTriggers(ctx)
+			w.processDeliveries(ctx, runner)
+		}
+	}
+}
+
+// scheduleVoteCast schedules vote.cast of poll at the end of batch interval
+func (w *WebhookWorker) scheduleVoteCast(ctx context.Context, pollID string) {
+	now := time.Now()
+	if due, ok := w.pending[pollID]; ok && now.Before(due) {
+		return
+	}
+
+	due := now.Add(w.config.BatchInterval)
+	if err := w.storage.ScheduleVoteCast(ctx, pollID, due); err != nil {
+		w.logger.ErrorContext(ctx, "failed to schedule vote.cast",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	w.pending[pollID] = due
+}
+
+// processTriggers turns due poll events into deliveries
+func (w *WebhookWorker) processTriggers(ctx context.Context) {
+	now := time.Now()
+	for pollID, due := range w.pending {
+		if !now.Before(due) {
+			delete(w.pending, pollID)
+		}
+	}
+
+	triggers, err := w.storage.ClaimWebhookTriggers(ctx, now, webhookClaimLimit)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to claim webhook events",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	for _, trigger := range triggers {
+		if err := w.trigger(ctx, trigger, now); err != nil {
+			w.logger.ErrorContext(ctx, "failed to create webhook event",
+				slog.String("poll_id", trigger.PollID),
+				slog.String("event", string(trigger.Type)),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// trigger enqueues deliveries of poll event to subscribed webhooks
+func (w *WebhookWorker) trigger(ctx context.Context, trigger model.WebhookTrigger, now time.Time) error {
+	webhooks, err := w.storage.ListWebhooks(ctx, trigger.PollID)
+	if err != nil {
+		return err
+	}
+	if !subscribed(webhooks, trigger.Type) {
+		return nil
+	}
+
+	data, err := w.eventData(ctx, trigger, now)
+	if err != nil {
+		if errors.Is(err, storage.ErrPollNotFound) {
+			return nil
+		}
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	event, err := newWebhookEvent(trigger.PollID, trigger.Type, data, now)
+	if err != nil {
+		return err
+	}
+	return w.storage.EnqueueWebhookDeliveries(ctx, webhookDeliveries(webhooks, event), now)
+}
+
+// eventData returns data of poll event, it is nil when there is nothing to report
+func (w *WebhookWorker) eventData(ctx context.Context, trigger model.WebhookTrigger, now time.Time) (any, error) {
+	switch trigger.Type {
+	case model.WebhookVoteCast:
+		votes, err := w.storage.CountVoteBatch(ctx, trigger.PollID)
+		if err != nil || votes == 0 {
+			return nil, err
+		}
+		results, err := w.storage.GetResults(ctx, trigger.PollID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.VoteCastData{Votes: votes, Results: results}, nil
+	case model.WebhookPollClosed:
+		poll, err := w.storage.GetPoll(ctx, trigger.PollID)
+		if err != nil {
+			return nil, err
+		}
+		return model.NewPollDetails(poll, now), nil
+	case model.WebhookPollExpired:
+		return &model.PollExpiredData{ExpiredAt: trigger.DueAt.UTC()}, nil
+	}
+	return nil, fmt.Errorf("unknown webhook event %s", trigger.Type)
+}
+
+// processDeliveries starts sending of due deliveries in background, no more than runner has free slots for
+func (w *WebhookWorker) processDeliveries(ctx context.Context, runner *dueRunner) {
+	free := runner.free()
+	if free == 0 {
+		return
+	}
+	deliveries, err := w.storage.ClaimWebhookDeliveries(ctx, time.Now(), free)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to claim webhook deliveries",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	runDue(ctx, runner, deliveries, w.deliver)
+}
+
+// deliver sends delivery, failed delivery is retried with exponential backoff and given up after max attempts
+func (w *WebhookWorker) deliver(ctx context.Context, delivery model.WebhookDelivery) {
+	pollID := delivery.Event.PollID
+
+	webhooks, err := w.storage.ListWebhooks(ctx, pollID)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "failed to get webhooks",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+		w.retry(ctx, delivery, time.Now().Add(w.retries.backoff(delivery.Attempts)))
+		return
+	}
+	var webhook *model.Webhook
+	for i := range webhooks {
+		if webhooks[i].ID == delivery.WebhookID {
+			webhook = &webhooks[i]
+		}
+	}
+	if webhook == nil {
+		// Webhook or poll was deleted
+		return
+	}
+
+	statusCode, err := w.send(ctx, webhook, &delivery)
+	now := time.Now()
+	attempt := &model.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		WebhookID:  delivery.WebhookID,
+		EventID:    delivery.Event.ID,
+		EventType:  delivery.Event.Type,
+		Attempt:    delivery.Attempts + 1,
+		Status:     model.WebhookDelivered,
+		StatusCode: statusCode,
+		Timestamp:  now,
+	}
+
+	var dead *model.WebhookDeadLetter
+	if err != nil {
+		attempt.Error = err.Error()
+		delivery.Attempts++
+		if due, ok := w.retries.next(delivery.Attempts, now); ok {
+			attempt.Status = model.WebhookRetrying
+			w.retry(ctx, delivery, due)
+		} else {
+			attempt.Status = model.WebhookDead
+			dead = &model.WebhookDeadLetter{WebhookDelivery: delivery, Error: err.Error(), FailedAt: now}
+			w.logger.WarnContext(ctx, "webhook delivery given up",
+				slog.String("poll_id", pollID),
+				slog.String("webhook_id", delivery.WebhookID),
+				slog.String("event", string(delivery.Event.Type)),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	if err := w.storage.RecordWebhookAttempt(ctx, pollID, attempt, dead); err != nil {
+		w.logger.ErrorContext(ctx, "failed to record webhook attempt",
+			slog.String("poll_id", pollID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// retry queues delivery again at due time of its next attempt
+func (w *WebhookWorker) retry(ctx context.Context, delivery model.WebhookDelivery, due time.Time) {
+	if err := w.storage.EnqueueWebhookDeliveries(ctx, []model.WebhookDelivery{delivery}, due); err != nil {
+		w.logger.ErrorContext(ctx, "failed to retry webhook delivery",
+			slog.String("poll_id", delivery.Event.PollID),
+			slog.String("webhook_id", delivery.WebhookID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// send posts signed event to webhook, any status but 2xx is failure
+func (w *WebhookWorker) send(ctx context.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(&delivery.Event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "surway-webhooks/1.0")
+	req.Header.Set(webhookEventHeader, string(delivery.Event.Type))
+	req.Header.Set(webhookDeliveryHeader, delivery.ID)
+	req.Header.Set(webhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhookSignatureHeader, webhookSignature(webhook.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain response, so connection is reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookSignature returns "sha256=" and hex HMAC-SHA256 of "<timestamp>.<body>" keyed by webhook secret
+func webhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,533 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AlexeyLars/surway-service/internal/lib/terms"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// answerKeys are Redis keys holding answers to a poll or a survey question
+type answerKeys struct {
+	votes   string // hash: option index -> counter
+	ballots string // list: ranked ballots
+	scores  string // hash: "<option>:sum" and "<option>:<score>" -> counter
+	texts   string // list: text answers and write-ins
+	numbers string // hash: step index -> counter
+	matrix  string // hash: "<row>:<column>" -> counter
+	terms   string // sorted set: word cloud term -> counter
+	answers string // counter: word cloud answers
+	quiz    string // hash: "answered", "correct" -> counter of graded question
+}
+
+// all returns every answer key
+func (k answerKeys) all() []string {
+	return []string{k.votes, k.ballots, k.scores, k.texts, k.numbers, k.matrix, k.terms, k.answers, k.quiz}
+}
+
+// countVotes converts counters hash into option -> count map and total
+func countVotes(options []string, votesMap map[string]string) (map[string]int, int, error) {
+	votes := make(map[string]int)
+	total := 0
+
+	for i, option := range options {
+		field := fmt.Sprintf("%d", i)
+		count := 0
+		if countStr, ok := votesMap[field]; ok {
+			_, err := fmt.Sscanf(countStr, "%d", &count)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to convert votes into map: %w", err)
+			}
+		}
+		votes[option] = count
+		total += count
+	}
+
+	return votes, total, nil
+}
+
+// parseCounters converts values of counters hash to integers
+func parseCounters(data map[string]string) (map[string]int, error) {
+	counters := make(map[string]int, len(data))
+	for field, value := range data {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse counter %q: %w", field, err)
+		}
+		counters[field] = count
+	}
+	return counters, nil
+}
+
+// readBallots returns ranked ballots stored in list
+func (s *RedisStorage) readBallots(ctx context.Context, key string) ([][]int, error) {
+	data, err := s.client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ballots: %w", err)
+	}
+
+	ballots := make([][]int, 0, len(data))
+	for _, raw := range data {
+		var ballot []int
+		if err := json.Unmarshal([]byte(raw), &ballot); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ballot: %w", err)
+		}
+		ballots = append(ballots, ballot)
+	}
+
+	return ballots, nil
+}
+
+// readScores returns sum and histogram of scores per option index stored in hash
+func (s *RedisStorage) readScores(ctx context.Context, key string) (map[int]model.ScoreStats, error) {
+	data, err := s.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scores: %w", err)
+	}
+
+	// Fields are "<option>:sum" and "<option>:<score>"
+	scores := make(map[int]model.ScoreStats)
+	for field, value := range data {
+		option, bucket, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("unexpected scores field %q", field)
+		}
+
+		idx, err := strconv.Atoi(option)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse scores field %q: %w", field, err)
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse scores counter: %w", err)
+		}
+
+		stats := scores[idx]
+		if bucket == "sum" {
+			stats.Sum = count
+		} else {
+			score, err := strconv.Atoi(bucket)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse scores field %q: %w", field, err)
+			}
+			if stats.Distribution == nil {
+				stats.Distribution = make(map[int]int)
+			}
+			stats.Distribution[score] = count
+			stats.Count += count
+		}
+		scores[idx] = stats
+	}
+
+	return scores, nil
+}
+
+// readNumbers returns counters of numbers by step index stored in hash
+func (s *RedisStorage) readNumbers(ctx context.Context, key string) (map[int]int, error) {
+	data, err := s.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get numbers: %w", err)
+	}
+
+	counts := make(map[int]int, len(data))
+	for field, value := range data {
+		step, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse numbers field %q: %w", field, err)
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse numbers counter: %w", err)
+		}
+		counts[step] = count
+	}
+
+	return counts, nil
+}
+
+// readMatrix returns counters of picked columns by row index stored in hash
+func (s *RedisStorage) readMatrix(ctx context.Context, key string) (map[int]map[int]int, error) {
+	data, err := s.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get matrix: %w", err)
+	}
+
+	// Fields are "<row>:<column>"
+	matrix := make(map[int]map[int]int)
+	for field, value := range data {
+		rowStr, columnStr, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("unexpected matrix field %q", field)
+		}
+
+		row, err := strconv.Atoi(rowStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse matrix field %q: %w", field, err)
+		}
+		column, err := strconv.Atoi(columnStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse matrix field %q: %w", field, err)
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse matrix counter: %w", err)
+		}
+
+		if matrix[row] == nil {
+			matrix[row] = make(map[int]int)
+		}
+		matrix[row][column] = count
+	}
+
+	return matrix, nil
+}
+
+// readTerms returns most frequent terms stored in sorted set with number of answers
+func (s *RedisStorage) readTerms(ctx context.Context, termsKey, answersKey string, limit int) (*model.WordCloudResults, error) {
+	pipe := s.client.Pipeline()
+	topCmd := pipe.ZRevRangeWithScores(ctx, termsKey, 0, int64(limit-1))
+	distinctCmd := pipe.ZCard(ctx, termsKey)
+	answersCmd := pipe.Get(ctx, answersKey)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get terms: %w", err)
+	}
+
+	answers, err := answersCmd.Int()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to parse word cloud answers: %w", err)
+	}
+
+	results := &model.WordCloudResults{
+		Answers:  answers,
+		Distinct: int(distinctCmd.Val()),
+		Terms:    make([]model.TermCount, 0, len(topCmd.Val())),
+	}
+	for _, z := range topCmd.Val() {
+		results.Terms = append(results.Terms, model.TermCount{
+			Term:  z.Member.(string),
+			Count: int(z.Score),
+		})
+	}
+
+	return results, nil
+}
+
+// readTextAnswers returns page of text answers stored in list and their total number
+func (s *RedisStorage) readTextAnswers(ctx context.Context, key string, offset, limit int) ([]model.TextAnswer, int, error) {
+	pipe := s.client.Pipeline()
+	totalCmd := pipe.LLen(ctx, key)
+	dataCmd := pipe.LRange(ctx, key, int64(offset), int64(offset+limit-1))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, fmt.Errorf("failed to get text answers: %w", err)
+	}
+
+	answers := make([]model.TextAnswer, 0, len(dataCmd.Val()))
+	for _, raw := range dataCmd.Val() {
+		var answer model.TextAnswer
+		if err := json.Unmarshal([]byte(raw), &answer); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal text answer: %w", err)
+		}
+		answers = append(answers, answer)
+	}
+
+	return answers, int(totalCmd.Val()), nil
+}
+
+// validateAnswer checks answer against question type and settings
+func validateAnswer(question *model.Question, answer *model.Answer) error {
+	if !matchesType(answer, question.VotingType()) {
+		return ErrVoteTypeMismatch
+	}
+
+	switch question.VotingType() {
+	case model.PollTypeRanked:
+		return validateIndices(answer.Ranking, len(question.Options))
+	case model.PollTypeScore:
+		return validateScores(answer.Scores, len(question.Options), question.Scale)
+	case model.PollTypeBudget:
+		return validatePoints(answer.Points, len(question.Options), question.Budget)
+	case model.PollTypeText:
+		if answer.Text == "" {
+			return ErrEmptyVote
+		}
+		return validateText(answer.Text, question.MaxLength)
+	case model.PollTypeWordCloud:
+		// Phrase of punctuation only has no term
+		if terms.Normalize(answer.Text) == "" {
+			return ErrEmptyVote
+		}
+		return validateText(answer.Text, question.MaxLength)
+	case model.PollTypeMatrix:
+		return validateMatrix(answer.Matrix, len(question.Options), len(question.Columns))
+	case model.PollTypeNPS:
+		if answer.Rating == nil {
+			return ErrEmptyVote
+		}
+		if *answer.Rating < 0 || *answer.Rating >= len(question.Options) {
+			return ErrInvalidScore
+		}
+		return nil
+	case model.PollTypeNumeric:
+		if answer.Number == nil {
+			return ErrEmptyVote
+		}
+		if question.Range == nil {
+			return ErrInvalidNumber
+		}
+		if _, ok := question.Range.StepOf(*answer.Number); !ok {
+			return ErrInvalidNumber
+		}
+		return nil
+	default:
+		if answer.Text != "" {
+			if !question.AllowOther {
+				return ErrVoteTypeMismatch
+			}
+			if err := validateText(answer.Text, question.MaxLength); err != nil {
+				return err
+			}
+			// Write-in alone is a complete vote
+			if len(answer.OptionIndices) == 0 {
+				return nil
+			}
+		}
+		return validateIndices(answer.OptionIndices, len(question.Options))
+	}
+}
+
+// queueAnswer adds writes of validated answer to pipeline, keys created on first answer expire at expiresAt
+func queueAnswer(ctx context.Context, pipe redis.Pipeliner, keys answerKeys, question *model.Question, answer *model.Answer, expiresAt time.Time) error {
+	for field, count := range answerVotes(question, answer) {
+		pipe.HIncrBy(ctx, keys.votes, field, int64(count))
+	}
+
+	switch question.VotingType() {
+	case model.PollTypeRanked:
+		ballot, err := json.Marshal(answer.Ranking)
+		if err != nil {
+			return fmt.Errorf("failed to marshal ballot: %w", err)
+		}
+
+		// Ballot is kept for tabulation
+		pipe.RPush(ctx, keys.ballots, ballot)
+		pipe.ExpireAt(ctx, keys.ballots, expiresAt)
+	case model.PollTypeScore:
+		// Sum and histogram are kept for statistics
+		for idx, score := range answer.Scores {
+			pipe.HIncrBy(ctx, keys.scores, fmt.Sprintf("%d:sum", idx), int64(score))
+			pipe.HIncrBy(ctx, keys.scores, fmt.Sprintf("%d:%d", idx, score), 1)
+		}
+		pipe.ExpireAt(ctx, keys.scores, expiresAt)
+	case model.PollTypeWordCloud:
+		pipe.ZIncrBy(ctx, keys.terms, 1, terms.Normalize(answer.Text))
+		pipe.Incr(ctx, keys.answers)
+		pipe.ExpireAt(ctx, keys.terms, expiresAt)
+		pipe.ExpireAt(ctx, keys.answers, expiresAt)
+	case model.PollTypeMatrix:
+		// Matrix keeps picked columns per row
+		for row, column := range answer.Matrix {
+			pipe.HIncrBy(ctx, keys.matrix, fmt.Sprintf("%d:%d", row, column), 1)
+		}
+		pipe.ExpireAt(ctx, keys.matrix, expiresAt)
+	case model.PollTypeNumeric:
+		// Numbers are pre-bucketed by step, hash size is bounded by range
+		step, _ := question.Range.StepOf(*answer.Number)
+		pipe.HIncrBy(ctx, keys.numbers, fmt.Sprintf("%d", step), 1)
+		pipe.ExpireAt(ctx, keys.numbers, expiresAt)
+	}
+
+	// Text answers, word cloud phrases and write-ins are kept as records
+	if answer.Text != "" {
+		record, err := json.Marshal(model.TextAnswer{Text: answer.Text, CreatedAt: time.Now().UTC()})
+		if err != nil {
+			return fmt.Errorf("failed to marshal text answer: %w", err)
+		}
+		pipe.RPush(ctx, keys.texts, record)
+		pipe.ExpireAt(ctx, keys.texts, expiresAt)
+	}
+
+	if question.Graded() {
+		pipe.HIncrBy(ctx, keys.quiz, "answered", 1)
+		if question.IsCorrect(answer) {
+			pipe.HIncrBy(ctx, keys.quiz, "correct", 1)
+		}
+		pipe.ExpireAt(ctx, keys.quiz, expiresAt)
+	}
+
+	return nil
+}
+
+// answerVotes returns increments of votes counters made by validated answer
+func answerVotes(question *model.Question, answer *model.Answer) map[string]int {
+	votes := make(map[string]int)
+
+	switch question.VotingType() {
+	case model.PollTypeRanked:
+		// Counter holds first preferences
+		votes[fmt.Sprintf("%d", answer.Ranking[0])]++
+	case model.PollTypeScore:
+		// Counter holds number of ratings
+		for idx := range answer.Scores {
+			votes[fmt.Sprintf("%d", idx)]++
+		}
+	case model.PollTypeBudget:
+		// Counters hold points given to options
+		for idx, points := range answer.Points {
+			if points > 0 {
+				votes[fmt.Sprintf("%d", idx)] += points
+			}
+		}
+	case model.PollTypeMatrix:
+		// Counter holds number of rated rows
+		for row := range answer.Matrix {
+			votes[fmt.Sprintf("%d", row)]++
+		}
+	case model.PollTypeNPS:
+		// Each rating has its own counter
+		votes[fmt.Sprintf("%d", *answer.Rating)]++
+	case model.PollTypeWordCloud, model.PollTypeNumeric:
+		// Counted in their own keys
+	default:
+		for _, idx := range answer.OptionIndices {
+			votes[fmt.Sprintf("%d", idx)]++
+		}
+	}
+
+	return votes
+}
+
+// validateIndices checks that indices are non-empty, in range and unique
+func validateIndices(indices []int, optionsCount int) error {
+	if len(indices) == 0 {
+		return ErrEmptyVote
+	}
+
+	for _, idx := range indices {
+		if idx < 0 || idx >= optionsCount {
+			return ErrInvalidOption
+		}
+	}
+
+	seen := make(map[int]bool)
+	for _, idx := range indices {
+		if seen[idx] {
+			return ErrDuplicateOption
+		}
+		seen[idx] = true
+	}
+
+	return nil
+}
+
+// validateText checks that text fits maximum length in characters
+func validateText(text string, maxLength int) error {
+	if utf8.RuneCountInString(text) > maxLength {
+		return ErrTextTooLong
+	}
+	return nil
+}
+
+// validateMatrix checks that matrix answer is non-empty and refers to existing rows and columns
+func validateMatrix(matrix map[int]int, rowsCount, columnsCount int) error {
+	if len(matrix) == 0 {
+		return ErrEmptyVote
+	}
+
+	for row, column := range matrix {
+		if row < 0 || row >= rowsCount {
+			return ErrInvalidOption
+		}
+		if column < 0 || column >= columnsCount {
+			return ErrInvalidColumn
+		}
+	}
+
+	return nil
+}
+
+// validateScores checks that scores are non-empty, given to existing options and fit the scale
+func validateScores(scores map[int]int, optionsCount int, scale *model.ScoreScale) error {
+	if len(scores) == 0 {
+		return ErrEmptyVote
+	}
+
+	for idx, score := range scores {
+		if idx < 0 || idx >= optionsCount {
+			return ErrInvalidOption
+		}
+		if scale == nil || score < scale.Min || score > scale.Max {
+			return ErrInvalidScore
+		}
+	}
+
+	return nil
+}
+
+// validatePoints checks that points are given to existing options and fit the budget
+func validatePoints(points map[int]int, optionsCount int, budget *model.PointBudget) error {
+	if budget == nil {
+		return ErrBudgetExceeded
+	}
+
+	spent := 0
+	for idx, p := range points {
+		if idx < 0 || idx >= optionsCount {
+			return ErrInvalidOption
+		}
+		if p < 0 {
+			return ErrInvalidPoints
+		}
+		// Single allocation above budget exceeds it in any cost model
+		if p > budget.Points {
+			return ErrBudgetExceeded
+		}
+
+		if budget.Quadratic {
+			spent += p * p
+		} else {
+			spent += p
+		}
+	}
+
+	if spent == 0 {
+		return ErrEmptyVote
+	}
+	if spent > budget.Points {
+		return ErrBudgetExceeded
+	}
+
+	return nil
+}
+
+// matchesType checks that answer has no fields of other question types,
+// word cloud answers are text and choice answers may carry write-in text
+func matchesType(answer *model.Answer, pollType model.PollType) bool {
+	filled := map[model.PollType]bool{
+		model.PollTypeChoice:  len(answer.OptionIndices) > 0,
+		model.PollTypeRanked:  len(answer.Ranking) > 0,
+		model.PollTypeScore:   len(answer.Scores) > 0,
+		model.PollTypeBudget:  len(answer.Points) > 0,
+		model.PollTypeText:    answer.Text != "",
+		model.PollTypeNumeric: answer.Number != nil,
+		model.PollTypeNPS:     answer.Rating != nil,
+		model.PollTypeMatrix:  len(answer.Matrix) > 0,
+	}
+
+	for t, ok := range filled {
+		if t == model.PollTypeText && (pollType == model.PollTypeChoice || pollType == model.PollTypeWordCloud) {
+			continue
+		}
+		if ok && t != pollType {
+			return false
+		}
+	}
+
+	return true
+}
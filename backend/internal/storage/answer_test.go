@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePoints(t *testing.T) {
+	linear := &model.PointBudget{Points: 10}
+	quadratic := &model.PointBudget{Points: 10, Quadratic: true}
+
+	tests := []struct {
+		name    string
+		points  map[int]int
+		budget  *model.PointBudget
+		wantErr error
+	}{
+		{
+			name:   "spend whole budget",
+			points: map[int]int{0: 5, 1: 3, 2: 2},
+			budget: linear,
+		},
+		{
+			name:   "spend part of budget",
+			points: map[int]int{1: 4},
+			budget: linear,
+		},
+		{
+			name:    "budget exceeded",
+			points:  map[int]int{0: 6, 1: 5},
+			budget:  linear,
+			wantErr: ErrBudgetExceeded,
+		},
+		{
+			name:   "quadratic cost within budget",
+			points: map[int]int{0: 3, 1: 1}, // 9 + 1
+			budget: quadratic,
+		},
+		{
+			name:    "quadratic cost exceeds budget",
+			points:  map[int]int{0: 3, 1: 2}, // 9 + 4
+			budget:  quadratic,
+			wantErr: ErrBudgetExceeded,
+		},
+		{
+			name:    "huge allocation",
+			points:  map[int]int{0: 1 << 40},
+			budget:  quadratic,
+			wantErr: ErrBudgetExceeded,
+		},
+		{
+			name:    "negative points",
+			points:  map[int]int{0: 2, 1: -3},
+			budget:  linear,
+			wantErr: ErrInvalidPoints,
+		},
+		{
+			name:    "unknown option",
+			points:  map[int]int{3: 1},
+			budget:  linear,
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "only zero allocations",
+			points:  map[int]int{0: 0},
+			budget:  linear,
+			wantErr: ErrEmptyVote,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePoints(tt.points, 3, tt.budget)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMatchesType(t *testing.T) {
+	assert.True(t, matchesType(&model.Answer{OptionIndices: []int{0}}, model.PollTypeChoice))
+	assert.True(t, matchesType(&model.Answer{Points: map[int]int{0: 1}}, model.PollTypeBudget))
+	assert.True(t, matchesType(&model.Answer{}, model.PollTypeRanked))
+	assert.False(t, matchesType(&model.Answer{OptionIndices: []int{0}}, model.PollTypeBudget))
+	assert.False(t, matchesType(&model.Answer{Ranking: []int{0}, Scores: map[int]int{0: 1}}, model.PollTypeRanked))
+	assert.True(t, matchesType(&model.Answer{Text: "Helix"}, model.PollTypeText))
+	assert.True(t, matchesType(&model.Answer{OptionIndices: []int{0}, Text: "Helix"}, model.PollTypeChoice))
+	assert.False(t, matchesType(&model.Answer{Text: "Helix"}, model.PollTypeScore))
+	assert.False(t, matchesType(&model.Answer{OptionIndices: []int{0}}, model.PollTypeText))
+	assert.True(t, matchesType(&model.Answer{Text: "Chaotic"}, model.PollTypeWordCloud))
+	assert.False(t, matchesType(&model.Answer{OptionIndices: []int{0}, Text: "Chaotic"}, model.PollTypeWordCloud))
+}
+
+func TestValidateAnswer_WordCloud(t *testing.T) {
+	question := &model.Question{Type: model.PollTypeWordCloud, MaxLength: 10, TopTerms: 20}
+
+	assert.NoError(t, validateAnswer(question, &model.Answer{Text: "Chaotic"}))
+	assert.NoError(t, validateAnswer(question, &model.Answer{Text: "Too long!"}))
+	assert.ErrorIs(t, validateAnswer(question, &model.Answer{Text: "Rather chaotic"}), ErrTextTooLong)
+	assert.ErrorIs(t, validateAnswer(question, &model.Answer{Text: "?!"}), ErrEmptyVote)
+	assert.ErrorIs(t, validateAnswer(question, &model.Answer{}), ErrEmptyVote)
+	assert.ErrorIs(t, validateAnswer(question, &model.Answer{Rating: new(int)}), ErrVoteTypeMismatch)
+}
+
+func TestValidateAnswer_Text(t *testing.T) {
+	text := &model.Question{Type: model.PollTypeText, MaxLength: 10}
+	choice := &model.Question{Type: model.PollTypeChoice, Options: []string{"Vim", "Emacs"}}
+	other := &model.Question{Type: model.PollTypeChoice, Options: []string{"Vim", "Emacs"}, AllowOther: true, MaxLength: 10}
+
+	tests := []struct {
+		name     string
+		question *model.Question
+		answer   *model.Answer
+		wantErr  error
+	}{
+		{name: "text answer", question: text, answer: &model.Answer{Text: "Nice talk"}},
+		{name: "length counted in characters", question: text, answer: &model.Answer{Text: "Привет мир"}},
+		{name: "text too long", question: text, answer: &model.Answer{Text: "Nice talk!!"}, wantErr: ErrTextTooLong},
+		{name: "empty text", question: text, answer: &model.Answer{}, wantErr: ErrEmptyVote},
+		{name: "write-in alone", question: other, answer: &model.Answer{Text: "Helix"}},
+		{name: "write-in with options", question: other, answer: &model.Answer{OptionIndices: []int{1}, Text: "Helix"}},
+		{name: "write-in with invalid option", question: other, answer: &model.Answer{OptionIndices: []int{2}, Text: "Helix"}, wantErr: ErrInvalidOption},
+		{name: "write-in too long", question: other, answer: &model.Answer{Text: "Sublime Text"}, wantErr: ErrTextTooLong},
+		{name: "write-in not allowed", question: choice, answer: &model.Answer{OptionIndices: []int{0}, Text: "Helix"}, wantErr: ErrVoteTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnswer(tt.question, tt.answer)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateAnswer_Numeric(t *testing.T) {
+	question := &model.Question{Type: model.PollTypeNumeric, Range: &model.NumericRange{Min: 0, Max: 10, Step: 0.5}}
+	number := func(n float64) *float64 { return &n }
+
+	tests := []struct {
+		name    string
+		answer  *model.Answer
+		wantErr error
+	}{
+		{name: "lower bound", answer: &model.Answer{Number: number(0)}},
+		{name: "upper bound", answer: &model.Answer{Number: number(10)}},
+		{name: "fractional step", answer: &model.Answer{Number: number(7.5)}},
+		{name: "floating point error", answer: &model.Answer{Number: number(0.1 + 0.2 + 0.2)}},
+		{name: "between steps", answer: &model.Answer{Number: number(7.3)}, wantErr: ErrInvalidNumber},
+		{name: "below range", answer: &model.Answer{Number: number(-0.5)}, wantErr: ErrInvalidNumber},
+		{name: "above range", answer: &model.Answer{Number: number(10.5)}, wantErr: ErrInvalidNumber},
+		{name: "missing number", answer: &model.Answer{}, wantErr: ErrEmptyVote},
+		{name: "options instead of number", answer: &model.Answer{OptionIndices: []int{0}}, wantErr: ErrVoteTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnswer(question, tt.answer)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateAnswer_NPS(t *testing.T) {
+	question := &model.Question{Type: model.PollTypeNPS, Options: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}}
+	rating := func(r int) *int { return &r }
+
+	assert.NoError(t, validateAnswer(question, &model.Answer{Rating: rating(0)}))
+	assert.NoError(t, validateAnswer(question, &model.Answer{Rating: rating(10)}))
+	assert.ErrorIs(t, validateAnswer(question, &model.Answer{Rating: rating(11)}), ErrInvalidScore)
+	assert.ErrorIs(t, validateAnswer(question, &model.Answer{Rating: rating(-1)}), ErrInvalidScore)
+	assert.ErrorIs(t, validateAnswer(question, &model.Answer{}), ErrEmptyVote)
+	assert.ErrorIs(t, validateAnswer(question, &model.Answer{OptionIndices: []int{9}}), ErrVoteTypeMismatch)
+}
+
+func TestValidateAnswer_Matrix(t *testing.T) {
+	question := &model.Question{
+		Type:    model.PollTypeMatrix,
+		Columns: []string{"Disagree", "Neutral", "Agree"},
+		Options: []string{"Docs are clear", "Builds are fast"},
+	}
+
+	tests := []struct {
+		name    string
+		answer  *model.Answer
+		wantErr error
+	}{
+		{name: "all rows", answer: &model.Answer{Matrix: map[int]int{0: 2, 1: 0}}},
+		{name: "some rows", answer: &model.Answer{Matrix: map[int]int{1: 1}}},
+		{name: "unknown row", answer: &model.Answer{Matrix: map[int]int{2: 1}}, wantErr: ErrInvalidOption},
+		{name: "negative row", answer: &model.Answer{Matrix: map[int]int{-1: 1}}, wantErr: ErrInvalidOption},
+		{name: "unknown column", answer: &model.Answer{Matrix: map[int]int{0: 3}}, wantErr: ErrInvalidColumn},
+		{name: "negative column", answer: &model.Answer{Matrix: map[int]int{0: -1}}, wantErr: ErrInvalidColumn},
+		{name: "empty matrix", answer: &model.Answer{Matrix: map[int]int{}}, wantErr: ErrEmptyVote},
+		{name: "scores instead of matrix", answer: &model.Answer{Scores: map[int]int{0: 1}}, wantErr: ErrVoteTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnswer(question, tt.answer)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckStatus(t *testing.T) {
+	assert.NoError(t, checkStatus(model.PollStatusOpen))
+	assert.ErrorIs(t, checkStatus(model.PollStatusScheduled), ErrNotOpen)
+	assert.ErrorIs(t, checkStatus(model.PollStatusClosed), ErrClosed)
+}
+
+func TestPollKeys(t *testing.T) {
+	keys := pollKeys("abc1234")
+
+	// Every answer key must follow poll expiry when it is extended
+	answers := reflect.ValueOf(pollAnswerKeys("abc1234"))
+	for i := 0; i < answers.NumField(); i++ {
+		assert.Contains(t, keys, answers.Field(i).String(), answers.Type().Field(i).Name)
+	}
+	assert.Contains(t, keys, pollInfoKey("abc1234"))
+	assert.Contains(t, keys, pollAdminKey("abc1234"))
+	assert.Contains(t, keys, pollPasswordKey("abc1234"))
+	assert.Contains(t, keys, pollVotersKey("abc1234"))
+	assert.Contains(t, keys, pollInvitesKey("abc1234"))
+	assert.Contains(t, keys, pollUsedInvitesKey("abc1234"))
+	assert.Contains(t, keys, pollReceiptsKey("abc1234"))
+	assert.Contains(t, keys, pollLedgerKey("abc1234"))
+	assert.Contains(t, keys, pollNotificationKey("abc1234"))
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// pollChatKey is response URL of the latest action on chat message of poll, it expires with the URL
+func pollChatKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:chat", pollID)
+}
+
+// pollChatThrottleKey exists while live counts of poll were just posted by some worker
+func pollChatThrottleKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:chat:throttle", pollID)
+}
+
+// SetChatResponseURL keeps response URL of chat message of poll for ttl, live counts are posted to it
+func (s *RedisStorage) SetChatResponseURL(ctx context.Context, pollID, responseURL string, ttl time.Duration) error {
+	if err := s.client.Set(ctx, pollChatKey(pollID), responseURL, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save chat response url: %w", err)
+	}
+	return nil
+}
+
+// ClaimChatUpdate returns response URL to post live counts of poll to, workers get it once per interval.
+// It is empty when poll has no chat message with valid response URL or update is already claimed.
+func (s *RedisStorage) ClaimChatUpdate(ctx context.Context, pollID string, interval time.Duration) (string, error) {
+	responseURL, err := s.client.Get(ctx, pollChatKey(pollID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get chat response url: %w", err)
+	}
+
+	claimed, err := s.client.SetNX(ctx, pollChatThrottleKey(pollID), 1, interval).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to claim chat update: %w", err)
+	}
+	if !claimed {
+		return "", nil
+	}
+	return responseURL, nil
+}
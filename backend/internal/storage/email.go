@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// emailFinalResultsLead is how long before expiry polls without closing time are reported as closed,
+// so results still exist when email is rendered
+const emailFinalResultsLead = time.Minute
+
+// emailQueueKey is sorted set of emails scored by due time
+const emailQueueKey = "emails:queue"
+
+// emailScheduleKey is sorted set of polls scored by time their closing is reported
+const emailScheduleKey = "emails:schedule"
+
+// pollNotificationKey is email notification opt-in of poll creator, it is kept apart from poll info to hide address
+func pollNotificationKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:notification", pollID)
+}
+
+// emailRecipientKey counts emails queued for recipient within rate limit window, address is kept hashed
+func emailRecipientKey(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "emails:recipient:" + hex.EncodeToString(sum[:])
+}
+
+// emailClosingTime returns when closing of poll is reported
+func emailClosingTime(poll *model.Poll) time.Time {
+	if poll.ClosesAt != nil {
+		return *poll.ClosesAt
+	}
+	return poll.ExpiresAt.Add(-emailFinalResultsLead)
+}
+
+// rescheduleEmailTrigger moves closing report of poll that is still pending, polls without notification have none
+func rescheduleEmailTrigger(ctx context.Context, pipe redis.Pipeliner, pollID string, at time.Time) {
+	pipe.ZAddXX(ctx, emailScheduleKey, redis.Z{Score: dueScore(at), Member: pollID})
+}
+
+// SetEmailNotification saves email notification of poll and schedules report of its closing
+func (s *RedisStorage) SetEmailNotification(ctx context.Context, poll *model.Poll, notification *model.EmailNotification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email notification: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, pollNotificationKey(poll.ID), data, 0)
+		pipe.ExpireAt(ctx, pollNotificationKey(poll.ID), poll.ExpiresAt)
+		pipe.ZAdd(ctx, emailScheduleKey, redis.Z{Score: dueScore(emailClosingTime(poll)), Member: poll.ID})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save email notification: %w", err)
+	}
+	return nil
+}
+
+// GetEmailNotification returns email notification of poll, it is nil when creator hasn't opted in
+func (s *RedisStorage) GetEmailNotification(ctx context.Context, pollID string) (*model.EmailNotification, error) {
+	data, err := s.client.Get(ctx, pollNotificationKey(pollID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email notification: %w", err)
+	}
+
+	var notification model.EmailNotification
+	if err := json.Unmarshal([]byte(data), &notification); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal email notification: %w", err)
+	}
+	return &notification, nil
+}
+
+// ClaimVoteThreshold marks vote threshold of poll as reported once ballots reach it and returns notification
+// with number of ballots. Notification is nil when threshold isn't reached, is already reported or there is none.
+func (s *RedisStorage) ClaimVoteThreshold(ctx context.Context, pollID string) (*model.EmailNotification, int64, error) {
+	var claimed *model.EmailNotification
+	var ballots int64
+	key := pollNotificationKey(pollID)
+
+	err := s.watchTx(ctx, func(tx *redis.Tx) error {
+		claimed = nil
+		data, err := tx.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get email notification: %w", err)
+		}
+
+		var notification model.EmailNotification
+		if err := json.Unmarshal([]byte(data), &notification); err != nil {
+			return fmt.Errorf("failed to unmarshal email notification: %w", err)
+		}
+		if notification.Threshold <= 0 || notification.ThresholdSent {
+			return nil
+		}
+
+		head, err := ledgerHead(ctx, tx, pollID)
+		if err != nil {
+			return err
+		}
+		if head == nil || head.Seq < notification.Threshold {
+			return nil
+		}
+
+		notification.ThresholdSent = true
+		updated, err := json.Marshal(&notification)
+		if err != nil {
+			return fmt.Errorf("failed to marshal email notification: %w", err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, updated, redis.KeepTTL)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save email notification: %w", err)
+		}
+
+		claimed = &notification
+		ballots = head.Seq
+		return nil
+	}, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	return claimed, ballots, nil
+}
+
+// ReleaseVoteThreshold marks vote threshold of poll as not reported, so it is claimed again when its email isn't queued
+func (s *RedisStorage) ReleaseVoteThreshold(ctx context.Context, pollID string) error {
+	key := pollNotificationKey(pollID)
+	return s.watchTx(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get email notification: %w", err)
+		}
+
+		var notification model.EmailNotification
+		if err := json.Unmarshal([]byte(data), &notification); err != nil {
+			return fmt.Errorf("failed to unmarshal email notification: %w", err)
+		}
+		if !notification.ThresholdSent {
+			return nil
+		}
+
+		notification.ThresholdSent = false
+		updated, err := json.Marshal(&notification)
+		if err != nil {
+			return fmt.Errorf("failed to marshal email notification: %w", err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, updated, redis.KeepTTL)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save email notification: %w", err)
+		}
+		return nil
+	}, key)
+}
+
+// AddRecipientEmail counts email queued for recipient and returns emails queued for it within rate limit window,
+// window starts with the first email
+func (s *RedisStorage) AddRecipientEmail(ctx context.Context, email string, window time.Duration) (int, error) {
+	key := emailRecipientKey(email)
+	pipe := s.client.TxPipeline()
+	emails := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to count recipient email: %w", err)
+	}
+	return int(emails.Val()), nil
+}
+
+// ClaimEmailTriggers removes polls whose closing is due from schedule and returns their IDs,
+// every poll is claimed by one caller only
+func (s *RedisStorage) ClaimEmailTriggers(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	due, err := s.claimDue(ctx, emailScheduleKey, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var pollIDs []string
+	for _, z := range due {
+		pollIDs = append(pollIDs, z.Member.(string))
+	}
+	return pollIDs, nil
+}
+
+// EnqueueEmails queues emails due at given time
+func (s *RedisStorage) EnqueueEmails(ctx context.Context, deliveries []model.EmailDelivery, at time.Time) error {
+	return enqueueDue(ctx, s, emailQueueKey, deliveries, at)
+}
+
+// ClaimEmails removes due emails from queue and returns them, every email is claimed by one caller only
+func (s *RedisStorage) ClaimEmails(ctx context.Context, now time.Time, limit int) ([]model.EmailDelivery, error) {
+	return claimDueItems[model.EmailDelivery](ctx, s, emailQueueKey, now, limit)
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailClosingTime(t *testing.T) {
+	expiresAt := time.Now().Add(24 * time.Hour)
+	closesAt := time.Now().Add(time.Hour)
+
+	poll := &model.Poll{ExpiresAt: expiresAt, Schedule: model.Schedule{ClosesAt: &closesAt}}
+	assert.Equal(t, closesAt, emailClosingTime(poll))
+
+	// Results of poll without closing time are gone at expiry, so they are reported before it
+	poll.ClosesAt = nil
+	assert.Equal(t, expiresAt.Add(-emailFinalResultsLead), emailClosingTime(poll))
+}
+
+func TestEmailRecipientKey(t *testing.T) {
+	key := emailRecipientKey("Me@Example.com ")
+
+	assert.Equal(t, emailRecipientKey("me@example.com"), key, "address case doesn't matter")
+	assert.NotContains(t, key, "example.com", "address isn't kept")
+	assert.NotEqual(t, emailRecipientKey("you@example.com"), key)
+}
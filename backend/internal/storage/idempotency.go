@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// idempotencyPendingTTL bounds how long key stays reserved if its first request never completes
+const idempotencyPendingTTL = time.Minute
+
+// IdempotencyStorage keeps responses of requests with Idempotency-Key
+type IdempotencyStorage interface {
+	BeginIdempotent(ctx context.Context, key, bodyHash string) (*model.IdempotentResponse, error)
+	SaveIdempotent(ctx context.Context, key string, response *model.IdempotentResponse, window time.Duration) error
+	ReleaseIdempotent(ctx context.Context, key string) error
+}
+
+func idempotencyKey(key string) string {
+	return fmt.Sprintf("idempotency:%s", key)
+}
+
+// BeginIdempotent reserves key for request with given body hash, it returns nil when key is new
+// and stored response, possibly not completed yet, when key is already taken
+func (s *RedisStorage) BeginIdempotent(ctx context.Context, key, bodyHash string) (*model.IdempotentResponse, error) {
+	data, err := json.Marshal(&model.IdempotentResponse{BodyHash: bodyHash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal idempotent response: %w", err)
+	}
+
+	// Key may expire between SETNX and GET, then it is reserved again
+	for i := 0; i < maxTxRetries; i++ {
+		reserved, err := s.client.SetNX(ctx, idempotencyKey(key), data, idempotencyPendingTTL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		stored, err := s.client.Get(ctx, idempotencyKey(key)).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get idempotent response: %w", err)
+		}
+
+		var response model.IdempotentResponse
+		if err := json.Unmarshal(stored, &response); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal idempotent response: %w", err)
+		}
+		return &response, nil
+	}
+	return nil, fmt.Errorf("failed to reserve idempotency key after %d retries", maxTxRetries)
+}
+
+// SaveIdempotent stores completed response of reserved key for window
+func (s *RedisStorage) SaveIdempotent(ctx context.Context, key string, response *model.IdempotentResponse, window time.Duration) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotent response: %w", err)
+	}
+	if err := s.client.Set(ctx, idempotencyKey(key), data, window).Err(); err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotent frees reserved key so request can be retried
+func (s *RedisStorage) ReleaseIdempotent(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, idempotencyKey(key)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// pollLedgerKey is stream of accepted votes, each entry is hash-chained to the previous one
+func pollLedgerKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:ledger", pollID)
+}
+
+// ledgerEntry is accepted vote in poll ledger, ballot is kept as written so its hash can be recomputed
+type ledgerEntry struct {
+	Seq       int64
+	Timestamp time.Time
+	Ballot    string // JSON of answer
+	PrevHash  string
+	Hash      string
+}
+
+// newLedgerEntry returns entry of answer chained to head, head is nil for the first entry
+func newLedgerEntry(pollID string, head *ledgerEntry, answer *model.Answer, now time.Time) (*ledgerEntry, error) {
+	ballot, err := json.Marshal(answer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ballot: %w", err)
+	}
+
+	entry := &ledgerEntry{
+		Seq:       1,
+		Timestamp: now.UTC(),
+		Ballot:    string(ballot),
+	}
+	if head != nil {
+		entry.Seq = head.Seq + 1
+		entry.PrevHash = head.Hash
+	}
+	entry.Hash = entry.digest(pollID)
+	return entry, nil
+}
+
+// digest returns hex SHA-256 of poll ID and entry fields including previous hash
+func (e *ledgerEntry) digest(pollID string) string {
+	sum := sha256.Sum256([]byte(pollID + "\n" + strconv.FormatInt(e.Seq, 10) + "\n" + e.PrevHash + "\n" +
+		e.Timestamp.Format(time.RFC3339Nano) + "\n" + e.Ballot))
+	return hex.EncodeToString(sum[:])
+}
+
+// values returns stream fields of entry
+func (e *ledgerEntry) values() map[string]interface{} {
+	return map[string]interface{}{
+		"seq":    e.Seq,
+		"time":   e.Timestamp.Format(time.RFC3339Nano),
+		"ballot": e.Ballot,
+		"prev":   e.PrevHash,
+		"hash":   e.Hash,
+	}
+}
+
+// parseLedgerEntry reads entry from stream message, missing fields are left empty to fail chain check
+func parseLedgerEntry(message redis.XMessage) *ledgerEntry {
+	field := func(name string) string {
+		value, _ := message.Values[name].(string)
+		return value
+	}
+
+	entry := &ledgerEntry{
+		Ballot:   field("ballot"),
+		PrevHash: field("prev"),
+		Hash:     field("hash"),
+	}
+	entry.Seq, _ = strconv.ParseInt(field("seq"), 10, 64)
+	entry.Timestamp, _ = time.Parse(time.RFC3339Nano, field("time"))
+	return entry
+}
+
+// ledgerHead returns the last entry of poll ledger, it is nil while ledger is empty
+func ledgerHead(ctx context.Context, tx *redis.Tx, pollID string) (*ledgerEntry, error) {
+	messages, err := tx.XRevRangeN(ctx, pollLedgerKey(pollID), "+", "-", 1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger head: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	return parseLedgerEntry(messages[0]), nil
+}
+
+// auditLedger recomputes hash chain and tally of ledger entries and compares tally with vote counters.
+// Tally is recomputed only up to the first broken entry, since later entries can't be trusted.
+func auditLedger(poll *model.Poll, entries []*ledgerEntry, counters map[string]int) *model.LedgerAudit {
+	audit := &model.LedgerAudit{
+		PollID:     poll.ID,
+		Entries:    len(entries),
+		ChainValid: true,
+	}
+
+	question := poll.Question()
+	tally := make(map[string]int)
+	prevHash := ""
+	for i, entry := range entries {
+		var answer model.Answer
+		valid := entry.Seq == int64(i+1) && entry.PrevHash == prevHash && entry.Hash == entry.digest(poll.ID) &&
+			json.Unmarshal([]byte(entry.Ballot), &answer) == nil && validateAnswer(&question, &answer) == nil
+		if !valid {
+			seq := int64(i + 1)
+			audit.ChainValid = false
+			audit.BrokenAt = &seq
+			break
+		}
+
+		for field, count := range answerVotes(&question, &answer) {
+			tally[field] += count
+		}
+		prevHash = entry.Hash
+	}
+	if len(entries) > 0 {
+		audit.HeadHash = entries[len(entries)-1].Hash
+	}
+
+	fields := make([]string, 0, len(tally)+len(counters))
+	for field := range tally {
+		fields = append(fields, field)
+	}
+	for field := range counters {
+		if _, ok := tally[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, errA := strconv.Atoi(fields[i])
+		b, errB := strconv.Atoi(fields[j])
+		if errA != nil || errB != nil {
+			return fields[i] < fields[j]
+		}
+		return a < b
+	})
+
+	for _, field := range fields {
+		if tally[field] != counters[field] {
+			audit.Divergences = append(audit.Divergences, model.CounterDivergence{
+				Field:   field,
+				Ledger:  tally[field],
+				Counter: counters[field],
+			})
+		}
+	}
+
+	audit.Consistent = audit.ChainValid && len(audit.Divergences) == 0
+	return audit
+}
+
+// AuditLedger compares hash-chained ledger of poll with its vote counters, both are read in one transaction
+func (s *RedisStorage) AuditLedger(ctx context.Context, pollID string) (*model.LedgerAudit, error) {
+	poll, err := s.GetPoll(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ledgerCmd *redis.XMessageSliceCmd
+		votesCmd  *redis.MapStringStringCmd
+	)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		ledgerCmd = pipe.XRange(ctx, pollLedgerKey(pollID), "-", "+")
+		votesCmd = pipe.HGetAll(ctx, pollVotesKey(pollID))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger: %w", err)
+	}
+
+	counters, err := parseCounters(votesCmd.Val())
+	if err != nil {
+		return nil, err
+	}
+
+	messages := ledgerCmd.Val()
+	entries := make([]*ledgerEntry, len(messages))
+	for i, message := range messages {
+		entries[i] = parseLedgerEntry(message)
+	}
+
+	return auditLedger(poll, entries, counters), nil
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLedger returns chained entries of answers
+func newLedger(t *testing.T, pollID string, answers ...model.Answer) []*ledgerEntry {
+	var (
+		entries []*ledgerEntry
+		head    *ledgerEntry
+	)
+	for i := range answers {
+		entry, err := newLedgerEntry(pollID, head, &answers[i], time.Now())
+		require.NoError(t, err)
+		entries = append(entries, entry)
+		head = entry
+	}
+	return entries
+}
+
+func TestLedgerEntry(t *testing.T) {
+	entries := newLedger(t, "poll1", model.Answer{OptionIndices: []int{0}}, model.Answer{OptionIndices: []int{1}})
+
+	assert.Equal(t, int64(1), entries[0].Seq)
+	assert.Empty(t, entries[0].PrevHash)
+	assert.Equal(t, int64(2), entries[1].Seq)
+	assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+	assert.NotEqual(t, entries[0].digest("poll2"), entries[0].Hash, "hash is bound to poll")
+}
+
+func TestAuditLedger(t *testing.T) {
+	poll := &model.Poll{ID: "poll1", Options: []string{"A", "B", "C"}}
+	answers := []model.Answer{
+		{OptionIndices: []int{0}},
+		{OptionIndices: []int{0, 2}},
+		{OptionIndices: []int{1}},
+	}
+
+	t.Run("consistent", func(t *testing.T) {
+		audit := auditLedger(poll, newLedger(t, "poll1", answers...), map[string]int{"0": 2, "1": 1, "2": 1})
+
+		assert.True(t, audit.ChainValid)
+		assert.True(t, audit.Consistent)
+		assert.Equal(t, 3, audit.Entries)
+		assert.Empty(t, audit.Divergences)
+	})
+
+	t.Run("edited counter", func(t *testing.T) {
+		audit := auditLedger(poll, newLedger(t, "poll1", answers...), map[string]int{"0": 2, "1": 7, "2": 1})
+
+		assert.True(t, audit.ChainValid)
+		assert.False(t, audit.Consistent)
+		assert.Equal(t, []model.CounterDivergence{{Field: "1", Ledger: 1, Counter: 7}}, audit.Divergences)
+	})
+
+	t.Run("counter missing from ledger", func(t *testing.T) {
+		audit := auditLedger(poll, newLedger(t, "poll1", answers[:2]...), map[string]int{"0": 2, "1": 1, "2": 1})
+
+		assert.Equal(t, []model.CounterDivergence{{Field: "1", Ledger: 0, Counter: 1}}, audit.Divergences)
+	})
+
+	t.Run("edited ballot", func(t *testing.T) {
+		entries := newLedger(t, "poll1", answers...)
+		entries[1].Ballot = `{"question":0,"option_indices":[1,2]}`
+
+		audit := auditLedger(poll, entries, map[string]int{"0": 2, "1": 1, "2": 1})
+
+		assert.False(t, audit.ChainValid)
+		assert.False(t, audit.Consistent)
+		require.NotNil(t, audit.BrokenAt)
+		assert.Equal(t, int64(2), *audit.BrokenAt)
+	})
+
+	t.Run("rehashed entry breaks next link", func(t *testing.T) {
+		entries := newLedger(t, "poll1", answers...)
+		entries[1].Ballot = `{"question":0,"option_indices":[1,2]}`
+		entries[1].Hash = entries[1].digest("poll1")
+
+		audit := auditLedger(poll, entries, map[string]int{"0": 1, "1": 2, "2": 1})
+
+		require.NotNil(t, audit.BrokenAt)
+		assert.Equal(t, int64(3), *audit.BrokenAt)
+	})
+
+	t.Run("deleted entry", func(t *testing.T) {
+		entries := newLedger(t, "poll1", answers...)
+		entries = append(entries[:1], entries[2:]...)
+
+		audit := auditLedger(poll, entries, map[string]int{"0": 1, "1": 1})
+
+		assert.False(t, audit.ChainValid)
+		require.NotNil(t, audit.BrokenAt)
+		assert.Equal(t, int64(2), *audit.BrokenAt)
+	})
+
+	t.Run("empty ledger", func(t *testing.T) {
+		audit := auditLedger(poll, nil, map[string]int{})
+
+		assert.True(t, audit.Consistent)
+		assert.Empty(t, audit.HeadHash)
+	})
+}
+
+func TestAnswerVotes(t *testing.T) {
+	rating := 9
+	tests := []struct {
+		name   string
+		poll   *model.Poll
+		answer model.Answer
+		want   map[string]int
+	}{
+		{
+			name:   "multiple choice",
+			poll:   &model.Poll{Options: []string{"A", "B", "C"}},
+			answer: model.Answer{OptionIndices: []int{0, 2}},
+			want:   map[string]int{"0": 1, "2": 1},
+		},
+		{
+			name:   "ranked first preference",
+			poll:   &model.Poll{Type: model.PollTypeRanked, Options: []string{"A", "B", "C"}},
+			answer: model.Answer{Ranking: []int{2, 0, 1}},
+			want:   map[string]int{"2": 1},
+		},
+		{
+			name:   "budget points",
+			poll:   &model.Poll{Type: model.PollTypeBudget, Options: []string{"A", "B"}},
+			answer: model.Answer{Points: map[int]int{0: 7, 1: 0}},
+			want:   map[string]int{"0": 7},
+		},
+		{
+			name:   "nps rating",
+			poll:   &model.Poll{Type: model.PollTypeNPS},
+			answer: model.Answer{Rating: &rating},
+			want:   map[string]int{"9": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := tt.poll.Question()
+			assert.Equal(t, tt.want, answerVotes(&question, &tt.answer))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// dueScore returns score of sorted sets ordered by due time
+func dueScore(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
+
+// enqueueDue adds items to sorted set queue as JSON members due at given time
+func enqueueDue[T any](ctx context.Context, s *RedisStorage, key string, items []T, at time.Time) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	members := make([]redis.Z, len(items))
+	for i := range items {
+		data, err := json.Marshal(&items[i])
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s item: %w", key, err)
+		}
+		members[i] = redis.Z{Score: dueScore(at), Member: data}
+	}
+
+	if err := s.client.ZAdd(ctx, key, members...).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue %s: %w", key, err)
+	}
+	return nil
+}
+
+// claimDueItems removes due JSON members from sorted set queue and returns them decoded,
+// every item is claimed by one caller only. Malformed member is dropped, so it doesn't take claimed batch with it.
+func claimDueItems[T any](ctx context.Context, s *RedisStorage, key string, now time.Time, limit int) ([]T, error) {
+	due, err := s.claimDue(ctx, key, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	for _, z := range due {
+		var item T
+		if err := json.Unmarshal([]byte(z.Member.(string)), &item); err != nil {
+			s.logger.ErrorContext(ctx, "malformed queue item dropped",
+				slog.String("queue", key),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// claimDue removes members of sorted set due by now and returns them with their scores,
+// every member is claimed by one caller only
+func (s *RedisStorage) claimDue(ctx context.Context, key string, now time.Time, limit int) ([]redis.Z, error) {
+	due, err := s.client.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatFloat(dueScore(now), 'f', -1, 64),
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due %s: %w", key, err)
+	}
+
+	members := make([]string, len(due))
+	for i, z := range due {
+		members[i], _ = z.Member.(string)
+	}
+	claimed, err := s.claim(ctx, key, members)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []redis.Z
+	for i, ok := range claimed {
+		if ok {
+			result = append(result, redis.Z{Score: due[i].Score, Member: members[i]})
+		}
+	}
+	return result, nil
+}
+
+// claim removes members from sorted set and reports which of them were removed by this call
+func (s *RedisStorage) claim(ctx context.Context, key string, members []string) ([]bool, error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	removed := make([]*redis.IntCmd, len(members))
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, member := range members {
+			removed[i] = pipe.ZRem(ctx, key, member)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim %s: %w", key, err)
+	}
+
+	claimed := make([]bool, len(members))
+	for i, cmd := range removed {
+		claimed[i] = cmd.Val() == 1
+	}
+	return claimed, nil
+}
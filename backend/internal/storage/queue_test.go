@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimDueItems(t *testing.T) {
+	store, _ := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.EnqueueEmails(ctx, []model.EmailDelivery{{ID: "e1"}, {ID: "e2"}}, now))
+	require.NoError(t, store.EnqueueEmails(ctx, []model.EmailDelivery{{ID: "later"}}, now.Add(time.Hour)))
+	require.NoError(t, store.client.ZAdd(ctx, emailQueueKey, redis.Z{Score: dueScore(now), Member: "{not json"}).Err())
+
+	emails, err := store.ClaimEmails(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, emails, 2, "malformed item doesn't take others with it")
+	assert.ElementsMatch(t, []string{"e1", "e2"}, []string{emails[0].ID, emails[1].ID})
+
+	again, err := store.ClaimEmails(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, again, "claimed items and malformed one are gone from queue")
+
+	left, err := store.client.ZCard(ctx, emailQueueKey).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), left, "item which isn't due stays")
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/AlexeyLars/surway-service/internal/model"
 	"github.com/redis/go-redis/v9"
+	"log/slog"
 	"time"
 )
 
@@ -14,30 +15,156 @@ var (
 	// ErrPollNotFound returns when poll not found
 	ErrPollNotFound = errors.New("poll not found")
 
+	// ErrSurveyNotFound returns when survey not found
+	ErrSurveyNotFound = errors.New("survey not found")
+
+	// ErrInvalidQuestion returns when answer refers to non existed question
+	ErrInvalidQuestion = errors.New("invalid question index")
+
+	// ErrDuplicateAnswer returns when question is answered more than once
+	ErrDuplicateAnswer = errors.New("question answered more than once")
+
+	// ErrMissingAnswer returns when required question is not answered
+	ErrMissingAnswer = errors.New("required question not answered")
+
+	// ErrUnreachableAnswer returns when question hidden by branching conditions is answered
+	ErrUnreachableAnswer = errors.New("question not reachable")
+
 	// ErrInvalidOption returns when trying vote for non existed option
 	ErrInvalidOption = errors.New("invalid option index")
 
 	// ErrDuplicateOption returns when options indices not unique
 	ErrDuplicateOption = errors.New("options indices not unique")
+
+	// ErrEmptyVote returns when ballot contains no options
+	ErrEmptyVote = errors.New("vote contains no options")
+
+	// ErrVoteTypeMismatch returns when ballot doesn't match poll type
+	ErrVoteTypeMismatch = errors.New("vote does not match poll type")
+
+	// ErrInvalidScore returns when score is out of poll scale
+	ErrInvalidScore = errors.New("score out of scale")
+
+	// ErrInvalidPoints returns when option is given negative points
+	ErrInvalidPoints = errors.New("points must not be negative")
+
+	// ErrBudgetExceeded returns when ballot spends more points than poll budget
+	ErrBudgetExceeded = errors.New("points budget exceeded")
+
+	// ErrTextTooLong returns when text answer exceeds question maximum length
+	ErrTextTooLong = errors.New("text answer too long")
+
+	// ErrInvalidColumn returns when matrix answer refers to non existed column
+	ErrInvalidColumn = errors.New("invalid column index")
+
+	// ErrInvalidNumber returns when number is out of range or doesn't match a step
+	ErrInvalidNumber = errors.New("number out of range")
+
+	// ErrNotOpen returns when poll or survey is answered before its opening time
+	ErrNotOpen = errors.New("voting is not open yet")
+
+	// ErrClosed returns when poll or survey is answered after its closing time
+	ErrClosed = errors.New("voting is closed")
+
+	// ErrInvalidInvite returns when invite-only poll is voted without invite token issued for it
+	ErrInvalidInvite = errors.New("invalid invite token")
+
+	// ErrInviteUsed returns when invite token has already voted
+	ErrInviteUsed = errors.New("invite token already used")
+
+	// ErrAlreadyVoted returns when voter token has already voted in poll
+	ErrAlreadyVoted = errors.New("voter has already voted")
+
+	// ErrReceiptNotFound returns when poll has no receipt with given ID
+	ErrReceiptNotFound = errors.New("receipt not found")
+
+	// ErrExpiryNotExtended returns when new poll expiry is not after the current one
+	ErrExpiryNotExtended = errors.New("expiry must be after current expiry")
+
+	// ErrWebhookNotFound returns when poll has no webhook with given ID
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrTooManyWebhooks returns when poll already has maximum number of webhooks
+	ErrTooManyWebhooks = errors.New("too many webhooks")
 )
 
+// maxTxRetries bounds retries of optimistic transaction while watched keys change concurrently,
+// concurrent votes of poll contend on its ledger head
+const maxTxRetries = 50
+
 // Storage defines interface for working with polls storage
 type Storage interface {
 	CreatePoll(ctx context.Context, poll *model.Poll, ttl time.Duration) error
 	GetPoll(ctx context.Context, pollID string) (*model.Poll, error)
-	Vote(ctx context.Context, pollID string, optionIndices []int) error
+	ExtendPoll(ctx context.Context, pollID string, expiresAt time.Time) (*model.Poll, error)
+	ClosePoll(ctx context.Context, pollID string, closesAt time.Time) (*model.Poll, error)
+	DeletePoll(ctx context.Context, pollID string) error
+	AddPasswordAttempt(ctx context.Context, pollID, client string, window time.Duration) (int, error)
+	ForgivePasswordAttempt(ctx context.Context, pollID, client string) error
+	AddInvites(ctx context.Context, pollID string, invites []string) (*model.InviteUsage, error)
+	GetInviteUsage(ctx context.Context, pollID string) (*model.InviteUsage, error)
+	Vote(ctx context.Context, pollID string, req *model.VoteRequest, voter string, receipt *model.VoteReceipt) error
+	GetReceipt(ctx context.Context, pollID, receiptID string) (*model.VoteReceipt, error)
+	AuditLedger(ctx context.Context, pollID string) (*model.LedgerAudit, error)
+	HasVoted(ctx context.Context, pollID, voter string) (bool, error)
 	GetResults(ctx context.Context, pollID string) (*model.PollResults, error)
+	GetBallots(ctx context.Context, pollID string) ([][]int, error)
+	GetScores(ctx context.Context, pollID string) (map[int]model.ScoreStats, error)
+	GetTextAnswers(ctx context.Context, pollID string, offset, limit int) ([]model.TextAnswer, int, error)
+	GetNumbers(ctx context.Context, pollID string) (map[int]int, error)
+	GetMatrix(ctx context.Context, pollID string) (map[int]map[int]int, error)
+	GetTerms(ctx context.Context, pollID string, limit int) (*model.WordCloudResults, error)
+	WatchPoll(ctx context.Context, pollID string) (<-chan struct{}, error)
+	WatchVotes(ctx context.Context) (<-chan string, error)
+
+	AddWebhook(ctx context.Context, poll *model.Poll, webhook *model.Webhook) error
+	ListWebhooks(ctx context.Context, pollID string) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, pollID, webhookID string) error
+	ScheduleVoteCast(ctx context.Context, pollID string, at time.Time) error
+	ClaimWebhookTriggers(ctx context.Context, now time.Time, limit int) ([]model.WebhookTrigger, error)
+	CountVoteBatch(ctx context.Context, pollID string) (int64, error)
+	EnqueueWebhookDeliveries(ctx context.Context, deliveries []model.WebhookDelivery, at time.Time) error
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, pollID string, attempt *model.WebhookAttempt, dead *model.WebhookDeadLetter) error
+	GetWebhookDeliveryLog(ctx context.Context, pollID string) (*model.WebhookDeliveryLog, error)
+
+	SetEmailNotification(ctx context.Context, poll *model.Poll, notification *model.EmailNotification) error
+	GetEmailNotification(ctx context.Context, pollID string) (*model.EmailNotification, error)
+	ClaimVoteThreshold(ctx context.Context, pollID string) (*model.EmailNotification, int64, error)
+	ReleaseVoteThreshold(ctx context.Context, pollID string) error
+	AddRecipientEmail(ctx context.Context, email string, window time.Duration) (int, error)
+	ClaimEmailTriggers(ctx context.Context, now time.Time, limit int) ([]string, error)
+	EnqueueEmails(ctx context.Context, deliveries []model.EmailDelivery, at time.Time) error
+	ClaimEmails(ctx context.Context, now time.Time, limit int) ([]model.EmailDelivery, error)
+
+	SetChatResponseURL(ctx context.Context, pollID, responseURL string, ttl time.Duration) error
+	ClaimChatUpdate(ctx context.Context, pollID string, interval time.Duration) (string, error)
+
+	CreateSurvey(ctx context.Context, survey *model.Survey, ttl time.Duration) error
+	GetSurvey(ctx context.Context, surveyID string) (*model.Survey, error)
+	SubmitSurvey(ctx context.Context, surveyID string, answers []model.Answer, voter string) error
+	HasSubmitted(ctx context.Context, surveyID, voter string) (bool, error)
+	GetSurveyResults(ctx context.Context, surveyID string) (*model.SurveyResults, error)
+	GetSurveyBallots(ctx context.Context, surveyID string, question int) ([][]int, error)
+	GetSurveyScores(ctx context.Context, surveyID string, question int) (map[int]model.ScoreStats, error)
+	GetSurveyTextAnswers(ctx context.Context, surveyID string, question, offset, limit int) ([]model.TextAnswer, int, error)
+	GetSurveyNumbers(ctx context.Context, surveyID string, question int) (map[int]int, error)
+	GetSurveyMatrix(ctx context.Context, surveyID string, question int) (map[int]map[int]int, error)
+	GetSurveyTerms(ctx context.Context, surveyID string, question, limit int) (*model.WordCloudResults, error)
+
 	Close() error
 }
 
 // RedisStorage realises Storage for Redis
 type RedisStorage struct {
 	client *redis.Client
+	logger *slog.Logger
 }
 
-func NewRedisStorage(client *redis.Client) *RedisStorage {
+func NewRedisStorage(client *redis.Client, logger *slog.Logger) *RedisStorage {
 	return &RedisStorage{
 		client: client,
+		logger: logger,
 	}
 }
 
@@ -50,6 +177,96 @@ func pollVotesKey(pollID string) string {
 	return fmt.Sprintf("poll:%s:votes", pollID)
 }
 
+func pollBallotsKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:ballots", pollID)
+}
+
+func pollScoresKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:scores", pollID)
+}
+
+func pollTextsKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:texts", pollID)
+}
+
+func pollNumbersKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:numbers", pollID)
+}
+
+func pollMatrixKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:matrix", pollID)
+}
+
+func pollTermsKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:terms", pollID)
+}
+
+func pollTermAnswersKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:terms:answers", pollID)
+}
+
+// pollVotersKey is set of voter token hashes
+func pollVotersKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:voters", pollID)
+}
+
+func pollQuizKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:quiz", pollID)
+}
+
+// pollUpdatesChannel is Pub/Sub channel notified on every vote
+func pollUpdatesChannel(pollID string) string {
+	return fmt.Sprintf("poll:%s:updates", pollID)
+}
+
+func pollAdminKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:admin", pollID)
+}
+
+func pollPasswordKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:password", pollID)
+}
+
+// pollPasswordFailuresKey counts password attempts of client but right ones, it expires with rate limit window
+func pollPasswordFailuresKey(pollID, client string) string {
+	return fmt.Sprintf("poll:%s:password:failures:%s", pollID, client)
+}
+
+// pollInvitesKey is set of unused invite token hashes
+func pollInvitesKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:invites", pollID)
+}
+
+// pollUsedInvitesKey is set of used invite token hashes
+func pollUsedInvitesKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:invites:used", pollID)
+}
+
+// pollReceiptsKey is hash of vote receipts by receipt ID
+func pollReceiptsKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:receipts", pollID)
+}
+
+func pollAnswerKeys(pollID string) answerKeys {
+	return answerKeys{
+		votes:   pollVotesKey(pollID),
+		ballots: pollBallotsKey(pollID),
+		scores:  pollScoresKey(pollID),
+		texts:   pollTextsKey(pollID),
+		numbers: pollNumbersKey(pollID),
+		matrix:  pollMatrixKey(pollID),
+		terms:   pollTermsKey(pollID),
+		answers: pollTermAnswersKey(pollID),
+		quiz:    pollQuizKey(pollID),
+	}
+}
+
+// pollKeys returns every key of poll, new keys must be listed here to follow poll expiry
+func pollKeys(pollID string) []string {
+	return append(pollAnswerKeys(pollID).all(), pollInfoKey(pollID), pollAdminKey(pollID), pollPasswordKey(pollID), pollVotersKey(pollID),
+		pollInvitesKey(pollID), pollUsedInvitesKey(pollID), pollReceiptsKey(pollID), pollLedgerKey(pollID), pollNotificationKey(pollID))
+}
+
 // CreatePoll saves new poll in Redis
 func (s *RedisStorage) CreatePoll(ctx context.Context, poll *model.Poll, ttl time.Duration) error {
 	pollData, err := json.Marshal(poll)
@@ -59,16 +276,24 @@ func (s *RedisStorage) CreatePoll(ctx context.Context, poll *model.Poll, ttl tim
 
 	pipe := s.client.Pipeline()
 
-	// Save poll info
+	// Save poll info, admin token and password hashes are kept apart
 	pipe.Set(ctx, pollInfoKey(poll.ID), pollData, ttl)
+	if poll.AdminTokenHash != "" {
+		pipe.Set(ctx, pollAdminKey(poll.ID), poll.AdminTokenHash, ttl)
+	}
+	if poll.PasswordHash != "" {
+		pipe.Set(ctx, pollPasswordKey(poll.ID), poll.PasswordHash, ttl)
+	}
 
-	// Initialize votes counters as zeros
-	votesData := make(map[string]interface{})
-	for i := range poll.Options {
-		votesData[fmt.Sprintf("%d", i)] = 0
+	// Initialize votes counters as zeros, text polls have none
+	if len(poll.Options) > 0 {
+		votesData := make(map[string]interface{})
+		for i := range poll.Options {
+			votesData[fmt.Sprintf("%d", i)] = 0
+		}
+		pipe.HSet(ctx, pollVotesKey(poll.ID), votesData)
+		pipe.Expire(ctx, pollVotesKey(poll.ID), ttl)
 	}
-	pipe.HSet(ctx, pollVotesKey(poll.ID), votesData)
-	pipe.Expire(ctx, pollVotesKey(poll.ID), ttl)
 
 	_, err = pipe.Exec(ctx)
 	if err != nil {
@@ -78,9 +303,17 @@ func (s *RedisStorage) CreatePoll(ctx context.Context, poll *model.Poll, ttl tim
 	return nil
 }
 
-// GetPoll gets poll info
+// GetPoll gets poll info with admin token and password hashes
 func (s *RedisStorage) GetPoll(ctx context.Context, pollID string) (*model.Poll, error) {
-	data, err := s.client.Get(ctx, pollInfoKey(pollID)).Result()
+	pipe := s.client.Pipeline()
+	infoCmd := pipe.Get(ctx, pollInfoKey(pollID))
+	adminCmd := pipe.Get(ctx, pollAdminKey(pollID))
+	passwordCmd := pipe.Get(ctx, pollPasswordKey(pollID))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	data, err := infoCmd.Result()
 	if err == redis.Nil {
 		return nil, ErrPollNotFound
 	}
@@ -92,11 +325,15 @@ func (s *RedisStorage) GetPoll(ctx context.Context, pollID string) (*model.Poll,
 	if err := json.Unmarshal([]byte(data), &poll); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal poll: %w", err)
 	}
+	// Polls created before admin tokens have none
+	poll.AdminTokenHash = adminCmd.Val()
+	poll.PasswordHash = passwordCmd.Val()
 
 	return &poll, nil
 }
 
-func (s *RedisStorage) Vote(ctx context.Context, pollID string, optionIndices []int) error {
+// Vote validates ballot against poll type and registers it with its receipt, voter is hash of voter token and may be empty
+func (s *RedisStorage) Vote(ctx context.Context, pollID string, req *model.VoteRequest, voter string, receipt *model.VoteReceipt) error {
 	// Check if poll exists
 	exists, err := s.client.Exists(ctx, pollInfoKey(pollID)).Result()
 	if err != nil {
@@ -106,76 +343,461 @@ func (s *RedisStorage) Vote(ctx context.Context, pollID string, optionIndices []
 		return ErrPollNotFound
 	}
 
-	// Get poll, validate indices and check for duplicates
-	poll, err := s.GetPoll(ctx, pollID)
+	receiptData, err := json.Marshal(receipt)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to marshal receipt: %w", err)
 	}
 
-	for _, idx := range optionIndices {
-		if idx < 0 || idx >= len(poll.Options) {
-			return ErrInvalidOption
+	// Atomic counters increase together with ledger entry, receipt, invite consumption and voter record,
+	// retried if poll expiry is extended, invites are used, voter votes or ledger is appended meanwhile
+	return s.watchTx(ctx, func(tx *redis.Tx) error {
+		poll, err := s.GetPoll(ctx, pollID)
+		if err != nil {
+			return err
+		}
+		if err := checkStatus(poll.Status(time.Now())); err != nil {
+			return err
+		}
+
+		question := poll.Question()
+		answer := req.Answer()
+		if err := validateAnswer(&question, &answer); err != nil {
+			return err
 		}
+		// Unused invite hasn't voted yet, reading voters too could report repeated invite as already voted
+		if poll.InviteOnly {
+			if err := checkInvite(ctx, tx, pollID, voter); err != nil {
+				return err
+			}
+		} else if voter != "" {
+			voted, err := tx.SIsMember(ctx, pollVotersKey(pollID), voter).Result()
+			if err != nil {
+				return fmt.Errorf("failed to check voter: %w", err)
+			}
+			if voted {
+				return ErrAlreadyVoted
+			}
+		}
+
+		head, err := ledgerHead(ctx, tx, pollID)
+		if err != nil {
+			return err
+		}
+		entry, err := newLedgerEntry(pollID, head, &answer, time.Now())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if err := queueAnswer(ctx, pipe, pollAnswerKeys(pollID), &question, &answer, poll.ExpiresAt); err != nil {
+				return err
+			}
+			pipe.XAdd(ctx, &redis.XAddArgs{Stream: pollLedgerKey(pollID), Values: entry.values()})
+			pipe.ExpireAt(ctx, pollLedgerKey(pollID), poll.ExpiresAt)
+			pipe.HSet(ctx, pollReceiptsKey(pollID), receipt.ID, receiptData)
+			pipe.ExpireAt(ctx, pollReceiptsKey(pollID), poll.ExpiresAt)
+			if poll.InviteOnly {
+				pipe.SMove(ctx, pollInvitesKey(pollID), pollUsedInvitesKey(pollID), voter)
+				pipe.ExpireAt(ctx, pollUsedInvitesKey(pollID), poll.ExpiresAt)
+			}
+			if voter != "" {
+				pipe.SAdd(ctx, pollVotersKey(pollID), voter)
+				pipe.ExpireAt(ctx, pollVotersKey(pollID), poll.ExpiresAt)
+			}
+			pipe.Publish(ctx, pollUpdatesChannel(pollID), "vote")
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register votes: %w", err)
+		}
+		return nil
+	}, pollInfoKey(pollID), pollInvitesKey(pollID), pollVotersKey(pollID), pollLedgerKey(pollID))
+}
+
+// GetReceipt returns receipt of vote counted in poll
+func (s *RedisStorage) GetReceipt(ctx context.Context, pollID, receiptID string) (*model.VoteReceipt, error) {
+	data, err := s.client.HGet(ctx, pollReceiptsKey(pollID), receiptID).Result()
+	if err == redis.Nil {
+		return nil, ErrReceiptNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	var receipt model.VoteReceipt
+	if err := json.Unmarshal([]byte(data), &receipt); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal receipt: %w", err)
+	}
+	return &receipt, nil
+}
+
+// checkInvite reports whether voter token hash is unused invite of poll
+func checkInvite(ctx context.Context, tx *redis.Tx, pollID, voter string) error {
+	if voter == "" {
+		return ErrInvalidInvite
+	}
+	pipe := tx.Pipeline()
+	unusedCmd := pipe.SIsMember(ctx, pollInvitesKey(pollID), voter)
+	usedCmd := pipe.SIsMember(ctx, pollUsedInvitesKey(pollID), voter)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to check invite: %w", err)
 	}
 
-	seen := make(map[int]bool)
-	for _, idx := range optionIndices {
-		if seen[idx] {
-			return ErrDuplicateOption
+	switch {
+	case unusedCmd.Val():
+		return nil
+	case usedCmd.Val():
+		return ErrInviteUsed
+	}
+	return ErrInvalidInvite
+}
+
+// AddInvites stores invite token hashes of poll, they expire with the poll
+func (s *RedisStorage) AddInvites(ctx context.Context, pollID string, invites []string) (*model.InviteUsage, error) {
+	var usage *model.InviteUsage
+
+	err := s.watchTx(ctx, func(tx *redis.Tx) error {
+		poll, err := s.GetPoll(ctx, pollID)
+		if err != nil {
+			return err
+		}
+
+		members := make([]interface{}, len(invites))
+		for i, invite := range invites {
+			members[i] = invite
 		}
-		seen[idx] = true
+
+		var unusedCmd, usedCmd *redis.IntCmd
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SAdd(ctx, pollInvitesKey(pollID), members...)
+			pipe.ExpireAt(ctx, pollInvitesKey(pollID), poll.ExpiresAt)
+			unusedCmd = pipe.SCard(ctx, pollInvitesKey(pollID))
+			usedCmd = pipe.SCard(ctx, pollUsedInvitesKey(pollID))
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add invites: %w", err)
+		}
+
+		usage = inviteUsage(int(unusedCmd.Val()), int(usedCmd.Val()))
+		return nil
+	}, pollInfoKey(pollID))
+	if err != nil {
+		return nil, err
 	}
 
-	// Atomic counters increase
+	return usage, nil
+}
+
+// GetInviteUsage returns numbers of issued, used and remaining invites of poll
+func (s *RedisStorage) GetInviteUsage(ctx context.Context, pollID string) (*model.InviteUsage, error) {
 	pipe := s.client.Pipeline()
-	for _, idx := range optionIndices {
-		field := fmt.Sprintf("%d", idx)
-		pipe.HIncrBy(ctx, pollVotesKey(pollID), field, 1)
+	unusedCmd := pipe.SCard(ctx, pollInvitesKey(pollID))
+	usedCmd := pipe.SCard(ctx, pollUsedInvitesKey(pollID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get invite usage: %w", err)
 	}
 
-	_, err = pipe.Exec(ctx)
+	return inviteUsage(int(unusedCmd.Val()), int(usedCmd.Val())), nil
+}
+
+// inviteUsage returns usage of invites from numbers of unused and used invites
+func inviteUsage(unused, used int) *model.InviteUsage {
+	return &model.InviteUsage{
+		Issued:    unused + used,
+		Used:      used,
+		Remaining: unused,
+	}
+}
+
+// ExtendPoll moves poll expiry to expiresAt and re-expires all poll keys in one transaction.
+// Expiry is never moved back, so concurrent extensions keep the latest one.
+func (s *RedisStorage) ExtendPoll(ctx context.Context, pollID string, expiresAt time.Time) (*model.Poll, error) {
+	var extended *model.Poll
+
+	err := s.watchTx(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, pollInfoKey(pollID)).Result()
+		if err == redis.Nil {
+			return ErrPollNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get poll: %w", err)
+		}
+
+		var poll model.Poll
+		if err := json.Unmarshal([]byte(data), &poll); err != nil {
+			return fmt.Errorf("failed to unmarshal poll: %w", err)
+		}
+		if !expiresAt.After(poll.ExpiresAt) {
+			return ErrExpiryNotExtended
+		}
+		poll.ExpiresAt = expiresAt
+
+		pollData, err := json.Marshal(&poll)
+		if err != nil {
+			return fmt.Errorf("failed to marshal poll: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, pollInfoKey(pollID), pollData, redis.KeepTTL)
+			// Keys that don't exist yet get expiry when they are created by votes
+			for _, key := range pollKeys(pollID) {
+				pipe.ExpireAt(ctx, key, expiresAt)
+			}
+			extendWebhooks(ctx, pipe, pollID, expiresAt)
+			if poll.ClosesAt == nil {
+				rescheduleEmailTrigger(ctx, pipe, pollID, emailClosingTime(&poll))
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to extend poll: %w", err)
+		}
+
+		extended = &poll
+		return nil
+	}, pollInfoKey(pollID))
+	if err != nil {
+		return nil, err
+	}
+
+	return extended, nil
+}
+
+// ClosePoll moves poll closing time to closesAt, results are kept until expiry.
+// Closing time is never moved later, so closed poll stays closed.
+func (s *RedisStorage) ClosePoll(ctx context.Context, pollID string, closesAt time.Time) (*model.Poll, error) {
+	var closed *model.Poll
+
+	err := s.watchTx(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, pollInfoKey(pollID)).Result()
+		if err == redis.Nil {
+			return ErrPollNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get poll: %w", err)
+		}
+
+		var poll model.Poll
+		if err := json.Unmarshal([]byte(data), &poll); err != nil {
+			return fmt.Errorf("failed to unmarshal poll: %w", err)
+		}
+		if poll.ClosesAt != nil && !poll.ClosesAt.After(closesAt) {
+			closed = &poll
+			return nil
+		}
+		poll.ClosesAt = &closesAt
+
+		pollData, err := json.Marshal(&poll)
+		if err != nil {
+			return fmt.Errorf("failed to marshal poll: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, pollInfoKey(pollID), pollData, redis.KeepTTL)
+			pipe.Publish(ctx, pollUpdatesChannel(pollID), "close")
+			rescheduleWebhookTrigger(ctx, pipe, pollID, model.WebhookPollClosed, closesAt)
+			rescheduleEmailTrigger(ctx, pipe, pollID, closesAt)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to close poll: %w", err)
+		}
+
+		closed = &poll
+		return nil
+	}, pollInfoKey(pollID))
+	if err != nil {
+		return nil, err
+	}
+
+	return closed, nil
+}
+
+// DeletePoll removes poll with all its votes, webhooks and email notification, live results streams of poll are ended
+func (s *RedisStorage) DeletePoll(ctx context.Context, pollID string) error {
+	var deleted *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		deleted = pipe.Del(ctx, pollInfoKey(pollID))
+		pipe.Del(ctx, pollKeys(pollID)...)
+		deleteWebhooks(ctx, pipe, pollID)
+		pipe.ZRem(ctx, emailScheduleKey, pollID)
+		pipe.Del(ctx, pollChatKey(pollID), pollChatThrottleKey(pollID))
+		pipe.Publish(ctx, pollUpdatesChannel(pollID), "delete")
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to register votes: %w", err)
+		return fmt.Errorf("failed to delete poll: %w", err)
+	}
+	if deleted.Val() == 0 {
+		return ErrPollNotFound
 	}
+	return nil
+}
+
+// watchTx runs fn as optimistic transaction watching keys, it is retried while keys change concurrently
+func (s *RedisStorage) watchTx(ctx context.Context, fn func(*redis.Tx) error, keys ...string) error {
+	for i := 0; i < maxTxRetries; i++ {
+		err := s.client.Watch(ctx, fn, keys...)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("transaction failed after %d retries: %w", maxTxRetries, redis.TxFailedErr)
+}
+
+// AddPasswordAttempt counts password attempt of client before password is checked and returns attempts
+// within rate limit window, window starts with the first attempt. Counting first keeps parallel guesses within the limit.
+func (s *RedisStorage) AddPasswordAttempt(ctx context.Context, pollID, client string, window time.Duration) (int, error) {
+	pipe := s.client.TxPipeline()
+	attempts := pipe.Incr(ctx, pollPasswordFailuresKey(pollID, client))
+	pipe.ExpireNX(ctx, pollPasswordFailuresKey(pollID, client), window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to add password attempt: %w", err)
+	}
+	return int(attempts.Val()), nil
+}
+
+// ForgivePasswordAttempt uncounts attempt of client who gave right password, nothing is done once window is over
+func (s *RedisStorage) ForgivePasswordAttempt(ctx context.Context, pollID, client string) error {
+	key := pollPasswordFailuresKey(pollID, client)
+	return s.watchTx(ctx, func(tx *redis.Tx) error {
+		attempts, err := tx.Get(ctx, key).Int()
+		if errors.Is(err, redis.Nil) || (err == nil && attempts <= 0) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get password attempts: %w", err)
+		}
 
+		// Counter keeps its expiry, so it can't outlive the window
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Decr(ctx, key)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to forgive password attempt: %w", err)
+		}
+		return nil
+	}, key)
+}
+
+// checkStatus allows answers to open polls and surveys only
+func checkStatus(status model.PollStatus) error {
+	switch status {
+	case model.PollStatusScheduled:
+		return ErrNotOpen
+	case model.PollStatusClosed:
+		return ErrClosed
+	}
 	return nil
 }
 
+// HasVoted reports whether voter with the token hash voted in poll
+func (s *RedisStorage) HasVoted(ctx context.Context, pollID, voter string) (bool, error) {
+	voted, err := s.client.SIsMember(ctx, pollVotersKey(pollID), voter).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check voter: %w", err)
+	}
+	return voted, nil
+}
+
 func (s *RedisStorage) GetResults(ctx context.Context, pollID string) (*model.PollResults, error) {
 	poll, err := s.GetPoll(ctx, pollID)
 	if err != nil {
 		return nil, err
 	}
 
-	votesMap, err := s.client.HGetAll(ctx, pollVotesKey(pollID)).Result()
-	if err != nil {
+	pipe := s.client.Pipeline()
+	votesCmd := pipe.HGetAll(ctx, pollVotesKey(pollID))
+	textsCmd := pipe.LLen(ctx, pollTextsKey(pollID))
+	if _, err := pipe.Exec(ctx); err != nil {
 		return nil, fmt.Errorf("failed to get votes: %w", err)
 	}
 
-	// Convert votes into map
-	votes := make(map[string]int)
-	total := 0
+	votes, total, err := countVotes(poll.Options, votesCmd.Val())
+	if err != nil {
+		return nil, err
+	}
 
-	for i, option := range poll.Options {
-		field := fmt.Sprintf("%d", i)
-		count := 0
-		if countStr, ok := votesMap[field]; ok {
-			_, err := fmt.Sscanf(countStr, "%d", &count)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert votes into map: %w", err)
-			}
-		}
-		votes[option] = count
-		total += count
+	texts := int(textsCmd.Val())
+	if poll.VotingType() == model.PollTypeText {
+		total = texts
 	}
 
 	return &model.PollResults{
-		Poll:  *poll,
-		Votes: votes,
-		Total: total,
+		Poll:        *poll,
+		Votes:       votes,
+		Total:       total,
+		TextAnswers: texts,
 	}, nil
 }
 
+// GetBallots returns stored ranked ballots in the order they were cast
+func (s *RedisStorage) GetBallots(ctx context.Context, pollID string) ([][]int, error) {
+	return s.readBallots(ctx, pollBallotsKey(pollID))
+}
+
+// GetScores returns sum and histogram of scores per option index
+func (s *RedisStorage) GetScores(ctx context.Context, pollID string) (map[int]model.ScoreStats, error) {
+	return s.readScores(ctx, pollScoresKey(pollID))
+}
+
+// GetTextAnswers returns page of text answers and their total number
+func (s *RedisStorage) GetTextAnswers(ctx context.Context, pollID string, offset, limit int) ([]model.TextAnswer, int, error) {
+	return s.readTextAnswers(ctx, pollTextsKey(pollID), offset, limit)
+}
+
+// GetNumbers returns counters of numbers by step index
+func (s *RedisStorage) GetNumbers(ctx context.Context, pollID string) (map[int]int, error) {
+	return s.readNumbers(ctx, pollNumbersKey(pollID))
+}
+
+// GetMatrix returns counters of picked columns by row index
+func (s *RedisStorage) GetMatrix(ctx context.Context, pollID string) (map[int]map[int]int, error) {
+	return s.readMatrix(ctx, pollMatrixKey(pollID))
+}
+
+// GetTerms returns most frequent word cloud terms, limit bounds number of terms
+func (s *RedisStorage) GetTerms(ctx context.Context, pollID string, limit int) (*model.WordCloudResults, error) {
+	return s.readTerms(ctx, pollTermsKey(pollID), pollTermAnswersKey(pollID), limit)
+}
+
+// WatchPoll notifies about votes for poll until ctx is done.
+// Notifications are coalesced, receiver gets one signal for votes cast while it was busy.
+func (s *RedisStorage) WatchPoll(ctx context.Context, pollID string) (<-chan struct{}, error) {
+	pubsub := s.client.Subscribe(ctx, pollUpdatesChannel(pollID))
+	// Wait for subscription confirmation so that no vote is missed after return
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to poll updates: %w", err)
+	}
+
+	updates := make(chan struct{}, 1)
+	go func() {
+		defer close(updates)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case updates <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
 func (s *RedisStorage) Close() error {
 	return s.client.Close()
 }
@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStorage returns storage backed by in-memory Redis server living for the test
+func newTestStorage(t *testing.T) (*RedisStorage, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewRedisStorage(client, slog.New(slog.NewTextHandler(io.Discard, nil))), server
+}
+
+// createTestPoll saves choice poll expiring in an hour
+func createTestPoll(t *testing.T, store *RedisStorage, inviteOnly bool) *model.Poll {
+	t.Helper()
+	poll := &model.Poll{
+		ID:         "poll1",
+		Title:      "Lunch?",
+		Options:    []string{"Pizza", "Sushi"},
+		InviteOnly: inviteOnly,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  time.Now().Add(time.Hour).Truncate(time.Second),
+	}
+	require.NoError(t, store.CreatePoll(context.Background(), poll, time.Hour))
+	return poll
+}
+
+func testVote(option int, id string) (*model.VoteRequest, *model.VoteReceipt) {
+	return &model.VoteRequest{OptionIndices: []int{option}}, &model.VoteReceipt{ID: id, PollID: "poll1"}
+}
+
+func TestRedisStorage_VoteInvite(t *testing.T) {
+	store, server := newTestStorage(t)
+	ctx := context.Background()
+	createTestPoll(t, store, true)
+
+	usage, err := store.AddInvites(ctx, "poll1", []string{"invite1", "invite2"})
+	require.NoError(t, err)
+	assert.Equal(t, &model.InviteUsage{Issued: 2, Used: 0, Remaining: 2}, usage)
+
+	req, receipt := testVote(0, "r1")
+	assert.ErrorIs(t, store.Vote(ctx, "poll1", req, "", receipt), ErrInvalidInvite)
+	assert.ErrorIs(t, store.Vote(ctx, "poll1", req, "unknown", receipt), ErrInvalidInvite)
+
+	require.NoError(t, store.Vote(ctx, "poll1", req, "invite1", receipt))
+	req, receipt = testVote(1, "r2")
+	assert.ErrorIs(t, store.Vote(ctx, "poll1", req, "invite1", receipt), ErrInviteUsed)
+
+	assert.False(t, isMember(t, server, pollInvitesKey("poll1"), "invite1"), "used invite leaves unused set")
+	assert.True(t, isMember(t, server, pollUsedInvitesKey("poll1"), "invite1"))
+	assert.Positive(t, server.TTL(pollUsedInvitesKey("poll1")), "used invites expire with poll")
+
+	usage, err = store.GetInviteUsage(ctx, "poll1")
+	require.NoError(t, err)
+	assert.Equal(t, &model.InviteUsage{Issued: 2, Used: 1, Remaining: 1}, usage)
+
+	results, err := store.GetResults(ctx, "poll1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Pizza": 1, "Sushi": 0}, results.Votes, "rejected votes aren't counted")
+	_, err = store.GetReceipt(ctx, "poll1", "r2")
+	assert.ErrorIs(t, err, ErrReceiptNotFound)
+}
+
+func TestRedisStorage_VoteInviteConcurrently(t *testing.T) {
+	store, server := newTestStorage(t)
+	ctx := context.Background()
+	createTestPoll(t, store, true)
+	_, err := store.AddInvites(ctx, "poll1", []string{"invite1"})
+	require.NoError(t, err)
+
+	const voters = 10
+	errs := make([]error, voters)
+	var wg sync.WaitGroup
+	for i := range voters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, receipt := testVote(i%2, fmt.Sprintf("r%d", i))
+			errs[i] = store.Vote(ctx, "poll1", req, "invite1", receipt)
+		}()
+	}
+	wg.Wait()
+
+	accepted := 0
+	for _, err := range errs {
+		if err == nil {
+			accepted++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrInviteUsed)
+	}
+	assert.Equal(t, 1, accepted, "invite is used once")
+
+	results, err := store.GetResults(ctx, "poll1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, results.Votes["Pizza"]+results.Votes["Sushi"])
+	receipts, err := store.client.HLen(ctx, pollReceiptsKey("poll1")).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), receipts)
+	ledger, err := store.client.XLen(ctx, pollLedgerKey("poll1")).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), ledger)
+	assert.True(t, isMember(t, server, pollUsedInvitesKey("poll1"), "invite1"))
+}
+
+func isMember(t *testing.T, server *miniredis.Miniredis, key, member string) bool {
+	t.Helper()
+	if !server.Exists(key) {
+		return false
+	}
+	ok, err := server.SIsMember(key, member)
+	require.NoError(t, err)
+	return ok
+}
+
+// beforeTx runs fn once before first transaction queuing command is sent, e.g. to change watched keys under it
+type beforeTx struct {
+	command string
+	once    sync.Once
+	fn      func()
+}
+
+func (h *beforeTx) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *beforeTx) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h *beforeTx) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if cmd.Name() == h.command {
+				h.once.Do(h.fn)
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestRedisStorage_ExtendPoll(t *testing.T) {
+	store, server := newTestStorage(t)
+	ctx := context.Background()
+	poll := &model.Poll{
+		ID:             "poll1",
+		Title:          "Lunch?",
+		Options:        []string{"Pizza", "Sushi"},
+		AdminTokenHash: "admin",
+		PasswordHash:   "password",
+		CreatedAt:      time.Now(),
+		ExpiresAt:      time.Now().Add(time.Hour).Truncate(time.Second),
+	}
+	require.NoError(t, store.CreatePoll(ctx, poll, time.Hour))
+	require.NoError(t, store.AddWebhook(ctx, poll, &model.Webhook{ID: "hook1", URL: "https://example.com/hook"}))
+	require.NoError(t, store.SetEmailNotification(ctx, poll, &model.EmailNotification{Email: "me@example.com"}))
+	_, err := store.AddInvites(ctx, "poll1", []string{"invite1"})
+	require.NoError(t, err)
+	req, receipt := testVote(0, "r1")
+	require.NoError(t, store.Vote(ctx, "poll1", req, "voter1", receipt))
+
+	expiresAt := poll.ExpiresAt.Add(48 * time.Hour)
+	extended, err := store.ExtendPoll(ctx, "poll1", expiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, expiresAt, extended.ExpiresAt)
+
+	ttl := time.Until(expiresAt)
+	for _, key := range pollKeys("poll1") {
+		if server.Exists(key) {
+			assert.InDelta(t, ttl, server.TTL(key), float64(5*time.Second), key)
+		}
+	}
+	for _, key := range webhookKeys("poll1") {
+		if server.Exists(key) {
+			assert.InDelta(t, ttl+webhookRetention, server.TTL(key), float64(5*time.Second), key)
+		}
+	}
+	expired, err := server.ZScore(webhookScheduleKey, triggerMember("poll1", model.WebhookPollExpired))
+	require.NoError(t, err)
+	assert.Equal(t, dueScore(expiresAt), expired)
+	closing, err := server.ZScore(emailScheduleKey, "poll1")
+	require.NoError(t, err)
+	assert.Equal(t, dueScore(expiresAt.Add(-emailFinalResultsLead)), closing, "final results follow new expiry")
+
+	_, err = store.ExtendPoll(ctx, "poll1", poll.ExpiresAt)
+	assert.ErrorIs(t, err, ErrExpiryNotExtended)
+}
+
+func TestRedisStorage_ExtendPollDuringVote(t *testing.T) {
+	store, server := newTestStorage(t)
+	ctx := context.Background()
+	poll := createTestPoll(t, store, false)
+	expiresAt := poll.ExpiresAt.Add(48 * time.Hour)
+
+	// Poll is extended by another client after vote has read poll and before its transaction runs
+	other := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer other.Close()
+	hook := &beforeTx{command: "xadd", fn: func() {
+		_, err := NewRedisStorage(other, store.logger).ExtendPoll(ctx, "poll1", expiresAt)
+		require.NoError(t, err)
+	}}
+	store.client.AddHook(hook)
+
+	req, receipt := testVote(1, "r1")
+	require.NoError(t, store.Vote(ctx, "poll1", req, "voter1", receipt))
+
+	ttl := time.Until(expiresAt)
+	for _, key := range []string{pollVotesKey("poll1"), pollLedgerKey("poll1"), pollReceiptsKey("poll1"), pollVotersKey("poll1")} {
+		assert.InDelta(t, ttl, server.TTL(key), float64(5*time.Second), "vote is retried with new expiry: %s", key)
+	}
+	results, err := store.GetResults(ctx, "poll1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, results.Votes["Sushi"], "vote is counted once")
+}
@@ -0,0 +1,314 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// AnswerError tells which question of survey has invalid answer
+type AnswerError struct {
+	Question int
+	Err      error
+}
+
+func (e *AnswerError) Error() string {
+	return fmt.Sprintf("question %d: %s", e.Question, e.Err)
+}
+
+func (e *AnswerError) Unwrap() error {
+	return e.Err
+}
+
+// Keys for Redis
+func surveyInfoKey(surveyID string) string {
+	return fmt.Sprintf("survey:%s:info", surveyID)
+}
+
+func surveyResponsesKey(surveyID string) string {
+	return fmt.Sprintf("survey:%s:responses", surveyID)
+}
+
+// surveyVotersKey is set of voter token hashes
+func surveyVotersKey(surveyID string) string {
+	return fmt.Sprintf("survey:%s:voters", surveyID)
+}
+
+// surveyScoresKey is hash: quiz score -> number of submissions
+func surveyScoresKey(surveyID string) string {
+	return fmt.Sprintf("survey:%s:scores", surveyID)
+}
+
+func surveyAdminKey(surveyID string) string {
+	return fmt.Sprintf("survey:%s:admin", surveyID)
+}
+
+func surveyAnswerKeys(surveyID string, question int) answerKeys {
+	return answerKeys{
+		votes:   fmt.Sprintf("survey:%s:q:%d:votes", surveyID, question),
+		ballots: fmt.Sprintf("survey:%s:q:%d:ballots", surveyID, question),
+		scores:  fmt.Sprintf("survey:%s:q:%d:scores", surveyID, question),
+		texts:   fmt.Sprintf("survey:%s:q:%d:texts", surveyID, question),
+		numbers: fmt.Sprintf("survey:%s:q:%d:numbers", surveyID, question),
+		matrix:  fmt.Sprintf("survey:%s:q:%d:matrix", surveyID, question),
+		terms:   fmt.Sprintf("survey:%s:q:%d:terms", surveyID, question),
+		answers: fmt.Sprintf("survey:%s:q:%d:terms:answers", surveyID, question),
+		quiz:    fmt.Sprintf("survey:%s:q:%d:quiz", surveyID, question),
+	}
+}
+
+// CreateSurvey saves new survey in Redis
+func (s *RedisStorage) CreateSurvey(ctx context.Context, survey *model.Survey, ttl time.Duration) error {
+	surveyData, err := json.Marshal(survey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal survey: %w", err)
+	}
+
+	pipe := s.client.TxPipeline()
+
+	pipe.Set(ctx, surveyInfoKey(survey.ID), surveyData, ttl)
+	pipe.Set(ctx, surveyResponsesKey(survey.ID), 0, ttl)
+	if survey.AdminTokenHash != "" {
+		pipe.Set(ctx, surveyAdminKey(survey.ID), survey.AdminTokenHash, ttl)
+	}
+
+	// Initialize votes counters of every question as zeros, text questions have none
+	for n, question := range survey.Questions {
+		if len(question.Options) == 0 {
+			continue
+		}
+
+		votesData := make(map[string]interface{})
+		for i := range question.Options {
+			votesData[fmt.Sprintf("%d", i)] = 0
+		}
+
+		keys := surveyAnswerKeys(survey.ID, n)
+		pipe.HSet(ctx, keys.votes, votesData)
+		pipe.Expire(ctx, keys.votes, ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to create survey: %w", err)
+	}
+
+	return nil
+}
+
+// GetSurvey gets survey definition with admin token hash
+func (s *RedisStorage) GetSurvey(ctx context.Context, surveyID string) (*model.Survey, error) {
+	pipe := s.client.Pipeline()
+	infoCmd := pipe.Get(ctx, surveyInfoKey(surveyID))
+	adminCmd := pipe.Get(ctx, surveyAdminKey(surveyID))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get survey: %w", err)
+	}
+
+	data, err := infoCmd.Result()
+	if err == redis.Nil {
+		return nil, ErrSurveyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get survey: %w", err)
+	}
+
+	var survey model.Survey
+	if err := json.Unmarshal([]byte(data), &survey); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal survey: %w", err)
+	}
+	survey.AdminTokenHash = adminCmd.Val()
+
+	return &survey, nil
+}
+
+// SubmitSurvey validates answers and registers them all in one transaction, voter is hash of voter token and may be empty
+func (s *RedisStorage) SubmitSurvey(ctx context.Context, surveyID string, answers []model.Answer, voter string) error {
+	survey, err := s.GetSurvey(ctx, surveyID)
+	if err != nil {
+		return err
+	}
+	if err := checkStatus(survey.Status(time.Now())); err != nil {
+		return err
+	}
+
+	answered := make(map[int]bool)
+	for i := range answers {
+		answer := &answers[i]
+		if answer.Question < 0 || answer.Question >= len(survey.Questions) {
+			return &AnswerError{Question: answer.Question, Err: ErrInvalidQuestion}
+		}
+		if answered[answer.Question] {
+			return &AnswerError{Question: answer.Question, Err: ErrDuplicateAnswer}
+		}
+		answered[answer.Question] = true
+
+		if err := validateAnswer(&survey.Questions[answer.Question], answer); err != nil {
+			return &AnswerError{Question: answer.Question, Err: err}
+		}
+	}
+
+	// Voter is checked and recorded in one transaction, retried if voter submits meanwhile
+	return s.watchTx(ctx, func(tx *redis.Tx) error {
+		if voter != "" {
+			voted, err := tx.SIsMember(ctx, surveyVotersKey(surveyID), voter).Result()
+			if err != nil {
+				return fmt.Errorf("failed to check voter: %w", err)
+			}
+			if voted {
+				return ErrAlreadyVoted
+			}
+		}
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i := range answers {
+				answer := &answers[i]
+				keys := surveyAnswerKeys(surveyID, answer.Question)
+				if err := queueAnswer(ctx, pipe, keys, &survey.Questions[answer.Question], answer, survey.ExpiresAt); err != nil {
+					return err
+				}
+			}
+			pipe.Incr(ctx, surveyResponsesKey(surveyID))
+			if voter != "" {
+				pipe.SAdd(ctx, surveyVotersKey(surveyID), voter)
+				pipe.ExpireAt(ctx, surveyVotersKey(surveyID), survey.ExpiresAt)
+			}
+			if score := survey.Grade(answers); score != nil {
+				pipe.HIncrBy(ctx, surveyScoresKey(surveyID), strconv.Itoa(score.Score), 1)
+				pipe.ExpireAt(ctx, surveyScoresKey(surveyID), survey.ExpiresAt)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register answers: %w", err)
+		}
+		return nil
+	}, surveyVotersKey(surveyID))
+}
+
+// HasSubmitted reports whether voter with the token hash submitted survey
+func (s *RedisStorage) HasSubmitted(ctx context.Context, surveyID, voter string) (bool, error) {
+	submitted, err := s.client.SIsMember(ctx, surveyVotersKey(surveyID), voter).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check voter: %w", err)
+	}
+	return submitted, nil
+}
+
+// GetSurveyResults returns survey with per-question vote counters
+func (s *RedisStorage) GetSurveyResults(ctx context.Context, surveyID string) (*model.SurveyResults, error) {
+	survey, err := s.GetSurvey(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := s.client.Pipeline()
+	responsesCmd := pipe.Get(ctx, surveyResponsesKey(surveyID))
+	scoresCmd := pipe.HGetAll(ctx, surveyScoresKey(surveyID))
+	votesCmds := make([]*redis.MapStringStringCmd, len(survey.Questions))
+	textsCmds := make([]*redis.IntCmd, len(survey.Questions))
+	quizCmds := make([]*redis.MapStringStringCmd, len(survey.Questions))
+	for n := range survey.Questions {
+		keys := surveyAnswerKeys(surveyID, n)
+		votesCmds[n] = pipe.HGetAll(ctx, keys.votes)
+		textsCmds[n] = pipe.LLen(ctx, keys.texts)
+		if survey.Questions[n].Graded() {
+			quizCmds[n] = pipe.HGetAll(ctx, keys.quiz)
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get survey votes: %w", err)
+	}
+
+	responses, err := responsesCmd.Int()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get survey responses: %w", err)
+	}
+
+	results := &model.SurveyResults{
+		Survey:    *survey,
+		Responses: responses,
+		Questions: make([]model.QuestionResults, len(survey.Questions)),
+	}
+	for n, question := range survey.Questions {
+		votes, total, err := countVotes(question.Options, votesCmds[n].Val())
+		if err != nil {
+			return nil, err
+		}
+		texts := int(textsCmds[n].Val())
+		if question.VotingType() == model.PollTypeText {
+			total = texts
+		}
+		results.Questions[n] = model.QuestionResults{
+			Question:    n,
+			Votes:       votes,
+			Total:       total,
+			TextAnswers: texts,
+		}
+
+		if quizCmds[n] != nil {
+			counters, err := parseCounters(quizCmds[n].Val())
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse quiz counters: %w", err)
+			}
+			results.Questions[n].Quiz = &model.QuestionQuiz{
+				Answered: counters["answered"],
+				Correct:  counters["correct"],
+			}
+		}
+	}
+
+	if survey.Graded() {
+		counters, err := parseCounters(scoresCmd.Val())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse quiz scores: %w", err)
+		}
+		distribution := make(map[int]int, len(counters))
+		for field, count := range counters {
+			score, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse quiz score %q: %w", field, err)
+			}
+			distribution[score] = count
+		}
+		results.Quiz = &model.QuizResults{Distribution: distribution}
+	}
+
+	return results, nil
+}
+
+// GetSurveyBallots returns ranked ballots of survey question
+func (s *RedisStorage) GetSurveyBallots(ctx context.Context, surveyID string, question int) ([][]int, error) {
+	return s.readBallots(ctx, surveyAnswerKeys(surveyID, question).ballots)
+}
+
+// GetSurveyScores returns sum and histogram of scores of survey question
+func (s *RedisStorage) GetSurveyScores(ctx context.Context, surveyID string, question int) (map[int]model.ScoreStats, error) {
+	return s.readScores(ctx, surveyAnswerKeys(surveyID, question).scores)
+}
+
+// GetSurveyTextAnswers returns page of text answers of survey question and their total number
+func (s *RedisStorage) GetSurveyTextAnswers(ctx context.Context, surveyID string, question, offset, limit int) ([]model.TextAnswer, int, error) {
+	return s.readTextAnswers(ctx, surveyAnswerKeys(surveyID, question).texts, offset, limit)
+}
+
+// GetSurveyNumbers returns counters of numbers by step index of survey question
+func (s *RedisStorage) GetSurveyNumbers(ctx context.Context, surveyID string, question int) (map[int]int, error) {
+	return s.readNumbers(ctx, surveyAnswerKeys(surveyID, question).numbers)
+}
+
+// GetSurveyMatrix returns counters of picked columns by row index of survey question
+func (s *RedisStorage) GetSurveyMatrix(ctx context.Context, surveyID string, question int) (map[int]map[int]int, error) {
+	return s.readMatrix(ctx, surveyAnswerKeys(surveyID, question).matrix)
+}
+
+// GetSurveyTerms returns most frequent word cloud terms of survey question
+func (s *RedisStorage) GetSurveyTerms(ctx context.Context, surveyID string, question, limit int) (*model.WordCloudResults, error) {
+	keys := surveyAnswerKeys(surveyID, question)
+	return s.readTerms(ctx, keys.terms, keys.answers, limit)
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisStorage_SubmitSurveyOnce(t *testing.T) {
+	store, _ := newTestStorage(t)
+	ctx := context.Background()
+	survey := &model.Survey{
+		ID:    "survey1",
+		Title: "Onboarding",
+		Questions: []model.Question{
+			{Title: "Did you get a laptop?", Options: []string{"Yes", "No"}},
+			{Title: "Which perks do you use?", Options: []string{"Gym", "Lunch"}},
+		},
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, store.CreateSurvey(ctx, survey, time.Hour))
+	answers := func() []model.Answer {
+		return []model.Answer{{Question: 0, OptionIndices: []int{0}}, {Question: 1, OptionIndices: []int{1}}}
+	}
+
+	const submissions = 10
+	errs := make([]error, submissions)
+	var wg sync.WaitGroup
+	for i := range submissions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = store.SubmitSurvey(ctx, "survey1", answers(), "voter1")
+		}()
+	}
+	wg.Wait()
+
+	accepted := 0
+	for _, err := range errs {
+		if err == nil {
+			accepted++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrAlreadyVoted)
+	}
+	assert.Equal(t, 1, accepted, "voter submits survey once")
+	responses, err := store.client.Get(ctx, surveyResponsesKey("survey1")).Int()
+	require.NoError(t, err)
+	assert.Equal(t, 1, responses)
+
+	for i := range 2 {
+		require.NoError(t, store.SubmitSurvey(ctx, "survey1", answers(), fmt.Sprintf("other%d", i)))
+	}
+	require.NoError(t, store.SubmitSurvey(ctx, "survey1", answers(), ""), "anonymous submission isn't tracked")
+}
@@ -0,0 +1,368 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// webhookRetention keeps webhooks after poll expiry, so poll.expired event and retries are delivered
+const webhookRetention = 24 * time.Hour
+
+// webhookLogLimit bounds delivery log and dead letters kept per poll.
+// Deliveries are removed from queue when claimed, so delivery is at most once: one whose worker crashes
+// while sending is lost and leaves neither log entry nor dead letter. Emails are queued the same way.
+const webhookLogLimit = 100
+
+// webhookQueueKey is sorted set of webhook deliveries scored by due time
+const webhookQueueKey = "webhooks:queue"
+
+// webhookScheduleKey is sorted set of poll events scored by due time, members are "<event>|<poll id>"
+const webhookScheduleKey = "webhooks:schedule"
+
+// pollWebhooksKey is hash of poll webhooks by webhook ID
+func pollWebhooksKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:webhooks", pollID)
+}
+
+// pollWebhookAttemptsKey is list of delivery attempts, newest first
+func pollWebhookAttemptsKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:webhooks:attempts", pollID)
+}
+
+// pollWebhookDeadKey is list of deliveries given up, newest first
+func pollWebhookDeadKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:webhooks:dead", pollID)
+}
+
+// pollWebhookCursorKey is ledger sequence number of the last vote reported by vote.cast
+func pollWebhookCursorKey(pollID string) string {
+	return fmt.Sprintf("poll:%s:webhooks:cursor", pollID)
+}
+
+// webhookKeys returns keys of poll webhooks, unlike pollKeys they outlive poll by webhookRetention
+func webhookKeys(pollID string) []string {
+	return []string{pollWebhooksKey(pollID), pollWebhookAttemptsKey(pollID), pollWebhookDeadKey(pollID), pollWebhookCursorKey(pollID)}
+}
+
+// triggerMember returns member of schedule set
+func triggerMember(pollID string, event model.WebhookEventType) string {
+	return string(event) + "|" + pollID
+}
+
+// parseTriggerMember reads member of schedule set
+func parseTriggerMember(member string, score float64) (model.WebhookTrigger, bool) {
+	event, pollID, ok := strings.Cut(member, "|")
+	if !ok {
+		return model.WebhookTrigger{}, false
+	}
+	return model.WebhookTrigger{PollID: pollID, Type: model.WebhookEventType(event), DueAt: time.UnixMilli(int64(score))}, true
+}
+
+// extendWebhooks moves expiry of poll webhooks and its poll.expired event
+func extendWebhooks(ctx context.Context, pipe redis.Pipeliner, pollID string, expiresAt time.Time) {
+	for _, key := range webhookKeys(pollID) {
+		pipe.ExpireAt(ctx, key, expiresAt.Add(webhookRetention))
+	}
+	rescheduleWebhookTrigger(ctx, pipe, pollID, model.WebhookPollExpired, expiresAt)
+}
+
+// rescheduleWebhookTrigger moves event that is still pending, polls without webhooks have none
+func rescheduleWebhookTrigger(ctx context.Context, pipe redis.Pipeliner, pollID string, event model.WebhookEventType, at time.Time) {
+	pipe.ZAddXX(ctx, webhookScheduleKey, redis.Z{Score: dueScore(at), Member: triggerMember(pollID, event)})
+}
+
+// deleteWebhooks removes poll webhooks with pending events, queued deliveries are dropped when due
+func deleteWebhooks(ctx context.Context, pipe redis.Pipeliner, pollID string) {
+	pipe.Del(ctx, webhookKeys(pollID)...)
+	members := make([]interface{}, len(model.WebhookEventTypes))
+	for i, event := range model.WebhookEventTypes {
+		members[i] = triggerMember(pollID, event)
+	}
+	pipe.ZRem(ctx, webhookScheduleKey, members...)
+}
+
+// AddWebhook registers webhook of poll and schedules poll.closed and poll.expired events
+func (s *RedisStorage) AddWebhook(ctx context.Context, poll *model.Poll, webhook *model.Webhook) error {
+	data, err := json.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+
+	key := pollWebhooksKey(poll.ID)
+	return s.watchTx(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, pollInfoKey(poll.ID)).Result()
+		if err != nil {
+			return fmt.Errorf("failed to check poll: %w", err)
+		}
+		if exists == 0 {
+			return ErrPollNotFound
+		}
+
+		count, err := tx.HLen(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to count webhooks: %w", err)
+		}
+		if count >= model.MaxWebhooks {
+			return ErrTooManyWebhooks
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, webhook.ID, data)
+			pipe.ExpireAt(ctx, key, poll.ExpiresAt.Add(webhookRetention))
+			pipe.ZAdd(ctx, webhookScheduleKey, redis.Z{Score: dueScore(poll.ExpiresAt), Member: triggerMember(poll.ID, model.WebhookPollExpired)})
+
+			// Closed poll has already sent poll.closed or had no webhooks then
+			if !poll.Closed(time.Now()) {
+				closesAt := math.Inf(1)
+				if poll.ClosesAt != nil {
+					closesAt = dueScore(*poll.ClosesAt)
+				}
+				pipe.ZAddNX(ctx, webhookScheduleKey, redis.Z{Score: closesAt, Member: triggerMember(poll.ID, model.WebhookPollClosed)})
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add webhook: %w", err)
+		}
+		return nil
+	}, pollInfoKey(poll.ID), key)
+}
+
+// ListWebhooks returns webhooks of poll in registration order
+func (s *RedisStorage) ListWebhooks(ctx context.Context, pollID string) ([]model.Webhook, error) {
+	values, err := s.client.HVals(ctx, pollWebhooksKey(pollID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	webhooks := make([]model.Webhook, 0, len(values))
+	for _, value := range values {
+		var webhook model.Webhook
+		if err := json.Unmarshal([]byte(value), &webhook); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+// DeleteWebhook removes webhook of poll, its queued deliveries are dropped when due
+func (s *RedisStorage) DeleteWebhook(ctx context.Context, pollID, webhookID string) error {
+	deleted, err := s.client.HDel(ctx, pollWebhooksKey(pollID), webhookID).Result()
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if deleted == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ScheduleVoteCast schedules vote.cast event of poll unless one is already pending
+func (s *RedisStorage) ScheduleVoteCast(ctx context.Context, pollID string, at time.Time) error {
+	err := s.client.ZAddNX(ctx, webhookScheduleKey, redis.Z{Score: dueScore(at), Member: triggerMember(pollID, model.WebhookVoteCast)}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to schedule vote.cast: %w", err)
+	}
+	return nil
+}
+
+// ClaimWebhookTriggers removes due poll events from schedule and returns them,
+// every event is claimed by one caller only
+func (s *RedisStorage) ClaimWebhookTriggers(ctx context.Context, now time.Time, limit int) ([]model.WebhookTrigger, error) {
+	due, err := s.claimDue(ctx, webhookScheduleKey, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var triggers []model.WebhookTrigger
+	for _, z := range due {
+		if trigger, valid := parseTriggerMember(z.Member.(string), z.Score); valid {
+			triggers = append(triggers, trigger)
+		}
+	}
+	return triggers, nil
+}
+
+// CountVoteBatch returns number of votes since previous call and moves cursor past them
+func (s *RedisStorage) CountVoteBatch(ctx context.Context, pollID string) (int64, error) {
+	var votes int64
+	cursorKey := pollWebhookCursorKey(pollID)
+
+	err := s.watchTx(ctx, func(tx *redis.Tx) error {
+		head, err := ledgerHead(ctx, tx, pollID)
+		if err != nil {
+			return err
+		}
+		if head == nil {
+			votes = 0
+			return nil
+		}
+
+		cursor, err := tx.Get(ctx, cursorKey).Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("failed to get vote cursor: %w", err)
+		}
+		if head.Seq <= cursor {
+			votes = 0
+			return nil
+		}
+
+		ttl, err := tx.PTTL(ctx, pollWebhooksKey(pollID)).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get webhooks expiry: %w", err)
+		}
+		if ttl <= 0 {
+			ttl = webhookRetention
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, cursorKey, head.Seq, ttl)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to move vote cursor: %w", err)
+		}
+
+		votes = head.Seq - cursor
+		return nil
+	}, cursorKey)
+	if err != nil {
+		return 0, err
+	}
+	return votes, nil
+}
+
+// EnqueueWebhookDeliveries queues deliveries due at given time
+func (s *RedisStorage) EnqueueWebhookDeliveries(ctx context.Context, deliveries []model.WebhookDelivery, at time.Time) error {
+	return enqueueDue(ctx, s, webhookQueueKey, deliveries, at)
+}
+
+// ClaimWebhookDeliveries removes due deliveries from queue and returns them,
+// every delivery is claimed by one caller only
+func (s *RedisStorage) ClaimWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	return claimDueItems[model.WebhookDelivery](ctx, s, webhookQueueKey, now, limit)
+}
+
+// RecordWebhookAttempt appends attempt to delivery log and dead letter if delivery is given up.
+// Nothing is recorded once poll webhooks are gone.
+func (s *RedisStorage) RecordWebhookAttempt(ctx context.Context, pollID string, attempt *model.WebhookAttempt, dead *model.WebhookDeadLetter) error {
+	ttl, err := s.client.PTTL(ctx, pollWebhooksKey(pollID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get webhooks expiry: %w", err)
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	attemptData, err := json.Marshal(attempt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook attempt: %w", err)
+	}
+	var deadData []byte
+	if dead != nil {
+		deadData, err = json.Marshal(dead)
+		if err != nil {
+			return fmt.Errorf("failed to marshal dead letter: %w", err)
+		}
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pushCapped(ctx, pipe, pollWebhookAttemptsKey(pollID), attemptData, ttl)
+		if deadData != nil {
+			pushCapped(ctx, pipe, pollWebhookDeadKey(pollID), deadData, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// pushCapped prepends value to list keeping webhookLogLimit newest values
+func pushCapped(ctx context.Context, pipe redis.Pipeliner, key string, value []byte, ttl time.Duration) {
+	pipe.LPush(ctx, key, value)
+	pipe.LTrim(ctx, key, 0, webhookLogLimit-1)
+	pipe.PExpire(ctx, key, ttl)
+}
+
+// GetWebhookDeliveryLog returns recent delivery attempts and dead letters of poll
+func (s *RedisStorage) GetWebhookDeliveryLog(ctx context.Context, pollID string) (*model.WebhookDeliveryLog, error) {
+	var attempts, dead *redis.StringSliceCmd
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		attempts = pipe.LRange(ctx, pollWebhookAttemptsKey(pollID), 0, -1)
+		dead = pipe.LRange(ctx, pollWebhookDeadKey(pollID), 0, -1)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery log: %w", err)
+	}
+
+	log := &model.WebhookDeliveryLog{
+		Attempts:    make([]model.WebhookAttempt, len(attempts.Val())),
+		DeadLetters: make([]model.WebhookDeadLetter, len(dead.Val())),
+	}
+	for i, value := range attempts.Val() {
+		if err := json.Unmarshal([]byte(value), &log.Attempts[i]); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook attempt: %w", err)
+		}
+	}
+	for i, value := range dead.Val() {
+		if err := json.Unmarshal([]byte(value), &log.DeadLetters[i]); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal dead letter: %w", err)
+		}
+	}
+	return log, nil
+}
+
+// WatchVotes returns IDs of polls as they are voted in until ctx is done
+func (s *RedisStorage) WatchVotes(ctx context.Context) (<-chan string, error) {
+	pubsub := s.client.PSubscribe(ctx, pollUpdatesChannel("*"))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to poll updates: %w", err)
+	}
+
+	prefix, suffix, _ := strings.Cut(pollUpdatesChannel("*"), "*")
+	votes := make(chan string, 64)
+	go func() {
+		defer close(votes)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				if message.Payload != "vote" {
+					continue
+				}
+				pollID := strings.TrimSuffix(strings.TrimPrefix(message.Channel, prefix), suffix)
+				select {
+				case votes <- pollID:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return votes, nil
+}
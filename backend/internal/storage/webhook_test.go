@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerMember(t *testing.T) {
+	due := time.UnixMilli(1700000000123)
+
+	trigger, ok := parseTriggerMember(triggerMember("abc1234", model.WebhookVoteCast), dueScore(due))
+
+	assert.True(t, ok)
+	assert.Equal(t, model.WebhookTrigger{PollID: "abc1234", Type: model.WebhookVoteCast, DueAt: due}, trigger)
+
+	_, ok = parseTriggerMember("garbage", 0)
+	assert.False(t, ok)
+}
+
+func TestWebhookKeys(t *testing.T) {
+	// Webhook keys outlive poll, so they must not follow poll expiry
+	keys := pollKeys("abc1234")
+	for _, key := range webhookKeys("abc1234") {
+		assert.NotContains(t, keys, key)
+	}
+}
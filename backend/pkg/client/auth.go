@@ -0,0 +1,71 @@
+package client
+
+import "net/http"
+
+// Headers understood by API
+const (
+	adminTokenHeader     = "X-Admin-Token"
+	voterTokenHeader     = "X-Voter-Token"
+	pollPasswordHeader   = "X-Poll-Password"
+	accessTokenHeader    = "X-Access-Token"
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// Auth authenticates every request of client, e.g. to API gateway in front of the service
+type Auth interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthFunc is function used as Auth
+type AuthFunc func(req *http.Request) error
+
+func (f AuthFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken returns Auth sending token in Authorization header
+func BearerToken(token string) Auth {
+	return Header("Authorization", "Bearer "+token)
+}
+
+// Header returns Auth sending value in header
+func Header(name, value string) Auth {
+	return AuthFunc(func(req *http.Request) error {
+		req.Header.Set(name, value)
+		return nil
+	})
+}
+
+// RequestOption sets credentials of single request
+type RequestOption func(req *http.Request)
+
+// WithAdminToken sends admin token of poll or survey
+func WithAdminToken(token string) RequestOption {
+	return withHeader(adminTokenHeader, token)
+}
+
+// WithVoterToken sends voter token returned on voting, or invite token in invite-only polls
+func WithVoterToken(token string) RequestOption {
+	return withHeader(voterTokenHeader, token)
+}
+
+// WithPassword sends password of password-protected poll
+func WithPassword(password string) RequestOption {
+	return withHeader(pollPasswordHeader, password)
+}
+
+// WithAccessToken sends access token of password-protected poll
+func WithAccessToken(token string) RequestOption {
+	return withHeader(accessTokenHeader, token)
+}
+
+// WithIdempotencyKey sends chosen Idempotency-Key, by default create and vote requests get random one
+func WithIdempotencyKey(key string) RequestOption {
+	return withHeader(idempotencyKeyHeader, key)
+}
+
+func withHeader(name, value string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,224 @@
+// Package client is Go client of poll service API.
+//
+// Create and vote requests carry random Idempotency-Key unless one is given, so they are retried
+// together with read requests on network and server errors without creating duplicate polls or votes.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/AlexeyLars/surway-service/internal/lib/random"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiPrefix is path of API version used by client
+const apiPrefix = "/api/v1"
+
+// RetryPolicy sets how idempotent requests are retried, delay doubles after every attempt
+type RetryPolicy struct {
+	MaxAttempts int // attempts including the first one, 1 disables retries
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy is retry policy of new client
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+// delay returns jittered wait before retry after attempt, attempts are counted from zero
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.BaseDelay << attempt
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	// Half of delay is random, so clients retrying together spread out
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// Client calls poll service API, it is safe for concurrent use
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	auth       Auth
+	retry      RetryPolicy
+}
+
+// Option configures Client
+type Option func(*Client)
+
+// WithHTTPClient sets HTTP client sending requests, http.DefaultClient by default
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAuth sets authentication of every request
+func WithAuth(auth Auth) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// WithRetryPolicy sets retries of idempotent requests
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// New creates client of service at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// call is API request
+type call struct {
+	method     string
+	path       string // relative to API prefix
+	query      url.Values
+	body       any
+	idempotent bool // safe to retry, POST requests get Idempotency-Key
+	options    []RequestOption
+}
+
+// do sends API request and decodes response into out, error responses are returned as *APIError
+func (c *Client) do(ctx context.Context, call call, out any) error {
+	var body []byte
+	if call.body != nil {
+		data, err := json.Marshal(call.body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = data
+	}
+
+	endpoint := c.baseURL + apiPrefix + call.path
+	if len(call.query) > 0 {
+		endpoint += "?" + call.query.Encode()
+	}
+
+	// Headers are built once, so retries share Idempotency-Key
+	header := requestHeader(call.options)
+	if body != nil {
+		header.Set("Content-Type", "application/json")
+	}
+	if call.idempotent && call.method == http.MethodPost && header.Get(idempotencyKeyHeader) == "" {
+		header.Set(idempotencyKeyHeader, random.NewToken())
+	}
+
+	attempts := 1
+	if call.idempotent && c.retry.MaxAttempts > 1 {
+		attempts = c.retry.MaxAttempts
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(c.retry.delay(attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		var retry bool
+		retry, err = c.send(ctx, call.method, endpoint, header, body, out)
+		if !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// send makes one attempt of request, it reports whether failed attempt can be retried
+func (c *Client) send(ctx context.Context, method, endpoint string, header http.Header, body []byte, out any) (bool, error) {
+	req, err := c.newRequest(ctx, method, endpoint, header, body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return true, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := decodeError(resp)
+		retry := resp.StatusCode >= http.StatusInternalServerError || apiErr.Code == "idempotency_in_progress"
+		return retry, apiErr
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return false, nil
+}
+
+// newRequest returns authenticated request with copy of header
+func (c *Client) newRequest(ctx context.Context, method, endpoint string, header http.Header, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = header.Clone()
+	if c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("failed to authenticate request: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// requestHeader returns header set by request options
+func requestHeader(options []RequestOption) http.Header {
+	header := make(http.Header)
+	probe := &http.Request{Header: header}
+	for _, option := range options {
+		option(probe)
+	}
+	return header
+}
+
+// decodeError reads error response, bodies not following ErrorResponse keep only status
+func decodeError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return apiErr
+	}
+	var response ErrorResponse
+	if json.Unmarshal(data, &response) == nil {
+		apiErr.Code = response.Error
+		apiErr.Message = response.Message
+	}
+	return apiErr
+}
@@ -0,0 +1,466 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AlexeyLars/surway-service/internal/config"
+	"github.com/AlexeyLars/surway-service/internal/handler"
+	"github.com/AlexeyLars/surway-service/internal/model"
+	"github.com/AlexeyLars/surway-service/internal/service"
+	"github.com/AlexeyLars/surway-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStorage keeps choice polls in memory, methods not used by tests panic
+type memoryStorage struct {
+	storage.Storage
+
+	mu       sync.Mutex
+	polls    map[string]*model.Poll
+	votes    map[string]map[string]int
+	receipts map[string]*model.VoteReceipt
+	voters   map[string]bool
+	watchers map[string][]chan struct{}
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		polls:    make(map[string]*model.Poll),
+		votes:    make(map[string]map[string]int),
+		receipts: make(map[string]*model.VoteReceipt),
+		voters:   make(map[string]bool),
+		watchers: make(map[string][]chan struct{}),
+	}
+}
+
+func (m *memoryStorage) CreatePoll(_ context.Context, poll *model.Poll, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *poll
+	m.polls[poll.ID] = &stored
+	m.votes[poll.ID] = make(map[string]int)
+	return nil
+}
+
+func (m *memoryStorage) GetPoll(_ context.Context, pollID string) (*model.Poll, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	poll, ok := m.polls[pollID]
+	if !ok {
+		return nil, storage.ErrPollNotFound
+	}
+	stored := *poll
+	return &stored, nil
+}
+
+func (m *memoryStorage) ExtendPoll(_ context.Context, pollID string, expiresAt time.Time) (*model.Poll, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	poll := m.polls[pollID]
+	poll.ExpiresAt = expiresAt
+	stored := *poll
+	return &stored, nil
+}
+
+func (m *memoryStorage) Vote(_ context.Context, pollID string, req *model.VoteRequest, voter string, receipt *model.VoteReceipt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	poll, ok := m.polls[pollID]
+	if !ok {
+		return storage.ErrPollNotFound
+	}
+	if poll.Closed(time.Now()) {
+		return storage.ErrClosed
+	}
+	for _, idx := range req.OptionIndices {
+		if idx < 0 || idx >= len(poll.Options) {
+			return storage.ErrInvalidOption
+		}
+	}
+	if m.voters[pollID+":"+voter] {
+		return storage.ErrAlreadyVoted
+	}
+	m.voters[pollID+":"+voter] = true
+	for _, idx := range req.OptionIndices {
+		m.votes[pollID][strconv.Itoa(idx)]++
+	}
+	m.receipts[receipt.ID] = receipt
+	m.notify(pollID)
+	return nil
+}
+
+func (m *memoryStorage) ClosePoll(_ context.Context, pollID string, closesAt time.Time) (*model.Poll, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	poll, ok := m.polls[pollID]
+	if !ok {
+		return nil, storage.ErrPollNotFound
+	}
+	poll.ClosesAt = &closesAt
+	stored := *poll
+	return &stored, nil
+}
+
+func (m *memoryStorage) DeletePoll(_ context.Context, pollID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.polls[pollID]; !ok {
+		return storage.ErrPollNotFound
+	}
+	delete(m.polls, pollID)
+	delete(m.votes, pollID)
+	m.notify(pollID)
+	return nil
+}
+
+func (m *memoryStorage) WatchPoll(ctx context.Context, pollID string) (<-chan struct{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	updates := make(chan struct{}, 1)
+	m.watchers[pollID] = append(m.watchers[pollID], updates)
+	return updates, nil
+}
+
+// notify wakes watchers of poll, caller holds mu
+func (m *memoryStorage) notify(pollID string) {
+	for _, updates := range m.watchers[pollID] {
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (m *memoryStorage) GetReceipt(_ context.Context, _, receiptID string) (*model.VoteReceipt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	receipt, ok := m.receipts[receiptID]
+	if !ok {
+		return nil, storage.ErrReceiptNotFound
+	}
+	return receipt, nil
+}
+
+func (m *memoryStorage) GetResults(_ context.Context, pollID string) (*model.PollResults, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	poll, ok := m.polls[pollID]
+	if !ok {
+		return nil, storage.ErrPollNotFound
+	}
+	results := &model.PollResults{Poll: *poll, Votes: make(map[string]int)}
+	for i, option := range poll.Options {
+		count := m.votes[pollID][strconv.Itoa(i)]
+		results.Votes[option] = count
+		results.Total += count
+	}
+	return results, nil
+}
+
+func (m *memoryStorage) AuditLedger(_ context.Context, pollID string) (*model.LedgerAudit, error) {
+	return &model.LedgerAudit{PollID: pollID, ChainValid: true, Consistent: true}, nil
+}
+
+// memoryIdempotency keeps idempotent responses in memory
+type memoryIdempotency struct {
+	mu        sync.Mutex
+	responses map[string]model.IdempotentResponse
+}
+
+func (m *memoryIdempotency) BeginIdempotent(_ context.Context, key, bodyHash string) (*model.IdempotentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if response, ok := m.responses[key]; ok {
+		return &response, nil
+	}
+	m.responses[key] = model.IdempotentResponse{BodyHash: bodyHash}
+	return nil, nil
+}
+
+func (m *memoryIdempotency) SaveIdempotent(_ context.Context, key string, response *model.IdempotentResponse, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[key] = *response
+	return nil
+}
+
+func (m *memoryIdempotency) ReleaseIdempotent(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.responses, key)
+	return nil
+}
+
+// newTestServer returns server running API router, wrap may intercept requests before router
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) (*httptest.Server, *memoryStorage) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{
+		Server: config.ServerConfig{BaseURL: "http://localhost:8080"},
+		Poll:   config.PollConfig{DefaultTTL: 24 * time.Hour, MaxTTL: 720 * time.Hour},
+		Auth:   config.AuthConfig{AccessTokenSecret: "test-secret", AccessTokenTTL: time.Hour},
+	}
+
+	store := newMemoryStorage()
+	idempotency := handler.IdempotencyMiddleware(&memoryIdempotency{responses: make(map[string]model.IdempotentResponse)}, time.Hour, logger)
+	pollHandler := handler.NewPollHandler(service.NewPollService(store, cfg, logger), logger)
+	chatAuth := handler.ChatAuthMiddleware(cfg.Chat, logger)
+	engine, err := handler.SetupRouter(pollHandler, idempotency, chatAuth, nil, logger, false)
+	require.NoError(t, err)
+	var router http.Handler = engine
+	if wrap != nil {
+		router = wrap(router)
+	}
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, store
+}
+
+// fastRetries keeps retrying tests quick
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+func TestClient_PollLifecycle(t *testing.T) {
+	server, _ := newTestServer(t, nil)
+	c := New(server.URL)
+	ctx := context.Background()
+
+	created, err := c.CreatePoll(ctx, &CreatePollRequest{Title: "Lunch?", Options: []string{"Pizza", "Sushi"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.AdminToken)
+
+	details, err := c.GetPoll(ctx, created.PollID)
+	require.NoError(t, err)
+	assert.Equal(t, "Lunch?", details.Title)
+
+	vote, err := c.Vote(ctx, created.PollID, &VoteRequest{OptionIndices: []int{1}})
+	require.NoError(t, err)
+	assert.True(t, vote.Success)
+	assert.NotEmpty(t, vote.VoterToken)
+	require.NotNil(t, vote.Receipt)
+
+	results, err := c.GetResults(ctx, created.PollID, WithVoterToken(vote.VoterToken))
+	require.NoError(t, err)
+	assert.Equal(t, 1, results.Votes["Sushi"])
+	assert.Equal(t, 1, results.Total)
+
+	receipt, err := c.GetReceipt(ctx, created.PollID, vote.Receipt.ID)
+	require.NoError(t, err)
+	assert.True(t, receipt.Counted)
+	assert.True(t, receipt.Valid)
+
+	audit, err := c.AuditLedger(ctx, created.PollID, WithAdminToken(created.AdminToken))
+	require.NoError(t, err)
+	assert.True(t, audit.Consistent)
+
+	extended, err := c.ExtendPoll(ctx, created.PollID, &ExtendPollRequest{ExpiresAt: details.ExpiresAt.Add(time.Hour)},
+		WithAdminToken(created.AdminToken))
+	require.NoError(t, err)
+	assert.True(t, extended.ExpiresAt.After(details.ExpiresAt))
+}
+
+func TestClient_CloseAndDelete(t *testing.T) {
+	server, _ := newTestServer(t, nil)
+	c := New(server.URL)
+	ctx := context.Background()
+
+	created, err := c.CreatePoll(ctx, &CreatePollRequest{Title: "Lunch?", Options: []string{"Pizza", "Sushi"}})
+	require.NoError(t, err)
+
+	err = c.DeletePoll(ctx, created.PollID, WithAdminToken("guess"))
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	closed, err := c.ClosePoll(ctx, created.PollID, WithAdminToken(created.AdminToken))
+	require.NoError(t, err)
+	require.NotNil(t, closed.ClosesAt)
+
+	_, err = c.Vote(ctx, created.PollID, &VoteRequest{OptionIndices: []int{0}})
+	assert.ErrorIs(t, err, ErrClosed)
+
+	require.NoError(t, c.DeletePoll(ctx, created.PollID, WithAdminToken(created.AdminToken)))
+
+	_, err = c.GetPoll(ctx, created.PollID)
+	assert.ErrorIs(t, err, ErrPollNotFound)
+}
+
+func TestClient_WatchResults(t *testing.T) {
+	server, _ := newTestServer(t, nil)
+	c := New(server.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := c.CreatePoll(ctx, &CreatePollRequest{Title: "Lunch?", Options: []string{"Pizza", "Sushi"}})
+	require.NoError(t, err)
+
+	var totals []int
+	err = c.WatchResults(ctx, created.PollID, func(results *PollResults) error {
+		totals = append(totals, results.Total)
+		switch results.Total {
+		case 0:
+			_, err := c.Vote(ctx, created.PollID, &VoteRequest{OptionIndices: []int{1}})
+			return err
+		default:
+			// Stream ends once poll is gone
+			return c.DeletePoll(ctx, created.PollID, WithAdminToken(created.AdminToken))
+		}
+	}, WithAdminToken(created.AdminToken))
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, totals)
+
+	err = c.WatchResults(ctx, "missing", func(*PollResults) error { return nil })
+	assert.ErrorIs(t, err, ErrPollNotFound)
+}
+
+func TestClient_Errors(t *testing.T) {
+	server, _ := newTestServer(t, nil)
+	c := New(server.URL)
+	ctx := context.Background()
+
+	created, err := c.CreatePoll(ctx, &CreatePollRequest{Title: "Lunch?", Options: []string{"Pizza", "Sushi"}})
+	require.NoError(t, err)
+
+	_, err = c.GetPoll(ctx, "missing")
+	assert.ErrorIs(t, err, ErrPollNotFound)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "poll_not_found", apiErr.Code)
+
+	_, err = c.Vote(ctx, created.PollID, &VoteRequest{OptionIndices: []int{7}})
+	assert.ErrorIs(t, err, ErrInvalidBallot)
+
+	_, err = c.Vote(ctx, created.PollID, &VoteRequest{OptionIndices: []int{0}}, WithVoterToken("voter-token-0123456789"))
+	require.NoError(t, err)
+	_, err = c.Vote(ctx, created.PollID, &VoteRequest{OptionIndices: []int{1}}, WithVoterToken("voter-token-0123456789"))
+	assert.ErrorIs(t, err, ErrAlreadyVoted)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+
+	_, err = c.CreatePoll(ctx, &CreatePollRequest{Title: "x"})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	_, err = c.AuditLedger(ctx, created.PollID)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = c.AuditLedger(ctx, created.PollID, WithAdminToken("guess"))
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestClient_RetryReplaysVote(t *testing.T) {
+	// First response of every request is lost after the request is handled
+	var attempts atomic.Int32
+	server, store := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && r.URL.Path != "/api/v1/polls" && attempts.Add(1) == 1 {
+				next.ServeHTTP(httptest.NewRecorder(), r)
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	c := New(server.URL, fastRetries)
+	ctx := context.Background()
+
+	created, err := c.CreatePoll(ctx, &CreatePollRequest{Title: "Lunch?", Options: []string{"Pizza", "Sushi"}})
+	require.NoError(t, err)
+
+	vote, err := c.Vote(ctx, created.PollID, &VoteRequest{OptionIndices: []int{0}})
+
+	require.NoError(t, err)
+	assert.True(t, vote.Success)
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.Equal(t, 1, store.votes[created.PollID]["0"], "retried vote is counted once")
+}
+
+func TestClient_Retry(t *testing.T) {
+	t.Run("idempotent call gives up after max attempts", func(t *testing.T) {
+		var attempts atomic.Int32
+		server, _ := newTestServer(t, func(http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+		})
+
+		_, err := New(server.URL, fastRetries).GetPoll(context.Background(), "abc")
+
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("call without idempotency is not retried", func(t *testing.T) {
+		var attempts atomic.Int32
+		server, _ := newTestServer(t, func(http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+		})
+
+		_, err := New(server.URL, fastRetries).CreateInvites(context.Background(), "abc", &CreateInvitesRequest{Count: 1})
+
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("client error is not retried", func(t *testing.T) {
+		var attempts atomic.Int32
+		server, _ := newTestServer(t, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				next.ServeHTTP(w, r)
+			})
+		})
+
+		_, err := New(server.URL, fastRetries).GetPoll(context.Background(), "missing")
+
+		assert.ErrorIs(t, err, ErrPollNotFound)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+
+	t.Run("canceled context stops retries", func(t *testing.T) {
+		server, _ := newTestServer(t, func(http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		c := New(server.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: time.Second}))
+
+		_, err := c.GetPoll(ctx, "abc")
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func TestClient_Auth(t *testing.T) {
+	var authorization, admin string
+	server, _ := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			admin = r.Header.Get(adminTokenHeader)
+			next.ServeHTTP(w, r)
+		})
+	})
+	c := New(server.URL, WithAuth(BearerToken("gateway-token")))
+
+	_, _ = c.GetInviteUsage(context.Background(), "abc", WithAdminToken("secret"))
+
+	assert.Equal(t, "Bearer gateway-token", authorization)
+	assert.Equal(t, "secret", admin)
+}
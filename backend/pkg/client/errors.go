@@ -0,0 +1,88 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors matching error codes of API, APIError unwraps to them
+var (
+	ErrInvalidRequest        = errors.New("invalid request")
+	ErrInvalidBallot         = errors.New("invalid ballot")
+	ErrPollNotFound          = errors.New("poll not found")
+	ErrSurveyNotFound        = errors.New("survey not found")
+	ErrReceiptNotFound       = errors.New("receipt not found")
+	ErrWebhookNotFound       = errors.New("webhook not found")
+	ErrTooManyWebhooks       = errors.New("too many webhooks")
+	ErrUnauthorized          = errors.New("admin token required")
+	ErrForbidden             = errors.New("invalid admin token")
+	ErrPasswordRequired      = errors.New("poll password required")
+	ErrWrongPassword         = errors.New("wrong poll password")
+	ErrTooManyAttempts       = errors.New("too many wrong passwords")
+	ErrResultsHidden         = errors.New("results hidden")
+	ErrNotOpen               = errors.New("voting is not open yet")
+	ErrClosed                = errors.New("voting is closed")
+	ErrInvalidInvite         = errors.New("invalid invite token")
+	ErrInviteUsed            = errors.New("invite token already used")
+	ErrAlreadyVoted          = errors.New("voter has already voted")
+	ErrIdempotencyInProgress = errors.New("request with idempotency key in progress")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key reused with different body")
+	ErrInternal              = errors.New("internal server error")
+)
+
+// errorCodes maps error codes of API to errors
+var errorCodes = map[string]error{
+	"invalid_request":         ErrInvalidRequest,
+	"invalid_option":          ErrInvalidBallot,
+	"duplicate_option":        ErrInvalidBallot,
+	"empty_vote":              ErrInvalidBallot,
+	"vote_type_mismatch":      ErrInvalidBallot,
+	"invalid_score":           ErrInvalidBallot,
+	"invalid_points":          ErrInvalidBallot,
+	"budget_exceeded":         ErrInvalidBallot,
+	"invalid_column":          ErrInvalidBallot,
+	"invalid_number":          ErrInvalidBallot,
+	"text_too_long":           ErrInvalidBallot,
+	"invalid_question":        ErrInvalidBallot,
+	"duplicate_answer":        ErrInvalidBallot,
+	"missing_answer":          ErrInvalidBallot,
+	"unreachable_question":    ErrInvalidBallot,
+	"poll_not_found":          ErrPollNotFound,
+	"survey_not_found":        ErrSurveyNotFound,
+	"receipt_not_found":       ErrReceiptNotFound,
+	"webhook_not_found":       ErrWebhookNotFound,
+	"too_many_webhooks":       ErrTooManyWebhooks,
+	"unauthorized":            ErrUnauthorized,
+	"forbidden":               ErrForbidden,
+	"password_required":       ErrPasswordRequired,
+	"wrong_password":          ErrWrongPassword,
+	"too_many_attempts":       ErrTooManyAttempts,
+	"results_hidden":          ErrResultsHidden,
+	"not_open":                ErrNotOpen,
+	"closed":                  ErrClosed,
+	"invalid_invite":          ErrInvalidInvite,
+	"invite_used":             ErrInviteUsed,
+	"already_voted":           ErrAlreadyVoted,
+	"idempotency_in_progress": ErrIdempotencyInProgress,
+	"idempotency_key_reused":  ErrIdempotencyKeyReused,
+	"internal_error":          ErrInternal,
+}
+
+// APIError is error response of API, errors.Is matches it against errors of its code
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("surway api: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("surway api: %s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns error of API error code, it is nil for unknown codes
+func (e *APIError) Unwrap() error {
+	return errorCodes[e.Code]
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreatePoll creates poll, its admin token is returned once
+func (c *Client) CreatePoll(ctx context.Context, req *CreatePollRequest, opts ...RequestOption) (*CreatePollResponse, error) {
+	var response CreatePollResponse
+	err := c.do(ctx, call{method: http.MethodPost, path: "/polls", body: req, idempotent: true, options: opts}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetPoll returns poll with its voting status
+func (c *Client) GetPoll(ctx context.Context, pollID string, opts ...RequestOption) (*PollDetails, error) {
+	var details PollDetails
+	err := c.do(ctx, call{method: http.MethodGet, path: pollPath(pollID), idempotent: true, options: opts}, &details)
+	if err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// ExtendPoll moves poll expiry, requires WithAdminToken
+func (c *Client) ExtendPoll(ctx context.Context, pollID string, req *ExtendPollRequest, opts ...RequestOption) (*PollDetails, error) {
+	var details PollDetails
+	err := c.do(ctx, call{method: http.MethodPost, path: pollPath(pollID) + "/extend", body: req, options: opts}, &details)
+	if err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// ClosePoll stops voting in poll now, requires WithAdminToken
+func (c *Client) ClosePoll(ctx context.Context, pollID string, opts ...RequestOption) (*PollDetails, error) {
+	var details PollDetails
+	err := c.do(ctx, call{method: http.MethodPost, path: pollPath(pollID) + "/close", idempotent: true, options: opts}, &details)
+	if err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// DeletePoll removes poll with its votes, requires WithAdminToken
+func (c *Client) DeletePoll(ctx context.Context, pollID string, opts ...RequestOption) error {
+	return c.do(ctx, call{method: http.MethodDelete, path: pollPath(pollID), options: opts}, nil)
+}
+
+// IssueAccessToken exchanges password of password-protected poll for access token
+func (c *Client) IssueAccessToken(ctx context.Context, pollID, password string, opts ...RequestOption) (*PollAccessResponse, error) {
+	var response PollAccessResponse
+	body := &PollAccessRequest{Password: password}
+	err := c.do(ctx, call{method: http.MethodPost, path: pollPath(pollID) + "/access", body: body, options: opts}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// CreateInvites generates single-use voter tokens of invite-only poll, requires WithAdminToken
+func (c *Client) CreateInvites(ctx context.Context, pollID string, req *CreateInvitesRequest, opts ...RequestOption) (*CreateInvitesResponse, error) {
+	var response CreateInvitesResponse
+	err := c.do(ctx, call{method: http.MethodPost, path: pollPath(pollID) + "/invites", body: req, options: opts}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetInviteUsage returns invite counters of invite-only poll, requires WithAdminToken
+func (c *Client) GetInviteUsage(ctx context.Context, pollID string, opts ...RequestOption) (*InviteUsage, error) {
+	var usage InviteUsage
+	err := c.do(ctx, call{method: http.MethodGet, path: pollPath(pollID) + "/invites", idempotent: true, options: opts}, &usage)
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// Vote registers ballot, response carries voter token and signed receipt
+func (c *Client) Vote(ctx context.Context, pollID string, req *VoteRequest, opts ...RequestOption) (*VoteResponse, error) {
+	var response VoteResponse
+	err := c.do(ctx, call{method: http.MethodPost, path: pollPath(pollID) + "/vote", body: req, idempotent: true, options: opts}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetResults returns poll results, results may be hidden by poll results visibility
+func (c *Client) GetResults(ctx context.Context, pollID string, opts ...RequestOption) (*PollResults, error) {
+	var results PollResults
+	err := c.do(ctx, call{method: http.MethodGet, path: pollPath(pollID) + "/results", idempotent: true, options: opts}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// GetTextAnswers returns page of text answers, requires WithAdminToken, zero limit means server default
+func (c *Client) GetTextAnswers(ctx context.Context, pollID string, offset, limit int, opts ...RequestOption) (*TextAnswersPage, error) {
+	var page TextAnswersPage
+	err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       pollPath(pollID) + "/texts",
+		query:      pageQuery(offset, limit),
+		idempotent: true,
+		options:    opts,
+	}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetReceipt checks that ballot of vote receipt is in the tally
+func (c *Client) GetReceipt(ctx context.Context, pollID, receiptID string, opts ...RequestOption) (*ReceiptStatus, error) {
+	var status ReceiptStatus
+	path := pollPath(pollID) + "/receipts/" + url.PathEscape(receiptID)
+	err := c.do(ctx, call{method: http.MethodGet, path: path, idempotent: true, options: opts}, &status)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetReceiptKey returns public key verifying vote receipts
+func (c *Client) GetReceiptKey(ctx context.Context, opts ...RequestOption) (*ReceiptKey, error) {
+	var key ReceiptKey
+	err := c.do(ctx, call{method: http.MethodGet, path: "/receipts/key", idempotent: true, options: opts}, &key)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// AuditLedger compares vote ledger of poll with its vote counters, requires WithAdminToken
+func (c *Client) AuditLedger(ctx context.Context, pollID string, opts ...RequestOption) (*LedgerAudit, error) {
+	var audit LedgerAudit
+	err := c.do(ctx, call{method: http.MethodGet, path: pollPath(pollID) + "/audit", idempotent: true, options: opts}, &audit)
+	if err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
+
+func pollPath(pollID string) string {
+	return "/polls/" + url.PathEscape(pollID)
+}
+
+// pageQuery returns query of text answers page, zero values are left to server defaults
+func pageQuery(offset, limit int) url.Values {
+	query := url.Values{}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return query
+}
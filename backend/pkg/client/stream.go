@@ -0,0 +1,72 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// resultsEvent is event name of live results stream
+const resultsEvent = "results"
+
+// WatchResults calls fn with current poll results and again after every batch of votes until ctx is done,
+// fn returns error or stream ends. Stream ends without error when poll expires or is deleted.
+func (c *Client) WatchResults(ctx context.Context, pollID string, fn func(*PollResults) error, opts ...RequestOption) error {
+	req, err := c.newRequest(ctx, http.MethodGet, c.baseURL+apiPrefix+pollPath(pollID)+"/results/stream", requestHeader(opts), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+
+	var event, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// Blank line ends event
+			if event == resultsEvent && data != "" {
+				var results PollResults
+				if err := json.Unmarshal([]byte(data), &results); err != nil {
+					return fmt.Errorf("failed to decode results: %w", err)
+				}
+				if err := fn(&results); err != nil {
+					return err
+				}
+			}
+			event, data = "", ""
+		case strings.HasPrefix(line, ":"):
+			// Heartbeat comment
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read results stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateSurvey creates survey, its admin token is returned once
+func (c *Client) CreateSurvey(ctx context.Context, req *CreateSurveyRequest, opts ...RequestOption) (*CreateSurveyResponse, error) {
+	var response CreateSurveyResponse
+	err := c.do(ctx, call{method: http.MethodPost, path: "/surveys", body: req, options: opts}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetSurvey returns survey definition, polls are returned as one-question surveys
+func (c *Client) GetSurvey(ctx context.Context, surveyID string, opts ...RequestOption) (*SurveyDefinition, error) {
+	var survey SurveyDefinition
+	err := c.do(ctx, call{method: http.MethodGet, path: surveyPath(surveyID), idempotent: true, options: opts}, &survey)
+	if err != nil {
+		return nil, err
+	}
+	return &survey, nil
+}
+
+// SubmitSurvey submits answers to survey, it is not retried
+func (c *Client) SubmitSurvey(ctx context.Context, surveyID string, req *SubmitSurveyRequest, opts ...RequestOption) (*SubmitSurveyResponse, error) {
+	var response SubmitSurveyResponse
+	err := c.do(ctx, call{method: http.MethodPost, path: surveyPath(surveyID) + "/responses", body: req, options: opts}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetSurveyResults returns results of every survey question
+func (c *Client) GetSurveyResults(ctx context.Context, surveyID string, opts ...RequestOption) (*SurveyResults, error) {
+	var results SurveyResults
+	err := c.do(ctx, call{method: http.MethodGet, path: surveyPath(surveyID) + "/results", idempotent: true, options: opts}, &results)
+	if err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// GetSurveyTextAnswers returns page of text answers to survey question, requires WithAdminToken
+func (c *Client) GetSurveyTextAnswers(ctx context.Context, surveyID string, question, offset, limit int, opts ...RequestOption) (*TextAnswersPage, error) {
+	var page TextAnswersPage
+	err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       surveyPath(surveyID) + "/questions/" + strconv.Itoa(question) + "/texts",
+		query:      pageQuery(offset, limit),
+		idempotent: true,
+		options:    opts,
+	}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func surveyPath(surveyID string) string {
+	return "/surveys/" + url.PathEscape(surveyID)
+}
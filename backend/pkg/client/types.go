@@ -0,0 +1,82 @@
+package client
+
+import "github.com/AlexeyLars/surway-service/internal/model"
+
+// API types are shared with the server, aliases make them usable outside of the module
+
+type (
+	Poll               = model.Poll
+	PollType           = model.PollType
+	PollDetails        = model.PollDetails
+	PollResults        = model.PollResults
+	CreatePollRequest  = model.CreatePollRequest
+	CreatePollResponse = model.CreatePollResponse
+	ExtendPollRequest  = model.ExtendPollRequest
+	VoteRequest        = model.VoteRequest
+	VoteResponse       = model.VoteResponse
+	VoteReceipt        = model.VoteReceipt
+	ReceiptStatus      = model.ReceiptStatus
+	ReceiptKey         = model.ReceiptKey
+	ScoreScale         = model.ScoreScale
+	PointBudget        = model.PointBudget
+	NumericRange       = model.NumericRange
+	Schedule           = model.Schedule
+	ResultsVisibility  = model.ResultsVisibility
+
+	PollAccessRequest     = model.PollAccessRequest
+	PollAccessResponse    = model.PollAccessResponse
+	CreateInvitesRequest  = model.CreateInvitesRequest
+	CreateInvitesResponse = model.CreateInvitesResponse
+	InviteUsage           = model.InviteUsage
+	LedgerAudit           = model.LedgerAudit
+	TextAnswersPage       = model.TextAnswersPage
+
+	Webhook              = model.Webhook
+	WebhookEventType     = model.WebhookEventType
+	WebhookEvent         = model.WebhookEvent
+	CreateWebhookRequest = model.CreateWebhookRequest
+	WebhookDeliveryLog   = model.WebhookDeliveryLog
+	VoteCastData         = model.VoteCastData
+	PollExpiredData      = model.PollExpiredData
+
+	Survey               = model.Survey
+	SurveyDefinition     = model.SurveyDefinition
+	Question             = model.Question
+	Answer               = model.Answer
+	CreateSurveyRequest  = model.CreateSurveyRequest
+	CreateSurveyResponse = model.CreateSurveyResponse
+	SubmitSurveyRequest  = model.SubmitSurveyRequest
+	SubmitSurveyResponse = model.SubmitSurveyResponse
+	SurveyResults        = model.SurveyResults
+
+	ErrorResponse = model.ErrorResponse
+)
+
+// Poll types
+const (
+	PollTypeChoice    = model.PollTypeChoice
+	PollTypeRanked    = model.PollTypeRanked
+	PollTypeScore     = model.PollTypeScore
+	PollTypeBudget    = model.PollTypeBudget
+	PollTypeText      = model.PollTypeText
+	PollTypeNumeric   = model.PollTypeNumeric
+	PollTypeNPS       = model.PollTypeNPS
+	PollTypeMatrix    = model.PollTypeMatrix
+	PollTypeWordCloud = model.PollTypeWordCloud
+)
+
+// Results visibility policies
+const (
+	ResultsAlways     = model.ResultsAlways
+	ResultsAfterVote  = model.ResultsAfterVote
+	ResultsAfterClose = model.ResultsAfterClose
+	ResultsCreator    = model.ResultsCreator
+)
+
+// Webhook events
+const (
+	WebhookPollCreated = model.WebhookPollCreated
+	WebhookVoteCast    = model.WebhookVoteCast
+	WebhookPollClosed  = model.WebhookPollClosed
+	WebhookPollExpired = model.WebhookPollExpired
+)